@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Fields of the add listing form, in tab order
+const (
+	addFieldTitle = iota
+	addFieldPrice
+	addFieldSource
+	addFieldURL
+	addFieldCondition
+	addFieldCount
+)
+
+// addFieldLabels label the add listing form fields
+var addFieldLabels = [addFieldCount]string{"Title:", "Price:", "Source:", "URL:", "Condition:"}
+
+// addListingForm is the overlay for entering a listing found by hand
+type addListingForm struct {
+	inputs     [addFieldCount]textinput.Model
+	focusIndex int
+	submitting bool   // set while the listing is being posted
+	lastError  string // why the last submission failed, shown in the form
+}
+
+func newAddListingForm() *addListingForm {
+	placeholders := [addFieldCount]string{"RTX 3060 at the flea market", "120.00", manualProvider, "https://... (optional)", "used (optional)"}
+
+	form := &addListingForm{}
+	for i, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		form.inputs[i] = input
+	}
+	form.inputs[addFieldTitle].Focus()
+
+	return form
+}
+
+// listing builds the listing to submit from the form fields
+func (f *addListingForm) listing() (APIListing, error) {
+	title := strings.TrimSpace(f.inputs[addFieldTitle].Value())
+	if title == "" {
+		return APIListing{}, fmt.Errorf("title is required")
+	}
+
+	rawPrice := strings.TrimPrefix(strings.TrimSpace(f.inputs[addFieldPrice].Value()), "$")
+	price, err := strconv.ParseFloat(rawPrice, 64)
+	if err != nil {
+		return APIListing{}, fmt.Errorf("price must be a number, got %q", f.inputs[addFieldPrice].Value())
+	}
+	if price < 0 {
+		return APIListing{}, fmt.Errorf("price must not be negative, got %g", price)
+	}
+
+	rawURL := strings.TrimSpace(f.inputs[addFieldURL].Value())
+	if rawURL != "" && !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+		return APIListing{}, fmt.Errorf("URL must start with http:// or https://, got %q", rawURL)
+	}
+
+	return APIListing{
+		Source:    valueOr(strings.TrimSpace(f.inputs[addFieldSource].Value()), manualProvider),
+		URL:       rawURL,
+		Title:     title,
+		Price:     price,
+		Currency:  defaultCurrency,
+		Condition: strings.TrimSpace(f.inputs[addFieldCondition].Value()),
+	}, nil
+}
+
+// updateAddForm handles input while the add listing form is open. Enter
+// submits the listing, Esc discards the form.
+func (p *ResultsPane) updateAddForm(msg tea.Msg) (ResultsPane, tea.Cmd) {
+	form := p.addForm
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.AddListing.Close):
+			p.addForm = nil
+			return *p, nil
+
+		case key.Matches(msg, keys.AddListing.Submit):
+			if form.submitting {
+				return *p, nil
+			}
+			listing, err := form.listing()
+			if err != nil {
+				form.lastError = err.Error()
+				return *p, nil
+			}
+			form.lastError = ""
+			form.submitting = true
+			return *p, p.createListing(listing)
+
+		case key.Matches(msg, keys.AddListing.PrevField):
+			form.focus((form.focusIndex + addFieldCount - 1) % addFieldCount)
+			return *p, nil
+
+		case key.Matches(msg, keys.AddListing.NextField):
+			form.focus((form.focusIndex + 1) % addFieldCount)
+			return *p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	form.inputs[form.focusIndex], cmd = form.inputs[form.focusIndex].Update(msg)
+	return *p, cmd
+}
+
+// createListing posts a listing off the UI goroutine
+func (p *ResultsPane) createListing(l APIListing) tea.Cmd {
+	client := p.apiClient
+	return func() tea.Msg {
+		created, err := client.CreateListing(l)
+		return ListingCreatedMsg{Listing: created, Error: err}
+	}
+}
+
+// ApplyCreated closes the form once the listing is stored and refreshes the
+// results to include it. A rejected listing keeps the form open with the
+// API's reason, so it can be corrected.
+func (p *ResultsPane) ApplyCreated(msg ListingCreatedMsg) tea.Cmd {
+	if p.addForm == nil {
+		return nil
+	}
+
+	p.addForm.submitting = false
+	if msg.Error != nil {
+		p.addForm.lastError = describeError(msg.Error)
+		return nil
+	}
+
+	p.addForm = nil
+	p.loading = true
+	p.lastError = ""
+	return tea.Batch(p.refresh(), p.spinner.Tick)
+}
+
+func (f *addListingForm) focus(index int) {
+	f.inputs[f.focusIndex].Blur()
+	f.focusIndex = index
+	f.inputs[f.focusIndex].Focus()
+}
+
+// View renders the form centred in the pane
+func (f *addListingForm) View(width, height int) string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true).
+		Width(12)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(theme.Error).
+		Bold(true)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		Padding(1, 2)
+
+	b.WriteString(titleStyle.Render("➕ Add a Listing"))
+	b.WriteString("\n\n")
+
+	for i, input := range f.inputs {
+		b.WriteString(labelStyle.Render(addFieldLabels[i]))
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if f.submitting {
+		b.WriteString(infoStyle.Render("Submitting..."))
+	} else {
+		b.WriteString(infoStyle.Render("↑/↓: Switch field • Enter: Submit • Esc: Cancel"))
+	}
+	if f.lastError != "" {
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render("✗ " + f.lastError))
+	}
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, boxStyle.Render(b.String()))
+}
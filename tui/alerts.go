@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// targetCrossings returns the items of after that are at or below their
+// target but were not in before. Items already under target stay quiet, so
+// each crossing alerts once.
+func targetCrossings(before, after []WatchlistItem) []WatchlistItem {
+	wasUnder := make(map[string]bool, len(before))
+	for _, item := range before {
+		wasUnder[item.Title] = item.UnderTarget()
+	}
+
+	var crossed []WatchlistItem
+	for _, item := range after {
+		if item.UnderTarget() && !wasUnder[item.Title] {
+			crossed = append(crossed, item)
+		}
+	}
+	return crossed
+}
+
+// recordCrossings compares the watchlist against its state before a refresh
+// was cached and records an alert for each item that crossed its target
+func recordCrossings(db *Database, before []WatchlistItem) tea.Msg {
+	after, err := db.GetWatchlist()
+	if err != nil {
+		return nil
+	}
+
+	crossed := targetCrossings(before, after)
+	if len(crossed) == 0 {
+		return nil
+	}
+	for _, item := range crossed {
+		// Alerts are best effort, like the cache they are derived from
+		if err := db.RecordAlert(item.Title, item.LatestPrice, item.TargetPrice); err != nil {
+			logger.Error("failed to record alert", "title", item.Title, "error", err)
+		}
+	}
+	return AlertsMsg{Items: crossed}
+}
+
+// alertText describes a crossing for notifications
+func alertText(item WatchlistItem) string {
+	return fmt.Sprintf("'%s' is down to $%.2f (target $%.2f)", item.Title, item.LatestPrice, item.TargetPrice)
+}
+
+// desktopNotifier shows a desktop notification; tests replace it with a stub
+var desktopNotifier = notifyDesktop
+
+// notifyDesktop shows a desktop notification with notify-send on Linux and
+// the BSDs, or osascript on macOS
+func notifyDesktop(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title))
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on windows")
+	default:
+		cmd = exec.Command("notify-send", title, body)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	// Reap the child in the background so it doesn't linger as a zombie
+	go cmd.Wait()
+
+	return nil
+}
+
+// notifyAlerts sends a desktop notification per crossing off the UI goroutine.
+// A desktop without a notifier is no reason to bother the user, so failures
+// are only logged; the in-app toast and badge still show the alert.
+func notifyAlerts(items []WatchlistItem) tea.Cmd {
+	return func() tea.Msg {
+		for _, item := range items {
+			if err := desktopNotifier("ArbFinder price alert", alertText(item)); err != nil {
+				logger.Debug("desktop notification failed", "error", err)
+			}
+		}
+		return nil
+	}
+}
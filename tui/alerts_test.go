@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTargetCrossings(t *testing.T) {
+	before := []WatchlistItem{
+		{Title: "RTX 3060", TargetPrice: 250, LatestPrice: 300, HasPrice: true},
+		{Title: "ThinkPad X1", TargetPrice: 400, LatestPrice: 350, HasPrice: true},
+		{Title: "Steam Deck", TargetPrice: 300},
+	}
+	after := []WatchlistItem{
+		{Title: "RTX 3060", TargetPrice: 250, LatestPrice: 250, HasPrice: true},
+		{Title: "ThinkPad X1", TargetPrice: 400, LatestPrice: 340, HasPrice: true},
+		{Title: "Steam Deck", TargetPrice: 300, LatestPrice: 320, HasPrice: true},
+		{Title: "iPad Air", TargetPrice: 200, LatestPrice: 150, HasPrice: true},
+	}
+
+	crossed := targetCrossings(before, after)
+	if len(crossed) != 2 {
+		t.Fatalf("Expected 2 crossings, got %+v", crossed)
+	}
+	// Reaching the target exactly counts; staying under it doesn't alert again
+	if crossed[0].Title != "RTX 3060" || crossed[1].Title != "iPad Air" {
+		t.Errorf("Expected RTX 3060 and iPad Air to cross, got %+v", crossed)
+	}
+}
+
+func TestAlertsArePersisted(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.RecordAlert("RTX 3060", 240, 250); err != nil {
+		t.Fatalf("Failed to record alert: %v", err)
+	}
+	if err := db.RecordAlert("ThinkPad X1", 390, 400); err != nil {
+		t.Fatalf("Failed to record alert: %v", err)
+	}
+
+	alerts, err := db.GetUnreadAlerts()
+	if err != nil {
+		t.Fatalf("Failed to get alerts: %v", err)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("Expected 2 unread alerts, got %d", len(alerts))
+	}
+	if alerts[0].Title != "ThinkPad X1" || alerts[0].Price != 390 || alerts[0].TargetPrice != 400 {
+		t.Errorf("Expected the newest alert first, got %+v", alerts[0])
+	}
+	if alerts[0].Read || alerts[0].CreatedAt.IsZero() {
+		t.Errorf("Expected an unread alert with a timestamp, got %+v", alerts[0])
+	}
+
+	if err := db.MarkAlertsRead(); err != nil {
+		t.Fatalf("Failed to mark alerts read: %v", err)
+	}
+	if alerts, _ := db.GetUnreadAlerts(); len(alerts) != 0 {
+		t.Errorf("Expected no unread alerts, got %+v", alerts)
+	}
+}
+
+func TestRefreshCrossingTargetRaisesAlert(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.AddToWatchlist("RTX 3060", 250); err != nil {
+		t.Fatalf("Failed to add to watchlist: %v", err)
+	}
+
+	var notified []string
+	defer func(orig func(string, string) error) { desktopNotifier = orig }(desktopNotifier)
+	desktopNotifier = func(title, body string) error {
+		notified = append(notified, body)
+		return nil
+	}
+
+	m := newTestModel("http://localhost:8080")
+	m.attachDatabase(db)
+
+	listings := []APIListing{{Source: "govdeals", URL: "https://example.com/1", Title: "RTX 3060 Founders Edition", Price: 240}}
+	msg, ok := runCmd(cacheListings(db, listings, m.cachedPrices)).(AlertsMsg)
+	if !ok || len(msg.Items) != 1 || msg.Items[0].Title != "RTX 3060" {
+		t.Fatalf("Expected an alert for RTX 3060, got %+v", msg)
+	}
+
+	tm, cmd := m.Update(msg)
+	m = tm.(model)
+	runCmd(cmd)
+
+	if len(notified) != 1 || !strings.Contains(notified[0], "$240.00") {
+		t.Errorf("Expected one desktop notification, got %v", notified)
+	}
+	if m.unreadAlerts != 1 || !strings.Contains(m.statusBar(), "1 unread alerts") {
+		t.Errorf("Expected an unread alert badge, got %q", m.statusBar())
+	}
+
+	// The same price on the next refresh doesn't alert again
+	m.cachedPrices = make(map[string]float64)
+	if msg := runCmd(cacheListings(db, listings, m.cachedPrices)); msg != nil {
+		t.Errorf("Expected no repeat alert, got %+v", msg)
+	}
+
+	// Opening the Watchlist marks the alerts read
+	m.currentPane = 4
+	tm, _ = m.Update(keyMsg("tab"))
+	m = tm.(model)
+	if m.unreadAlerts != 0 || strings.Contains(m.statusBar(), "unread alerts") {
+		t.Errorf("Expected the badge to clear, got %q", m.statusBar())
+	}
+	if alerts, _ := db.GetUnreadAlerts(); len(alerts) != 0 {
+		t.Errorf("Expected alerts to be marked read, got %+v", alerts)
+	}
+}
+
+func TestNotificationsToggle(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	p := NewConfigPane()
+	p.db = db
+	p.apiClient = NewAPIClient("http://localhost:8080")
+	p.focusIndex = themeFocus
+
+	// Letters are text in the form fields, so 'n' only toggles outside them
+	*p, _ = p.Update(keyMsg("n"))
+	if p.notify {
+		t.Fatal("Expected 'n' to turn desktop notifications off")
+	}
+	if !strings.Contains(p.View(120, 40), "Desktop Notifications: off") {
+		t.Error("Expected the config view to show notifications off")
+	}
+
+	p.newConfigName.SetValue("quiet")
+	p.saveConfig()
+	p.notify = true
+	p.loadConfig("quiet")
+	if p.notify {
+		t.Error("Expected the saved config to turn notifications off again")
+	}
+}
@@ -1,19 +1,141 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
 type APIClient struct {
+	// mu guards the settings below, which the Config pane can change while
+	// requests are in flight
+	mu         sync.RWMutex
 	baseURL    string
 	httpClient *http.Client
+
+	// limiter spaces out outbound requests; it allows any rate unless
+	// SetRateLimit is called
+	limiter *rate.Limiter
+
+	// statsMu guards the statistics cache, which GetStatistics serves until
+	// statsTTL has passed since it was fetched
+	statsMu      sync.Mutex
+	statsTTL     time.Duration
+	statsCache   *APIStatistics
+	statsFetched time.Time
+
+	// AuthToken, when set, is sent with every request. By default it goes in
+	// an "Authorization: Bearer" header; set APIKeyHeader to send it raw in a
+	// custom header (e.g. "X-API-Key") instead. Use SetAuth once the client
+	// is shared.
+	AuthToken    string
+	APIKeyHeader string
+
+	// userAgent is sent with every request so the backend can tell TUI
+	// traffic apart in its logs
+	userAgent string
+}
+
+// APIError is returned when the API answers with a non-200 status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error: %s - %s", e.Status, e.Body)
+}
+
+// newAPIError reads the response body into an APIError
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+// AuthError is returned when the API rejects the client's credentials. It
+// wraps the underlying APIError.
+type AuthError struct {
+	Status string
+	err    *APIError
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("API authentication failed: %s", e.Status)
+}
+
+func (e *AuthError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
+
+// errEmptyBody is wrapped in a DecodeError when a response has no body
+var errEmptyBody = errors.New("empty response body")
+
+// DecodeError is returned when a successful response isn't the JSON the
+// endpoint should return, as when a proxy answers in HTML or cuts the body
+// off
+type DecodeError struct {
+	Endpoint string // request path, without the query
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	switch {
+	case errors.Is(e.Err, errEmptyBody):
+		return fmt.Sprintf("empty response from %s", e.Endpoint)
+	case errors.Is(e.Err, io.ErrUnexpectedEOF):
+		return fmt.Sprintf("truncated response from %s", e.Endpoint)
+	}
+	return fmt.Sprintf("invalid response from %s: %v", e.Endpoint, e.Err)
 }
 
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// checkStatus returns nil for a 2xx response, an AuthError for a 401, and an
+// APIError for anything else
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	apiErr := newAPIError(resp)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return &AuthError{Status: resp.Status, err: apiErr}
+	}
+	return apiErr
+}
+
+// defaultAPIURL is the API used when no URL is configured
+const defaultAPIURL = "http://localhost:8080"
+
+// defaultStatsTTL is how long GetStatistics reuses a fetched result
+const defaultStatsTTL = 30 * time.Second
+
 type APIListing struct {
 	ID        int                    `json:"id"`
 	Source    string                 `json:"source"`
@@ -26,6 +148,31 @@ type APIListing struct {
 	Metadata  map[string]interface{} `json:"meta_json"`
 }
 
+// Time returns when the listing was posted; see parseEpoch
+func (l APIListing) Time() (time.Time, bool) {
+	return parseEpoch(l.Timestamp)
+}
+
+// millisecondEpochs is the smallest timestamp read as milliseconds rather
+// than seconds: 1e12 seconds is tens of millennia away, while 1e12
+// milliseconds is September 2001
+const millisecondEpochs = 1e12
+
+// parseEpoch converts an API timestamp, in seconds or milliseconds since the
+// Unix epoch, to a time. The API sends 0 when a listing has no timestamp, so
+// 0 reports false; any other value, even one near the epoch, is taken as
+// data.
+func parseEpoch(ts float64) (time.Time, bool) {
+	if ts == 0 || math.IsNaN(ts) || math.IsInf(ts, 0) {
+		return time.Time{}, false
+	}
+	if math.Abs(ts) >= millisecondEpochs {
+		ts /= 1000
+	}
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
 type APIStatistics struct {
 	TotalListings  int     `json:"total_listings"`
 	UniqueSourcers int     `json:"unique_sources"`
@@ -34,11 +181,28 @@ type APIStatistics struct {
 	MaxPrice       float64 `json:"max_price"`
 }
 
+// HealthStatus is the backend's report on itself from /api/health
+type HealthStatus struct {
+	Status        string  `json:"status"`
+	Version       string  `json:"version"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	DBConnected   bool    `json:"db_connected"`
+
+	// Basic is set when the backend has no health endpoint and answered a
+	// plain ping instead; the other fields are then unknown
+	Basic bool `json:"-"`
+}
+
+// Uptime is how long the backend has been running
+func (h HealthStatus) Uptime() time.Duration {
+	return time.Duration(h.UptimeSeconds * float64(time.Second))
+}
+
 type APIResponse struct {
-	Items []APIListing `json:"items"`
-	Total int          `json:"total"`
-	Limit int          `json:"limit"`
-	Offset int         `json:"offset"`
+	Items  []APIListing `json:"items"`
+	Total  int          `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
 }
 
 type APIComp struct {
@@ -49,22 +213,190 @@ type APIComp struct {
 	Timestamp   float64 `json:"ts"`
 }
 
+// TrendPoint is the average price of the listings seen in one bucket of a
+// price trend, stamped with the start of the bucket
+type TrendPoint struct {
+	Timestamp float64 `json:"ts"`
+	AvgPrice  float64 `json:"avg_price"`
+	Count     int     `json:"count"`
+}
+
+// Time returns the start of the point's bucket; see parseEpoch
+func (p TrendPoint) Time() (time.Time, bool) {
+	return parseEpoch(p.Timestamp)
+}
+
+// defaultUserAgent names the TUI and its version to the backend
+func defaultUserAgent() string {
+	return "arbfinder-tui/" + version
+}
+
+// defaultTimeout bounds each request, including reading the response body
+const defaultTimeout = 30 * time.Second
+
+// The connection pool keeps enough idle connections to the API for a
+// multi-source search or an auto-refresh burst to reuse rather than redial,
+// where the default transport keeps only two per host
+const (
+	defaultMaxIdleConnsPerHost = 16
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// maxDrainBytes bounds how much of an unread response body is discarded to
+// return its connection to the pool; longer bodies close the connection
+const maxDrainBytes = 64 << 10
+
 // NewAPIClient creates a new API client
 func NewAPIClient(baseURL string) *APIClient {
+	return NewAPIClientWithTimeout(baseURL, defaultTimeout)
+}
+
+// NewAPIClientWithTimeout creates a new API client whose requests give up
+// after timeout
+func NewAPIClientWithTimeout(baseURL string, timeout time.Duration) *APIClient {
 	if baseURL == "" {
-		baseURL = "http://localhost:8080"
+		baseURL = defaultAPIURL
 	}
 
 	return &APIClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   timeout,
+			Transport: newTransport(defaultMaxIdleConnsPerHost, defaultIdleConnTimeout),
 		},
+		limiter:   rate.NewLimiter(rate.Inf, 0),
+		statsTTL:  defaultStatsTTL,
+		userAgent: defaultUserAgent(),
 	}
 }
 
+// BaseURL returns the API base URL requests are sent to
+func (c *APIClient) BaseURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.baseURL
+}
+
+// SetBaseURL points the client at a new API base URL
+func (c *APIClient) SetBaseURL(baseURL string) error {
+	u, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid API URL %q: %w", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q: expected http(s)://host[:port]", baseURL)
+	}
+
+	c.mu.Lock()
+	c.baseURL = strings.TrimRight(baseURL, "/")
+	c.mu.Unlock()
+
+	// Statistics from the old server no longer apply
+	c.statsMu.Lock()
+	c.statsCache = nil
+	c.statsMu.Unlock()
+	return nil
+}
+
+// Timeout returns how long a request may take before it is abandoned
+func (c *APIClient) Timeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.httpClient.Timeout
+}
+
+// SetTimeout sets how long later requests may take before they are
+// abandoned; requests already in flight keep their timeout
+func (c *APIClient) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client := *c.httpClient
+	client.Timeout = timeout
+	c.httpClient = &client
+}
+
+// SetConnectionPool sets how many idle connections to the API are kept for
+// reuse and for how long. Non-positive values keep the defaults. Idle
+// connections of the previous pool are closed.
+func (c *APIClient) SetConnectionPool(maxIdleConnsPerHost int, idleConnTimeout time.Duration) {
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = defaultIdleConnTimeout
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client := *c.httpClient
+	client.Transport = newTransport(maxIdleConnsPerHost, idleConnTimeout)
+	c.httpClient.CloseIdleConnections()
+	c.httpClient = &client
+}
+
+// newTransport is the default transport with its idle connection pool sized
+// for the API
+func newTransport(maxIdleConnsPerHost int, idleConnTimeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	if transport.MaxIdleConns < maxIdleConnsPerHost {
+		transport.MaxIdleConns = maxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = idleConnTimeout
+	return transport
+}
+
+// SetStatsTTL sets how long GetStatistics reuses a fetched result; zero
+// disables the cache
+func (c *APIClient) SetStatsTTL(ttl time.Duration) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.statsTTL = ttl
+}
+
+// SetAuth sets the credentials sent with every request
+func (c *APIClient) SetAuth(token, header string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AuthToken = token
+	c.APIKeyHeader = header
+}
+
+// SetUserAgent sets the User-Agent sent with every request; empty restores
+// the default
+func (c *APIClient) SetUserAgent(userAgent string) {
+	if userAgent == "" {
+		userAgent = defaultUserAgent()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.userAgent = userAgent
+}
+
+// SetRateLimit caps outbound requests at requestsPerSecond, allowing bursts
+// of up to burst requests. A non-positive rate removes the limit.
+func (c *APIClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	limit := rate.Limit(requestsPerSecond)
+	if requestsPerSecond <= 0 {
+		limit = rate.Inf
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limiter = rate.NewLimiter(limit, burst)
+}
+
 // GetListings retrieves listings from the API
 func (c *APIClient) GetListings(limit, offset int, source, orderBy string) ([]APIListing, error) {
+	return c.GetListingsCtx(context.Background(), limit, offset, source, orderBy)
+}
+
+// GetListingsCtx retrieves listings from the API, aborting if ctx is cancelled
+func (c *APIClient) GetListingsCtx(ctx context.Context, limit, offset int, source, orderBy string) ([]APIListing, error) {
 	params := url.Values{}
 	params.Add("limit", fmt.Sprintf("%d", limit))
 	params.Add("offset", fmt.Sprintf("%d", offset))
@@ -75,130 +407,572 @@ func (c *APIClient) GetListings(limit, offset int, source, orderBy string) ([]AP
 		params.Add("order_by", orderBy)
 	}
 
-	url := fmt.Sprintf("%s/api/listings?%s", c.baseURL, params.Encode())
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
+	var apiResp APIResponse
+	if err := c.getList(ctx, "/api/listings", params, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to get listings: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
-	}
+	return apiResp.Items, nil
+}
 
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// GetRecentListings retrieves the newest listings across all sources
+func (c *APIClient) GetRecentListings(limit int) ([]APIListing, error) {
+	return c.GetRecentListingsCtx(context.Background(), limit)
+}
 
-	return apiResp.Items, nil
+// GetRecentListingsCtx retrieves the newest listings, aborting if ctx is cancelled
+func (c *APIClient) GetRecentListingsCtx(ctx context.Context, limit int) ([]APIListing, error) {
+	return c.GetListingsCtx(ctx, limit, 0, "", "")
 }
 
 // SearchListings searches for listings
 func (c *APIClient) SearchListings(query string) ([]APIListing, error) {
+	return c.SearchListingsCtx(context.Background(), query)
+}
+
+// SearchListingsCtx searches for listings, aborting if ctx is cancelled
+func (c *APIClient) SearchListingsCtx(ctx context.Context, query string) ([]APIListing, error) {
+	return c.SearchListingsBySourceCtx(ctx, query, "", SearchFilter{})
+}
+
+// SearchListingsBySource searches for listings from one source that pass
+// filter; empty or "all" searches every source
+func (c *APIClient) SearchListingsBySource(query, source string, filter SearchFilter) ([]APIListing, error) {
+	return c.SearchListingsBySourceCtx(context.Background(), query, source, filter)
+}
+
+// SearchListingsBySourceCtx searches for listings from one source, aborting if
+// ctx is cancelled
+func (c *APIClient) SearchListingsBySourceCtx(ctx context.Context, query, source string, filter SearchFilter) ([]APIListing, error) {
 	params := url.Values{}
 	params.Add("q", query)
+	addSource(params, source)
+	filter.addTo(params)
 
-	url := fmt.Sprintf("%s/api/listings/search?%s", c.baseURL, params.Encode())
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
+	var apiResp APIResponse
+	if err := c.getList(ctx, "/api/listings/search", params, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to search listings: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	return apiResp.Items, nil
+}
+
+// searchAllConcurrency bounds the requests SearchAllSources has in flight
+const searchAllConcurrency = 4
+
+// SearchAllSources searches each source with its own request, a few at a
+// time, rather than leaving the server to aggregate them
+func (c *APIClient) SearchAllSources(query string, sources []string, filter SearchFilter) ([]APIListing, error) {
+	return c.SearchAllSourcesCtx(context.Background(), query, sources, filter)
+}
+
+// SearchAllSourcesCtx searches each source concurrently, aborting if ctx is
+// cancelled. Results are merged in source order, listings whose URL an
+// earlier one had are dropped and listings without a source get the one
+// they were found under. Sources that fail are reported together in the
+// error, alongside the listings the others returned.
+func (c *APIClient) SearchAllSourcesCtx(ctx context.Context, query string, sources []string, filter SearchFilter) ([]APIListing, error) {
+	found := make([][]APIListing, len(sources))
+	errs := make([]error, len(sources))
+
+	// Failures are collected rather than returned, so one source failing
+	// doesn't cancel the others
+	var g errgroup.Group
+	g.SetLimit(searchAllConcurrency)
+	for i, source := range sources {
+		g.Go(func() error {
+			listings, err := c.SearchListingsBySourceCtx(ctx, query, source, filter)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", source, err)
+			}
+			found[i] = listings
+			return nil
+		})
+	}
+	g.Wait()
+
+	var merged []APIListing
+	seen := make(map[string]bool)
+	for i, listings := range found {
+		for _, l := range listings {
+			if l.URL != "" {
+				if seen[l.URL] {
+					continue
+				}
+				seen[l.URL] = true
+			}
+			if l.Source == "" {
+				l.Source = sources[i]
+			}
+			merged = append(merged, l)
+		}
 	}
 
+	return merged, errors.Join(errs...)
+}
+
+// SearchListingsPage searches for listings from source ("" or "all" for
+// every source) that pass filter, returning one page of matches along with
+// the server's total count
+func (c *APIClient) SearchListingsPage(query, source string, filter SearchFilter, limit, offset int) (APIResponse, error) {
+	return c.SearchListingsPageCtx(context.Background(), query, source, filter, limit, offset)
+}
+
+// SearchListingsPageCtx searches for a page of listings, aborting if ctx is
+// cancelled
+func (c *APIClient) SearchListingsPageCtx(ctx context.Context, query, source string, filter SearchFilter, limit, offset int) (APIResponse, error) {
+	params := url.Values{}
+	params.Add("q", query)
+	addSource(params, source)
+	filter.addTo(params)
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	params.Add("offset", fmt.Sprintf("%d", offset))
+
 	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.getList(ctx, "/api/listings/search", params, &apiResp); err != nil {
+		return APIResponse{}, fmt.Errorf("failed to search listings: %w", err)
 	}
 
-	return apiResp.Items, nil
+	return apiResp, nil
 }
 
-// GetStatistics retrieves statistics from the API
-func (c *APIClient) GetStatistics() (*APIStatistics, error) {
-	url := fmt.Sprintf("%s/api/statistics", c.baseURL)
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get statistics: %w", err)
+// GetListingByID retrieves a single listing with every field the API has
+// for it. A listing the API doesn't know yields an APIError with status 404.
+func (c *APIClient) GetListingByID(id int) (*APIListing, error) {
+	return c.GetListingByIDCtx(context.Background(), id)
+}
+
+// GetListingByIDCtx retrieves a single listing, aborting if ctx is cancelled
+func (c *APIClient) GetListingByIDCtx(ctx context.Context, id int) (*APIListing, error) {
+	var listing APIListing
+	if err := c.get(ctx, "/api/listings/"+strconv.Itoa(id), nil, &listing); err != nil {
+		return nil, fmt.Errorf("failed to get listing %d: %w", id, err)
+	}
+
+	return &listing, nil
+}
+
+// addSource scopes a search to source, unless it asks for every source
+func addSource(params url.Values, source string) {
+	if source != "" && source != "all" {
+		params.Add("source", source)
 	}
-	defer resp.Body.Close()
+}
+
+// SearchFilter narrows a search by price and condition. Zero price bounds
+// are open and no conditions means any condition.
+type SearchFilter struct {
+	MinPrice   float64
+	MaxPrice   float64
+	Conditions []string // searchConditions values
+}
+
+// addTo adds the filter to a search's query parameters, one condition
+// parameter per selected condition
+func (f SearchFilter) addTo(params url.Values) {
+	if f.MinPrice > 0 {
+		params.Add("min_price", strconv.FormatFloat(f.MinPrice, 'f', -1, 64))
+	}
+	if f.MaxPrice > 0 {
+		params.Add("max_price", strconv.FormatFloat(f.MaxPrice, 'f', -1, 64))
+	}
+	for _, condition := range f.Conditions {
+		params.Add("condition", condition)
+	}
+}
+
+// matches reports whether a listing passes the filter, for applying it
+// locally to cached listings and to servers that ignore it
+func (f SearchFilter) matches(l APIListing) bool {
+	if f.MinPrice > 0 && l.Price < f.MinPrice {
+		return false
+	}
+	if f.MaxPrice > 0 && l.Price > f.MaxPrice {
+		return false
+	}
+	if len(f.Conditions) == 0 {
+		return true
+	}
+	for _, condition := range f.Conditions {
+		if conditionMatches(l.Condition, condition) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatistics retrieves statistics from the API, reusing a recent result
+// if there is one
+func (c *APIClient) GetStatistics() (*APIStatistics, error) {
+	return c.GetStatisticsCtx(context.Background())
+}
+
+// GetStatisticsCtx retrieves statistics from the API or the cache, aborting
+// if ctx is cancelled
+func (c *APIClient) GetStatisticsCtx(ctx context.Context) (*APIStatistics, error) {
+	c.statsMu.Lock()
+	cached, fresh := c.statsCache, time.Since(c.statsFetched) < c.statsTTL
+	c.statsMu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	if cached != nil && fresh {
+		stats := *cached
+		return &stats, nil
 	}
 
+	return c.ForceRefreshStatisticsCtx(ctx)
+}
+
+// ForceRefreshStatistics retrieves statistics from the API, bypassing and
+// then updating the cache
+func (c *APIClient) ForceRefreshStatistics() (*APIStatistics, error) {
+	return c.ForceRefreshStatisticsCtx(context.Background())
+}
+
+// ForceRefreshStatisticsCtx retrieves statistics from the API, bypassing the
+// cache, aborting if ctx is cancelled
+func (c *APIClient) ForceRefreshStatisticsCtx(ctx context.Context) (*APIStatistics, error) {
 	var stats APIStatistics
-	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.get(ctx, "/api/statistics", nil, &stats); err != nil {
+		return nil, fmt.Errorf("failed to get statistics: %w", err)
 	}
 
+	c.statsMu.Lock()
+	cached := stats
+	c.statsCache = &cached
+	c.statsFetched = time.Now()
+	c.statsMu.Unlock()
+
 	return &stats, nil
 }
 
+// GetStatisticsBySource retrieves per-source statistics from the API. Older
+// servers don't provide them and answer with an APIError.
+func (c *APIClient) GetStatisticsBySource() (map[string]SourceStat, error) {
+	return c.GetStatisticsBySourceCtx(context.Background())
+}
+
+// GetStatisticsBySourceCtx retrieves per-source statistics from the API,
+// aborting if ctx is cancelled
+func (c *APIClient) GetStatisticsBySourceCtx(ctx context.Context) (map[string]SourceStat, error) {
+	var stats map[string]SourceStat
+	if err := c.getList(ctx, "/api/statistics/by_source", nil, &stats); err != nil {
+		return nil, fmt.Errorf("failed to get statistics by source: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CreateListing submits a listing found by hand and returns it as the API
+// stored it. Listings the API rejects yield an APIError carrying its
+// validation message.
+func (c *APIClient) CreateListing(l APIListing) (*APIListing, error) {
+	return c.CreateListingCtx(context.Background(), l)
+}
+
+// CreateListingCtx submits a listing, aborting if ctx is cancelled
+func (c *APIClient) CreateListingCtx(ctx context.Context, l APIListing) (*APIListing, error) {
+	var created APIListing
+	if err := c.post(ctx, "/api/listings", l, &created); err != nil {
+		return nil, fmt.Errorf("failed to create listing: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetSources retrieves the names of the providers the API can search
+func (c *APIClient) GetSources() ([]string, error) {
+	return c.GetSourcesCtx(context.Background())
+}
+
+// GetSourcesCtx retrieves the provider names, aborting if ctx is cancelled
+func (c *APIClient) GetSourcesCtx(ctx context.Context) ([]string, error) {
+	var sources []string
+	if err := c.getList(ctx, "/api/sources", nil, &sources); err != nil {
+		return nil, fmt.Errorf("failed to get sources: %w", err)
+	}
+
+	return sources, nil
+}
+
 // GetComps retrieves comparable prices
 func (c *APIClient) GetComps(query string) ([]APIComp, error) {
+	return c.GetCompsCtx(context.Background(), query)
+}
+
+// GetCompsCtx retrieves comparable prices, aborting if ctx is cancelled
+func (c *APIClient) GetCompsCtx(ctx context.Context, query string) ([]APIComp, error) {
+	path := "/api/comps"
 	params := url.Values{}
 	if query != "" {
+		path = "/api/comps/search"
 		params.Add("q", query)
-		url := fmt.Sprintf("%s/api/comps/search?%s", c.baseURL, params.Encode())
-		resp, err := c.httpClient.Get(url)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get comps: %w", err)
-		}
-		defer resp.Body.Close()
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
-		}
+	var comps []APIComp
+	if err := c.getList(ctx, path, params, &comps); err != nil {
+		return nil, fmt.Errorf("failed to get comps: %w", err)
+	}
 
-		var comps []APIComp
-		if err := json.NewDecoder(resp.Body).Decode(&comps); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
+	return comps, nil
+}
+
+// GetPriceTrend retrieves the average price of listings matching query over
+// time, bucketed by interval
+func (c *APIClient) GetPriceTrend(query, source, interval string) ([]TrendPoint, error) {
+	return c.GetPriceTrendCtx(context.Background(), query, source, interval)
+}
+
+// GetPriceTrendCtx retrieves the average price of listings matching query,
+// from source when one is given, in buckets of interval ("day", "week" or
+// "month"), oldest first. It aborts if ctx is cancelled.
+func (c *APIClient) GetPriceTrendCtx(ctx context.Context, query, source, interval string) ([]TrendPoint, error) {
+	if !validTrendInterval(interval) {
+		return nil, fmt.Errorf("failed to get price trend: unknown interval %q", interval)
+	}
+
+	params := url.Values{}
+	params.Add("q", query)
+	addSource(params, source)
+	params.Add("interval", interval)
+
+	var points []TrendPoint
+	if err := c.getList(ctx, "/api/comps/trend", params, &points); err != nil {
+		return nil, fmt.Errorf("failed to get price trend: %w", err)
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Timestamp < points[j].Timestamp
+	})
+
+	return points, nil
+}
+
+// Ping checks if the API is reachable
+func (c *APIClient) Ping() error {
+	return c.PingCtx(context.Background())
+}
+
+// PingCtx checks if the API is reachable, aborting if ctx is cancelled
+func (c *APIClient) PingCtx(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodGet, "/", nil)
+	if err != nil {
+		return fmt.Errorf("failed to ping API: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("failed to ping API: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	return checkStatus(resp)
+}
+
+// Health retrieves the backend's health report
+func (c *APIClient) Health() (*HealthStatus, error) {
+	return c.HealthCtx(context.Background())
+}
+
+// HealthCtx retrieves the backend's health report, aborting if ctx is
+// cancelled. Backends without /api/health degrade to a basic status when
+// they still answer a ping.
+func (c *APIClient) HealthCtx(ctx context.Context) (*HealthStatus, error) {
+	var health HealthStatus
+	err := c.get(ctx, "/api/health", nil, &health)
+	if err == nil {
+		return &health, nil
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		return nil, fmt.Errorf("failed to get health: %w", err)
+	}
+	if err := c.PingCtx(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get health: %w", err)
+	}
+	return &HealthStatus{Status: "reachable", Basic: true}, nil
+}
+
+// get issues a GET request for path with the given query parameters and
+// decodes the JSON response body into v
+func (c *APIClient) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.send(ctx, http.MethodGet, path, nil, v)
+}
+
+// getList is get for endpoints returning a collection, where an empty
+// response body means there is nothing to return and leaves v empty
+func (c *APIClient) getList(ctx context.Context, path string, params url.Values, v interface{}) error {
+	err := c.get(ctx, path, params, v)
+	if errors.Is(err, errEmptyBody) {
+		return nil
+	}
+	return err
+}
+
+// post issues a POST request for path with body encoded as JSON and decodes
+// the JSON response body into v
+func (c *APIClient) post(ctx context.Context, path string, body, v interface{}) error {
+	return c.send(ctx, http.MethodPost, path, body, v)
+}
+
+// send issues a request for path, with body encoded as JSON unless it is
+// nil, and decodes the JSON response body into v. A body that isn't JSON,
+// including an empty one, yields a DecodeError.
+func (c *APIClient) send(ctx context.Context, method, path string, body, v interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
 		}
+		reqBody = bytes.NewReader(encoded)
+	}
 
-		return comps, nil
+	req, err := c.newRequest(ctx, method, path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	url := fmt.Sprintf("%s/api/comps", c.baseURL)
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get comps: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	if err := checkStatus(resp); err != nil {
+		return err
 	}
 
-	var comps []APIComp
-	if err := json.NewDecoder(resp.Body).Decode(&comps); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errEmptyBody
+		}
+		return &DecodeError{Endpoint: req.URL.Path, Err: err}
 	}
 
-	return comps, nil
+	return nil
 }
 
-// Ping checks if the API is reachable
-func (c *APIClient) Ping() error {
-	url := fmt.Sprintf("%s/", c.baseURL)
-	resp, err := c.httpClient.Get(url)
+// closeBody reads what is left of a response body, up to maxDrainBytes, and
+// closes it, so the connection can be reused for the next request
+func closeBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// do sends req once the rate limiter allows it, giving up if the request's
+// context is cancelled while waiting
+func (c *APIClient) do(req *http.Request) (*http.Response, error) {
+	c.mu.RLock()
+	limiter, client := c.limiter, c.httpClient
+	c.mu.RUnlock()
+
+	if err := limiter.Wait(req.Context()); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	attrs := []any{"method", req.Method, "path", req.URL.RequestURI(), "request_id", req.Header.Get("X-Request-ID")}
+	switch {
+	case err != nil && errors.Is(err, context.Canceled):
+		logger.Debug("API request cancelled", attrs...)
+	case err != nil:
+		logger.Warn("API request failed", append(attrs, "error", err)...)
+	case resp.StatusCode >= 400:
+		logger.Warn("API request failed", append(attrs, "status", resp.Status)...)
+	default:
+		logger.Debug("API request", append(attrs, "status", resp.Status, "duration", time.Since(start))...)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to ping API: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	if err := decompressBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API returned non-200 status: %s", resp.Status)
+// decompressBody swaps a gzip or deflate encoded response body for one that
+// decodes it. The transport only does this itself when it chose the
+// Accept-Encoding header, and newRequest sets it.
+func decompressBody(resp *http.Response) error {
+	var body io.ReadCloser
+	var err error
+	switch encoding := strings.ToLower(resp.Header.Get("Content-Encoding")); encoding {
+	case "", "identity":
+		return nil
+	case "gzip":
+		body, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		body, err = zlib.NewReader(resp.Body)
+	default:
+		return fmt.Errorf("unsupported response encoding %q", encoding)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decompress response: %w", err)
 	}
 
+	resp.Body = &decompressedBody{ReadCloser: body, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
 	return nil
 }
+
+// decompressedBody reads through a decompressor and closes the raw body
+// underneath it too
+type decompressedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	b.ReadCloser.Close()
+	return b.raw.Close()
+}
+
+// newRequest builds a request for path relative to the base URL, carrying
+// the client's User-Agent and credentials and a fresh X-Request-ID the
+// backend can log it under. Compressed responses are accepted, as listing
+// pages shrink several times over.
+func (c *APIClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-Request-ID", newRequestID())
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	if c.AuthToken != "" {
+		if c.APIKeyHeader != "" {
+			req.Header.Set(c.APIKeyHeader, c.AuthToken)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+		}
+	}
+
+	return req, nil
+}
+
+// newRequestID returns a random version 4 UUID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
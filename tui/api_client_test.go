@@ -0,0 +1,1026 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGetRecentListings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings" {
+			t.Errorf("Expected path '/api/listings', got '%s'", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("limit") != "25" {
+			t.Errorf("Expected limit '25', got '%s'", query.Get("limit"))
+		}
+		if query.Get("offset") != "0" {
+			t.Errorf("Expected offset '0', got '%s'", query.Get("offset"))
+		}
+		if query.Has("source") || query.Has("order_by") {
+			t.Errorf("Expected no source or order_by, got '%s'", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(APIResponse{
+			Items: []APIListing{{ID: 7, Source: "govdeals", Title: "Dell Monitor", Price: 49.5}},
+			Total: 1,
+			Limit: 25,
+		})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	listings, err := client.GetRecentListings(25)
+	if err != nil {
+		t.Fatalf("Failed to get recent listings: %v", err)
+	}
+
+	if len(listings) != 1 {
+		t.Fatalf("Expected 1 listing, got %d", len(listings))
+	}
+
+	if listings[0].Title != "Dell Monitor" {
+		t.Errorf("Expected title 'Dell Monitor', got '%s'", listings[0].Title)
+	}
+}
+
+func TestSearchListingsCtxCancel(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error)
+	go func() {
+		_, err := client.SearchListingsCtx(ctx, "rtx 3060")
+		errs <- err
+	}()
+
+	<-started
+	cancel()
+
+	err := <-errs
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestShortTimeoutFailsPromptly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClientWithTimeout(server.URL, 50*time.Millisecond)
+	start := time.Now()
+	_, err := client.SearchListings("rtx 3060")
+	elapsed := time.Since(start)
+
+	var netErr interface{ Timeout() bool }
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Expected a timeout error, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("Expected the request to give up promptly, took %v", elapsed)
+	}
+
+	client.SetTimeout(time.Minute)
+	if got := client.Timeout(); got != time.Minute {
+		t.Errorf("Expected timeout 1m, got %v", got)
+	}
+}
+
+func TestGetListingByID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings/42" {
+			http.Error(w, `{"detail": "Listing not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id": 42, "source": "govdeals", "title": "RTX 3060", "price": 249.99, "condition": "used", "meta_json": {"bids": 7}}`))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	listing, err := client.GetListingByID(42)
+	if err != nil {
+		t.Fatalf("Failed to get listing: %v", err)
+	}
+	if listing.ID != 42 || listing.Title != "RTX 3060" || listing.Condition != "used" {
+		t.Errorf("Unexpected listing %+v", listing)
+	}
+	if listing.Metadata["bids"] != 7.0 {
+		t.Errorf("Expected metadata bids 7, got %v", listing.Metadata["bids"])
+	}
+
+	_, err = client.GetListingByID(43)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 APIError, got %v", err)
+	}
+}
+
+func TestCreateListing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/listings" {
+			t.Errorf("Expected POST /api/listings, got %s %s", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Expected a JSON body, got Content-Type '%s'", ct)
+		}
+
+		var l APIListing
+		if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if l.Price <= 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"detail": "price must be positive"}`))
+			return
+		}
+
+		l.ID = 99
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(l)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	created, err := client.CreateListing(APIListing{Source: "manual", Title: "RTX 3060", Price: 120, Condition: "used"})
+	if err != nil {
+		t.Fatalf("Failed to create listing: %v", err)
+	}
+	if created.ID != 99 || created.Title != "RTX 3060" || created.Condition != "used" {
+		t.Errorf("Unexpected created listing %+v", created)
+	}
+
+	_, err = client.CreateListing(APIListing{Source: "manual", Title: "Free stuff"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected a 422 APIError, got %v", err)
+	}
+	if !strings.Contains(apiErr.Body, "price must be positive") {
+		t.Errorf("Expected the validation message, got '%s'", apiErr.Body)
+	}
+}
+
+func TestAuthTokenHeader(t *testing.T) {
+	var gotAuth, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotKey = r.Header.Get("X-API-Key")
+		json.NewEncoder(w).Encode(APIStatistics{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.AuthToken = "secret"
+	if _, err := client.GetStatistics(); err != nil {
+		t.Fatalf("Failed to get statistics: %v", err)
+	}
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Expected Authorization 'Bearer secret', got '%s'", gotAuth)
+	}
+
+	client.APIKeyHeader = "X-API-Key"
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+	if gotKey != "secret" {
+		t.Errorf("Expected X-API-Key 'secret', got '%s'", gotKey)
+	}
+	if gotAuth != "" {
+		t.Errorf("Expected no Authorization header, got '%s'", gotAuth)
+	}
+}
+
+func TestRequestsCarryUserAgentAndRequestID(t *testing.T) {
+	var agents, ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.Header.Get("User-Agent"))
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.SearchListings("rtx 3060")
+	client.Ping()
+	client.SetUserAgent("arbfinder-tui/test (ops@example.com)")
+	client.GetRecentListings(10)
+
+	want := []string{"arbfinder-tui/" + version, "arbfinder-tui/" + version, "arbfinder-tui/test (ops@example.com)"}
+	if strings.Join(agents, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected User-Agents %q, got %q", want, agents)
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if !uuid.MatchString(id) {
+			t.Errorf("Expected a UUID request ID, got '%s'", id)
+		}
+		if seen[id] {
+			t.Errorf("Expected a fresh request ID per request, got '%s' twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+// largeListingPage is a page of listings the size of a big search result
+func largeListingPage() APIResponse {
+	page := APIResponse{Total: 500, Limit: 500}
+	for i := 0; i < 500; i++ {
+		page.Items = append(page.Items, APIListing{
+			ID:        i,
+			Source:    "govdeals",
+			URL:       fmt.Sprintf("https://www.govdeals.com/asset/%d", 100000+i),
+			Title:     fmt.Sprintf("NVIDIA GeForce RTX 3060 12GB Graphics Card lot %d", i),
+			Price:     200 + float64(i%50),
+			Currency:  "USD",
+			Condition: "used",
+			Timestamp: float64(1700000000 + i*60),
+			Metadata:  map[string]interface{}{"seller": "surplus-dept", "location": "Austin, TX"},
+		})
+	}
+	return page
+}
+
+func TestGzipResponsesAreDecoded(t *testing.T) {
+	page := largeListingPage()
+	raw, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("Failed to encode page: %v", err)
+	}
+
+	var sent int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			t.Errorf("Expected gzip to be accepted, got '%s'", r.Header.Get("Accept-Encoding"))
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(raw)
+		zw.Close()
+		sent = buf.Len()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	got, err := NewAPIClient(server.URL).SearchListingsPage("rtx 3060", "", SearchFilter{}, 500, 0)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(got.Items) != 500 || got.Items[499].Title != page.Items[499].Title {
+		t.Fatalf("Expected all 500 listings decoded, got %d", len(got.Items))
+	}
+
+	// The bandwidth saving documented in the README
+	t.Logf("500 listings: %d bytes raw, %d gzipped (%.1fx smaller)", len(raw), sent, float64(len(raw))/float64(sent))
+	if sent*4 > len(raw) {
+		t.Errorf("Expected gzip to shrink the page at least 4x, got %d of %d bytes", sent, len(raw))
+	}
+}
+
+func TestDeflateErrorBodiesAreDecoded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "deflate")
+		w.WriteHeader(http.StatusBadRequest)
+		zw := zlib.NewWriter(w)
+		zw.Write([]byte("bad query"))
+		zw.Close()
+	}))
+	defer server.Close()
+
+	_, err := NewAPIClient(server.URL).SearchListings("rtx 3060")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.Body != "bad query" {
+		t.Errorf("Expected the decoded body 'bad query', got '%s'", apiErr.Body)
+	}
+}
+
+func TestUnauthorizedReturnsAuthError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	_, err := client.SearchListings("rtx")
+
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected AuthError, got %v", err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"status": "ok", "version": "1.4.2", "uptime_seconds": 11045.5, "db_connected": true}`))
+	}))
+	defer server.Close()
+
+	health, err := NewAPIClient(server.URL).Health()
+	if err != nil {
+		t.Fatalf("Failed to get health: %v", err)
+	}
+	if health.Status != "ok" || health.Version != "1.4.2" || !health.DBConnected || health.Basic {
+		t.Errorf("Unexpected health %+v", health)
+	}
+	if want := 3*time.Hour + 4*time.Minute + 5500*time.Millisecond; health.Uptime() != want {
+		t.Errorf("Expected uptime %v, got %v", want, health.Uptime())
+	}
+
+	p := NewStatsPane(nil)
+	p.ApplyStats(StatsLoadedMsg{Health: health})
+	view := p.View(120, 40)
+	for _, want := range []string{"Backend Health", "1.4.2", "3h4m6s", "connected"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the stats view to show %q", want)
+		}
+	}
+	if strings.Contains(view, "disconnected") {
+		t.Error("Expected the database to show as connected")
+	}
+}
+
+func TestHealthFallsBackToPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	health, err := NewAPIClient(server.URL).Health()
+	if err != nil {
+		t.Fatalf("Expected a basic status without a health endpoint, got %v", err)
+	}
+	if !health.Basic || health.Status != "reachable" {
+		t.Errorf("Expected a basic reachable status, got %+v", health)
+	}
+
+	server.Close()
+	if _, err := NewAPIClient(server.URL).Health(); err == nil {
+		t.Error("Expected an error once the backend is down")
+	}
+}
+
+func TestGetPriceTrend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/comps/trend" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("q") != "rtx 3060" || q.Get("source") != "ebay" || q.Get("interval") != "month" {
+			t.Errorf("Unexpected trend query %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[
+			{"ts": 1738368000, "avg_price": 262.5, "count": 4},
+			{"ts": 1735689600, "avg_price": 281.0, "count": 9},
+			{"ts": 1740787200000, "avg_price": 249.99, "count": 1}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	points, err := client.GetPriceTrend("rtx 3060", "ebay", "month")
+	if err != nil {
+		t.Fatalf("Failed to get price trend: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("Expected 3 points, got %+v", points)
+	}
+	for i, want := range []struct {
+		month    time.Month
+		avgPrice float64
+		count    int
+	}{{time.January, 281.0, 9}, {time.February, 262.5, 4}, {time.March, 249.99, 1}} {
+		at, ok := points[i].Time()
+		if !ok || at.UTC().Month() != want.month || points[i].AvgPrice != want.avgPrice || points[i].Count != want.count {
+			t.Errorf("Point %d: expected %v $%.2f x%d oldest first, got %v %+v", i, want.month, want.avgPrice, want.count, at, points[i])
+		}
+	}
+
+	if _, err := client.GetPriceTrend("rtx 3060", "", "fortnight"); err == nil {
+		t.Error("Expected an unknown interval to be rejected")
+	}
+}
+
+func TestAPIErrorPreservesStatusCode(t *testing.T) {
+	for _, code := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "something went wrong", code)
+		}))
+		client := NewAPIClient(server.URL)
+
+		calls := map[string]func() error{
+			"SearchListings": func() error { _, err := client.SearchListings("rtx"); return err },
+			"GetListings":    func() error { _, err := client.GetListings(10, 0, "", ""); return err },
+			"GetStatistics":  func() error { _, err := client.GetStatistics(); return err },
+			"GetComps":       func() error { _, err := client.GetComps("rtx"); return err },
+			"Ping":           client.Ping,
+		}
+		for name, call := range calls {
+			var apiErr *APIError
+			if err := call(); !errors.As(err, &apiErr) {
+				t.Errorf("%s (%d): expected APIError, got %v", name, code, err)
+				continue
+			}
+			if apiErr.StatusCode != code {
+				t.Errorf("%s: expected status code %d, got %d", name, code, apiErr.StatusCode)
+			}
+			if apiErr.Body != "something went wrong" {
+				t.Errorf("%s: expected body 'something went wrong', got '%s'", name, apiErr.Body)
+			}
+		}
+
+		server.Close()
+	}
+}
+
+func TestAuthErrorUnwrapsToAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewAPIClient(server.URL).GetStatistics()
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected APIError with status 401, got %v", err)
+	}
+}
+
+// newPagedSearchServer serves /api/listings/search from a fixture of n
+// listings, honouring the limit and offset parameters
+func newPagedSearchServer(t *testing.T, n int) *httptest.Server {
+	fixture := make([]APIListing, n)
+	for i := range fixture {
+		fixture[i] = APIListing{ID: i + 1, Source: "shopgoodwill", URL: fmt.Sprintf("https://example.com/%d", i+1), Title: fmt.Sprintf("Item %d", i+1), Price: float64(i + 1)}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Anything else, such as the comps lookup that follows a search, is absent
+		if r.URL.Path != "/api/listings/search" {
+			http.NotFound(w, r)
+			return
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := offset + limit
+		if end > n {
+			end = n
+		}
+		if offset > end {
+			offset = end
+		}
+		json.NewEncoder(w).Encode(APIResponse{
+			Items:  fixture[offset:end],
+			Total:  n,
+			Limit:  limit,
+			Offset: offset,
+		})
+	}))
+}
+
+func TestSearchListingsPage(t *testing.T) {
+	server := newPagedSearchServer(t, 137)
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	var loaded []APIListing
+	for offset := 0; ; offset += 50 {
+		page, err := client.SearchListingsPage("item", "", SearchFilter{}, 50, offset)
+		if err != nil {
+			t.Fatalf("Failed to search page at offset %d: %v", offset, err)
+		}
+		if page.Total != 137 {
+			t.Errorf("Expected total 137, got %d", page.Total)
+		}
+		if len(page.Items) == 0 {
+			break
+		}
+		loaded = append(loaded, page.Items...)
+	}
+
+	if len(loaded) != 137 {
+		t.Fatalf("Expected 137 listings across pages, got %d", len(loaded))
+	}
+	if loaded[50].ID != 51 || loaded[136].ID != 137 {
+		t.Errorf("Expected pages in order, got IDs %d and %d", loaded[50].ID, loaded[136].ID)
+	}
+}
+
+func TestSearchListingsBySource(t *testing.T) {
+	var gotSource []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("source") {
+			gotSource = append(gotSource, r.URL.Query().Get("source"))
+		} else {
+			gotSource = append(gotSource, "")
+		}
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	for _, source := range []string{"shopgoodwill", "govdeals", "governmentsurplus", "all", ""} {
+		if _, err := client.SearchListingsBySource("rtx", source, SearchFilter{}); err != nil {
+			t.Fatalf("Failed to search %s: %v", source, err)
+		}
+	}
+
+	want := []string{"shopgoodwill", "govdeals", "governmentsurplus", "", ""}
+	if strings.Join(gotSource, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected source params %q, got %q", want, gotSource)
+	}
+}
+
+func TestSearchSendsFilter(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		got = append(got, fmt.Sprintf("%s|%s|%t|%t|%s", query.Get("min_price"), query.Get("max_price"),
+			query.Has("min_price"), query.Has("max_price"), strings.Join(query["condition"], "+")))
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	filters := []SearchFilter{
+		{},
+		{MinPrice: 50},
+		{MaxPrice: 199.99},
+		{MinPrice: 50, MaxPrice: 200},
+		{Conditions: []string{"used", "for-parts"}},
+	}
+	for _, filter := range filters {
+		if _, err := client.SearchListingsPage("rtx", "", filter, 50, 0); err != nil {
+			t.Fatalf("Failed to search %+v: %v", filter, err)
+		}
+	}
+	filter := SearchFilter{MinPrice: 10, MaxPrice: 20, Conditions: []string{"new"}}
+	if _, err := client.SearchAllSources("rtx", []string{"ebay"}, filter); err != nil {
+		t.Fatalf("Failed to search all sources: %v", err)
+	}
+
+	want := []string{
+		"||false|false|",
+		"50||true|false|",
+		"|199.99|false|true|",
+		"50|200|true|true|",
+		"||false|false|used+for-parts",
+		"10|20|true|true|new",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected filter params %q, got %q", want, got)
+	}
+}
+
+func TestSearchSendsProviderAsSource(t *testing.T) {
+	var gotSource string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/listings/search" {
+			requests++
+			gotSource = r.URL.Query().Get("source")
+		}
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	client := NewAPIClient(server.URL)
+	for _, provider := range NewSearchPane().providers {
+		requests, gotSource = 0, ""
+		msg := performSearch(context.Background(), SearchMsg{Query: "rtx", Provider: provider}, client, db, false)().(SearchResultMsg)
+		if msg.Error != nil {
+			t.Fatalf("%s: failed to search: %v", provider, msg.Error)
+		}
+
+		if provider == manualProvider {
+			if requests != 0 || !msg.Local {
+				t.Errorf("Expected manual search to stay local, got %d requests", requests)
+			}
+			continue
+		}
+		want := provider
+		if provider == allProvider {
+			// Without a list of sources to fan out to, the server aggregates
+			want = ""
+		}
+		if requests != 1 || gotSource != want {
+			t.Errorf("Expected 1 request with source '%s', got %d with '%s'", want, requests, gotSource)
+		}
+	}
+}
+
+// perSourceServer answers searches with the listings of the requested
+// source, failing sources missing from listings
+func perSourceServer(t *testing.T, listings map[string][]APIListing) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items, ok := listings[r.URL.Query().Get("source")]
+		if !ok {
+			http.Error(w, "source offline", http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Items: items, Total: len(items)})
+	}))
+}
+
+func TestSearchAllSources(t *testing.T) {
+	server := perSourceServer(t, map[string][]APIListing{
+		"ebay": {
+			{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1", Price: 250},
+			{Title: "RTX 3060 Ti", URL: "https://ebay.com/2", Price: 300},
+		},
+		"govdeals": {
+			{Title: "RTX 3060 lot", Source: "govdeals", URL: "https://govdeals.com/1", Price: 900},
+			{Title: "RTX 3060 (relisted)", Source: "govdeals", URL: "https://ebay.com/1", Price: 240},
+		},
+		"liquidation": {},
+	})
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	listings, err := client.SearchAllSources("rtx 3060", []string{"ebay", "govdeals", "liquidation"}, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Failed to search all sources: %v", err)
+	}
+
+	var got []string
+	for _, l := range listings {
+		got = append(got, l.Source+":"+l.URL)
+	}
+	want := "ebay:https://ebay.com/1,ebay:https://ebay.com/2,govdeals:https://govdeals.com/1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected merged, deduped listings %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestSearchAllSourcesPartialFailure(t *testing.T) {
+	server := perSourceServer(t, map[string][]APIListing{
+		"ebay": {{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1", Price: 250}},
+	})
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	listings, err := client.SearchAllSources("rtx 3060", []string{"govdeals", "ebay", "liquidation"}, SearchFilter{})
+	if len(listings) != 1 || listings[0].Source != "ebay" {
+		t.Errorf("Expected ebay's listing despite the failures, got %+v", listings)
+	}
+	if err == nil {
+		t.Fatal("Expected the failed sources to be reported")
+	}
+	for _, source := range []string{"govdeals", "liquidation"} {
+		if !strings.Contains(err.Error(), source+":") {
+			t.Errorf("Expected %s in the error, got %v", source, err)
+		}
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the API errors to be kept, got %v", err)
+	}
+}
+
+func TestSearchAllSourcesBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.SetRateLimit(0, 0)
+	sources := make([]string, 10)
+	for i := range sources {
+		sources[i] = fmt.Sprintf("source%d", i)
+	}
+	if _, err := client.SearchAllSources("rtx", sources, SearchFilter{}); err != nil {
+		t.Fatalf("Failed to search all sources: %v", err)
+	}
+	if got := peak.Load(); got > searchAllConcurrency {
+		t.Errorf("Expected at most %d requests at once, got %d", searchAllConcurrency, got)
+	}
+}
+
+func TestSearchAllProviderFansOut(t *testing.T) {
+	server := perSourceServer(t, map[string][]APIListing{
+		"ebay":     {{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1", Price: 250}},
+		"govdeals": {{Title: "RTX 3060 lot", URL: "https://govdeals.com/1", Price: 900}},
+	})
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.currentPane = 0
+	m.search.SetProviders([]string{"ebay", "govdeals", "liquidation"})
+	m.search.selectProvider(allProvider)
+	m.search.queryInput.SetValue("rtx 3060")
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(keyMsg("enter"))
+	tm, cmd = tm.Update(runCmd(cmd))
+	tm, _ = tm.Update(runCmd(cmd))
+
+	pane := tm.(model).results
+	if got := resultTitles(pane); got != "RTX 3060,RTX 3060 lot" {
+		t.Errorf("Expected both sources' listings, got %s", got)
+	}
+	if pane.results[1].Source != "govdeals" {
+		t.Errorf("Expected the listing to be annotated with its source, got '%s'", pane.results[1].Source)
+	}
+	if !strings.Contains(pane.notice, "Some sources failed") {
+		t.Errorf("Expected a notice about liquidation failing, got '%s'", pane.notice)
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.SetRateLimit(20, 1)
+
+	for i := 0; i < 5; i++ {
+		if err := client.Ping(); err != nil {
+			t.Fatalf("Failed to ping: %v", err)
+		}
+	}
+
+	if len(arrivals) != 5 {
+		t.Fatalf("Expected 5 requests, got %d", len(arrivals))
+	}
+	// 20 requests per second allows one every 50ms; leave slack for timer jitter
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("Expected requests at least 50ms apart, request %d came after %v", i, gap)
+		}
+	}
+}
+
+func TestRateLimitWaitHonoursCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(APIStatistics{})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	client.SetRateLimit(0.1, 1)
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+
+	// The next token is ten seconds away, so the wait must end on cancel
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := client.PingCtx(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancelled wait to return promptly, took %v", elapsed)
+	}
+}
+
+func TestStatisticsCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode(APIStatistics{TotalListings: int(n)})
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	for i := 0; i < 3; i++ {
+		stats, err := client.GetStatistics()
+		if err != nil {
+			t.Fatalf("Failed to get statistics: %v", err)
+		}
+		if stats.TotalListings != 1 {
+			t.Errorf("Expected cached statistics from the first request, got %d", stats.TotalListings)
+		}
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected 1 request within the TTL, got %d", got)
+	}
+
+	stats, err := client.ForceRefreshStatistics()
+	if err != nil {
+		t.Fatalf("Failed to force refresh statistics: %v", err)
+	}
+	if stats.TotalListings != 2 {
+		t.Errorf("Expected a forced refresh to hit the API, got %d", stats.TotalListings)
+	}
+	if stats, _ := client.GetStatistics(); stats == nil || stats.TotalListings != 2 {
+		t.Errorf("Expected the forced refresh to update the cache, got %+v", stats)
+	}
+
+	client.SetStatsTTL(10 * time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if stats, _ := client.GetStatistics(); stats == nil || stats.TotalListings != 3 {
+		t.Errorf("Expected an expired cache to be refetched, got %+v", stats)
+	}
+}
+
+func TestMalformedResponses(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	client := NewAPIClient(server.URL)
+
+	// list marks the methods returning a collection, which treat an empty
+	// body as nothing found
+	calls := []struct {
+		name     string
+		endpoint string
+		list     bool
+		call     func() (int, error)
+	}{
+		{"GetListings", "/api/listings", true, func() (int, error) { l, err := client.GetListings(10, 0, "", ""); return len(l), err }},
+		{"SearchListings", "/api/listings/search", true, func() (int, error) { l, err := client.SearchListings("rtx"); return len(l), err }},
+		{"SearchListingsPage", "/api/listings/search", true, func() (int, error) {
+			page, err := client.SearchListingsPage("rtx", "", SearchFilter{}, 10, 0)
+			return len(page.Items) + page.Total, err
+		}},
+		{"SearchAllSources", "/api/listings/search", true, func() (int, error) {
+			l, err := client.SearchAllSources("rtx", []string{"ebay", "govdeals"}, SearchFilter{})
+			return len(l), err
+		}},
+		{"GetStatisticsBySource", "/api/statistics/by_source", true, func() (int, error) { s, err := client.GetStatisticsBySource(); return len(s), err }},
+		{"GetSources", "/api/sources", true, func() (int, error) { s, err := client.GetSources(); return len(s), err }},
+		{"GetComps", "/api/comps/search", true, func() (int, error) { c, err := client.GetComps("rtx"); return len(c), err }},
+		{"GetPriceTrend", "/api/comps/trend", true, func() (int, error) { p, err := client.GetPriceTrend("rtx", "", "week"); return len(p), err }},
+		{"GetListingByID", "/api/listings/7", false, func() (int, error) { _, err := client.GetListingByID(7); return 0, err }},
+		{"ForceRefreshStatistics", "/api/statistics", false, func() (int, error) { _, err := client.ForceRefreshStatistics(); return 0, err }},
+		{"CreateListing", "/api/listings", false, func() (int, error) { _, err := client.CreateListing(APIListing{Title: "x"}); return 0, err }},
+		{"Health", "/api/health", false, func() (int, error) { _, err := client.Health(); return 0, err }},
+	}
+
+	for _, tt := range []struct {
+		body  string
+		cause error
+		want  string
+	}{
+		{"", errEmptyBody, "empty response from "},
+		{" \n", errEmptyBody, "empty response from "},
+		{`{"items": [{"id": 1, "title": "RTX`, io.ErrUnexpectedEOF, "truncated response from "},
+		{"<html>Bad Gateway</html>", nil, "invalid response from "},
+	} {
+		body = tt.body
+		for _, c := range calls {
+			n, err := c.call()
+			if c.list && tt.cause == errEmptyBody {
+				if err != nil || n != 0 {
+					t.Errorf("%s(%q): expected an empty result, got %d items and %v", c.name, tt.body, n, err)
+				}
+				continue
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Errorf("%s(%q): expected a DecodeError, got %v", c.name, tt.body, err)
+				continue
+			}
+			if decodeErr.Endpoint != c.endpoint {
+				t.Errorf("%s(%q): expected endpoint '%s', got '%s'", c.name, tt.body, c.endpoint, decodeErr.Endpoint)
+			}
+			if tt.cause != nil && !errors.Is(err, tt.cause) {
+				t.Errorf("%s(%q): expected %v, got %v", c.name, tt.body, tt.cause, err)
+			}
+			if !strings.Contains(err.Error(), tt.want+c.endpoint) {
+				t.Errorf("%s(%q): expected '%s%s' in '%v'", c.name, tt.body, tt.want, c.endpoint, err)
+			}
+		}
+	}
+}
+
+// newConnCountingServer serves empty search results slowly enough for a
+// fan-out's requests to overlap, counting the connections clients open
+func newConnCountingServer(conns *atomic.Int64) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	return server
+}
+
+// fanOutSources is more sources than SearchAllSources searches at once
+var fanOutSources = []string{"ebay", "govdeals", "shopgoodwill", "craigslist", "facebook", "mercari", "offerup", "poshmark"}
+
+func TestConnectionPoolReusesConnections(t *testing.T) {
+	var conns atomic.Int64
+	server := newConnCountingServer(&conns)
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	for i := 0; i < 5; i++ {
+		if _, err := client.SearchAllSources("rtx", fanOutSources, SearchFilter{}); err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+	}
+	if n := conns.Load(); n > searchAllConcurrency {
+		t.Errorf("Expected at most %d connections across 5 searches, got %d", searchAllConcurrency, n)
+	}
+
+	// A fresh pool redials once, then reuses its connections too
+	conns.Store(0)
+	client.SetConnectionPool(8, time.Minute)
+	for i := 0; i < 3; i++ {
+		client.SearchAllSources("rtx", fanOutSources, SearchFilter{})
+	}
+	if n := conns.Load(); n > searchAllConcurrency {
+		t.Errorf("Expected at most %d connections after resizing the pool, got %d", searchAllConcurrency, n)
+	}
+}
+
+// BenchmarkSearchAllSourcesConnections compares the connections a repeated
+// multi-source search opens with the default transport's two idle
+// connections per host and with the client's pool
+func BenchmarkSearchAllSourcesConnections(b *testing.B) {
+	for _, bench := range []struct {
+		name           string
+		maxIdlePerHost int
+	}{
+		{"default", http.DefaultMaxIdleConnsPerHost},
+		{"tuned", defaultMaxIdleConnsPerHost},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var conns atomic.Int64
+			server := newConnCountingServer(&conns)
+			defer server.Close()
+
+			client := NewAPIClient(server.URL)
+			client.SetConnectionPool(bench.maxIdlePerHost, 0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				client.SearchAllSources("rtx", fanOutSources, SearchFilter{})
+			}
+			b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AppConfig is the startup configuration, merged from defaults, the config
+// file, environment variables and command line flags, in rising precedence
+type AppConfig struct {
+	APIURL             string
+	Provider           string
+	Threshold          float64
+	Theme              string
+	AutoRefreshSeconds int
+	// Keymap holds the keys of remapped actions, by action name
+	Keymap map[string][]string
+
+	// explicit holds the settings given by a file, variable or flag rather
+	// than defaulted, so they can take precedence over the last session
+	explicit map[string]bool
+}
+
+// Settings of the config file, which are also the names used in the
+// environment (upper-cased with an ARBFINDER_ prefix) and, with dashes, as
+// flags
+const (
+	settingAPIURL      = "api_url"
+	settingProvider    = "provider"
+	settingThreshold   = "threshold"
+	settingTheme       = "theme"
+	settingAutoRefresh = "auto_refresh_seconds"
+)
+
+// appSettings lists every setting with its description for --help
+var appSettings = []struct {
+	name, usage string
+}{
+	{settingAPIURL, "ArbFinder API URL"},
+	{settingProvider, "provider selected at startup"},
+	{settingThreshold, "minimum discount threshold (%)"},
+	{settingTheme, "colour theme: dark, high-contrast or light"},
+	{settingAutoRefresh, "auto-refresh interval in seconds"},
+}
+
+// configFileEnv names a config file to read instead of the default one
+const configFileEnv = "ARBFINDER_CONFIG"
+
+// configFileNames are looked for in the config directory, in order
+var configFileNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// defaultAppConfig is the configuration used when nothing is set
+func defaultAppConfig() AppConfig {
+	return AppConfig{
+		APIURL:             defaultAPIURL,
+		Provider:           defaultProviders[0],
+		Threshold:          defaultThreshold,
+		Theme:              themes[0].Name,
+		AutoRefreshSeconds: defaultAutoRefreshSeconds,
+		explicit:           make(map[string]bool),
+	}
+}
+
+// configFilePath returns the config file to read: the file named by
+// ARBFINDER_CONFIG, or the first of config.yaml, config.yml and config.json
+// found in $XDG_CONFIG_HOME/arbfinder (~/.config/arbfinder by default).
+// ok is false when there is none.
+func configFilePath() (path string, ok bool, err error) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path, true, nil
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, ".config")
+	}
+
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, "arbfinder", name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// LoadConfigFile reads the config file over the defaults. Without a config
+// file it returns the defaults.
+func LoadConfigFile() (AppConfig, error) {
+	file, err := readConfigFile()
+	if err != nil {
+		return AppConfig{}, err
+	}
+	return mergeConfig(file)
+}
+
+// readConfigFile returns the settings in the config file, if there is one
+func readConfigFile() (map[string]string, error) {
+	path, ok, err := configFilePath()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	parse := parseYAMLSettings
+	if filepath.Ext(path) == ".json" {
+		parse = parseJSONSettings
+	}
+	settings, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// parseYAMLSettings reads the subset of YAML a config file needs: one
+// "key: value" pair per line, optionally quoted, with # comments
+func parseYAMLSettings(r io.Reader) (map[string]string, error) {
+	settings := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	inKeymap := false
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		nested := line[0] == ' ' || line[0] == '\t'
+		if nested && !inKeymap {
+			return nil, fmt.Errorf("line %d: nested values are only supported under %s", n, keymapSection)
+		}
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", n)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !nested {
+			inKeymap = name == keymapSection && value == ""
+			if inKeymap {
+				continue
+			}
+		}
+		value, err := yamlScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if nested {
+			name = keymapPrefix + name
+		}
+		settings[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// yamlScalar unquotes a YAML scalar, dropping any trailing comment
+func yamlScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := strings.LastIndex(raw, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		return strconv.Unquote(raw[:end+1])
+	case strings.HasPrefix(raw, "'"):
+		end := strings.LastIndex(raw, "'")
+		if end == 0 {
+			return "", fmt.Errorf("unterminated string %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:end], "''", "'"), nil
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	return raw, nil
+}
+
+// parseJSONSettings reads a config file holding a JSON object of strings
+// and numbers, plus a keymap object of strings
+func parseJSONSettings(r io.Reader) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case string:
+			settings[name] = v
+		case float64:
+			settings[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case map[string]interface{}:
+			if name != keymapSection {
+				return nil, fmt.Errorf("%s must be a string or number", name)
+			}
+			for action, keys := range v {
+				s, ok := keys.(string)
+				if !ok {
+					return nil, fmt.Errorf("%s%s must be a string", keymapPrefix, action)
+				}
+				settings[keymapPrefix+action] = s
+			}
+		default:
+			return nil, fmt.Errorf("%s must be a string or number", name)
+		}
+	}
+	return settings, nil
+}
+
+// envSettings returns the settings given as ARBFINDER_* environment
+// variables
+func envSettings(getenv func(string) string) map[string]string {
+	settings := make(map[string]string)
+	for _, s := range appSettings {
+		if value := getenv("ARBFINDER_" + strings.ToUpper(s.name)); value != "" {
+			settings[s.name] = value
+		}
+	}
+	return settings
+}
+
+// settingFlag is the command line flag for a setting
+func settingFlag(name string) string {
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// mergeConfig applies layers of settings over the defaults, later layers
+// taking precedence, and validates the result
+func mergeConfig(layers ...map[string]string) (AppConfig, error) {
+	merged := make(map[string]string)
+	for _, layer := range layers {
+		for name, value := range layer {
+			merged[name] = value
+		}
+	}
+
+	cfg := defaultAppConfig()
+	var errs []error
+	keymap := make(map[string]string)
+	for name, value := range merged {
+		if action, ok := strings.CutPrefix(name, keymapPrefix); ok {
+			keymap[action] = value
+			continue
+		}
+		if err := cfg.set(name, value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cfg.explicit[name] = true
+	}
+	keymapKeys, err := parseKeymap(keymap)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	cfg.Keymap = keymapKeys
+	if err := errors.Join(errs...); err != nil {
+		return AppConfig{}, err
+	}
+	return cfg, nil
+}
+
+// set parses and stores one setting
+func (c *AppConfig) set(name, value string) error {
+	switch name {
+	case settingAPIURL:
+		apiURL, err := normalizeAPIURL(value)
+		if err != nil {
+			return err
+		}
+		if apiURL != "" {
+			c.APIURL = apiURL
+		}
+	case settingProvider:
+		c.Provider = strings.TrimSpace(value)
+	case settingThreshold:
+		threshold, err := parseThreshold(value)
+		if err != nil {
+			return err
+		}
+		c.Threshold = threshold
+	case settingTheme:
+		if _, ok := themeIndex(value); !ok {
+			return fmt.Errorf("unknown theme %q", value)
+		}
+		c.Theme = value
+	case settingAutoRefresh:
+		seconds, err := parseRefreshSeconds(value)
+		if err != nil {
+			return err
+		}
+		c.AutoRefreshSeconds = seconds
+	default:
+		return fmt.Errorf("unknown setting %q", name)
+	}
+	return nil
+}
+
+// cliOptions are the parsed command line flags
+type cliOptions struct {
+	verbose bool
+	// query, when set, is searched without starting the TUI, printing
+	// up to limit listings as CSV, or JSON with asJSON
+	query  string
+	asJSON bool
+	limit  int
+	// report writes an opportunities report for query to the home
+	// directory instead of printing the listings
+	report bool
+	// settings are the config settings given as flags
+	settings map[string]string
+}
+
+// parseFlags parses the command line arguments, without the program name
+func parseFlags(args []string, output io.Writer) (cliOptions, error) {
+	fs := flag.NewFlagSet("arbfinder-tui", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	opts := cliOptions{settings: make(map[string]string)}
+	fs.BoolVar(&opts.verbose, "verbose", false, "log every API request and user action to "+logFileName)
+	fs.StringVar(&opts.query, "query", "", "search for `text`, print the listings and exit without starting the TUI")
+	fs.BoolVar(&opts.asJSON, "json", false, "print --query results as JSON instead of CSV")
+	fs.IntVar(&opts.limit, "limit", searchPageSize, "maximum number of --query results")
+	fs.BoolVar(&opts.report, "report", false, "write --query results with comps and margins to ~/"+reportName+"_<time>.md and .csv")
+	for _, s := range appSettings {
+		fs.String(settingFlag(s.name), "", s.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return cliOptions{}, err
+	}
+	if opts.limit < 1 {
+		return cliOptions{}, fmt.Errorf("--limit must be at least 1, got %d", opts.limit)
+	}
+
+	// Only flags actually given override the file and environment
+	fs.Visit(func(f *flag.Flag) {
+		for _, s := range appSettings {
+			if f.Name == settingFlag(s.name) {
+				opts.settings[s.name] = f.Value.String()
+			}
+		}
+	})
+	return opts, nil
+}
+
+// loadAppConfig merges the defaults, the config file, the environment and
+// the flags into the startup configuration
+func loadAppConfig(opts cliOptions, getenv func(string) string) (AppConfig, error) {
+	file, err := readConfigFile()
+	if err != nil {
+		return AppConfig{}, err
+	}
+	return mergeConfig(file, envSettings(getenv), opts.settings)
+}
+
+// applyAppConfig sets up the session from the startup configuration. The
+// provider and threshold restored from the last session are kept unless
+// the configuration sets them.
+func (m *model) applyAppConfig(cfg AppConfig) {
+	m.config.apiURL.SetValue(cfg.APIURL)
+	m.config.refresh.SetValue(strconv.Itoa(cfg.AutoRefreshSeconds))
+	if idx, ok := themeIndex(cfg.Theme); ok {
+		m.config.selectTheme(idx)
+	}
+	m.config.applyConfig()
+	setKeyOverrides(cfg.Keymap)
+
+	if cfg.explicit[settingProvider] {
+		m.search.selectProvider(cfg.Provider)
+	}
+	if cfg.explicit[settingThreshold] {
+		m.search.thresholdInput.SetValue(strconv.FormatFloat(cfg.Threshold, 'f', -1, 64))
+	}
+}
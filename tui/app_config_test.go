@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLSettings(t *testing.T) {
+	settings, err := parseYAMLSettings(strings.NewReader(`---
+# ArbFinder TUI
+api_url: "https://arb.example.com" # production
+provider: govdeals
+threshold: 35.5
+theme: 'high-contrast'
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+
+	want := map[string]string{
+		"api_url":   "https://arb.example.com",
+		"provider":  "govdeals",
+		"threshold": "35.5",
+		"theme":     "high-contrast",
+	}
+	if len(settings) != len(want) {
+		t.Errorf("Expected %d settings, got %v", len(want), settings)
+	}
+	for name, value := range want {
+		if settings[name] != value {
+			t.Errorf("Expected %s '%s', got '%s'", name, value, settings[name])
+		}
+	}
+
+	if _, err := parseYAMLSettings(strings.NewReader("api:\n  url: x\n")); err == nil {
+		t.Error("Expected nested values to be rejected")
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"api_url": "file.example.com", "provider": "govdeals", "threshold": 30, "auto_refresh_seconds": 120}`), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv(configFileEnv, path)
+
+	env := map[string]string{
+		"ARBFINDER_API_URL":   "http://env.example.com",
+		"ARBFINDER_THRESHOLD": "40",
+	}
+	opts, err := parseFlags([]string{"--threshold", "50", "--verbose"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	cfg, err := loadAppConfig(opts, func(name string) string { return env[name] })
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// flags > env > file > defaults
+	if cfg.Threshold != 50 {
+		t.Errorf("Expected the flag threshold 50, got %g", cfg.Threshold)
+	}
+	if cfg.APIURL != "http://env.example.com" {
+		t.Errorf("Expected the environment API URL, got '%s'", cfg.APIURL)
+	}
+	if cfg.Provider != "govdeals" || cfg.AutoRefreshSeconds != 120 {
+		t.Errorf("Expected the file's provider and interval, got %+v", cfg)
+	}
+	if cfg.Theme != "dark" || cfg.explicit[settingTheme] {
+		t.Errorf("Expected the default theme, got '%s'", cfg.Theme)
+	}
+	if !opts.verbose {
+		t.Error("Expected --verbose to be set")
+	}
+
+	file, err := LoadConfigFile()
+	if err != nil {
+		t.Fatalf("Failed to load config file: %v", err)
+	}
+	if file.APIURL != "http://file.example.com" || file.Threshold != 30 {
+		t.Errorf("Expected the file over the defaults, got %+v", file)
+	}
+}
+
+func TestConfigDefaultsWithoutFile(t *testing.T) {
+	t.Setenv(configFileEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := LoadConfigFile()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.APIURL != defaultAPIURL || cfg.Threshold != defaultThreshold || cfg.AutoRefreshSeconds != defaultAutoRefreshSeconds {
+		t.Errorf("Expected the defaults, got %+v", cfg)
+	}
+}
+
+func TestInvalidConfigSettings(t *testing.T) {
+	_, err := mergeConfig(
+		map[string]string{"theme": "neon", "thresold": "10"},
+		map[string]string{"auto_refresh_seconds": "1"},
+	)
+	if err == nil {
+		t.Fatal("Expected invalid settings to be rejected")
+	}
+	for _, want := range []string{`unknown theme "neon"`, `unknown setting "thresold"`, "at least 5 seconds"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestApplyAppConfigKeepsSession(t *testing.T) {
+	m := newTestModel("")
+	m.search.selectProvider("govdeals")
+	m.search.thresholdInput.SetValue("25")
+
+	cfg, err := mergeConfig(map[string]string{"api_url": "arb.example.com:9000", "theme": "light"})
+	if err != nil {
+		t.Fatalf("Failed to merge config: %v", err)
+	}
+	m.applyAppConfig(cfg)
+	defer m.config.selectTheme(0)
+
+	if m.apiClient.BaseURL() != "http://arb.example.com:9000" || theme.Name != "light" {
+		t.Errorf("Expected the configured API URL and theme, got %s and %s", m.apiClient.BaseURL(), theme.Name)
+	}
+	if m.search.providers[m.search.providerSelect] != "govdeals" || m.search.thresholdInput.Value() != "25" {
+		t.Error("Expected the session's provider and threshold to stay when not configured")
+	}
+
+	cfg, _ = mergeConfig(map[string]string{"provider": "liquidation", "threshold": "12.5"})
+	m.applyAppConfig(cfg)
+	if m.search.providers[m.search.providerSelect] != "liquidation" || m.search.thresholdInput.Value() != "12.5" {
+		t.Errorf("Expected the configured provider and threshold, got %s and %s",
+			m.search.providers[m.search.providerSelect], m.search.thresholdInput.Value())
+	}
+}
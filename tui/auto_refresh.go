@@ -0,0 +1,86 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// autoRefreshMsg is delivered by the auto-refresh tick. gen identifies the
+// run of ticks it belongs to, so ticks scheduled before auto-refresh was
+// turned off are dropped.
+type autoRefreshMsg struct {
+	gen int
+}
+
+// scheduleAutoRefresh fires the next auto-refresh tick after every
+func scheduleAutoRefresh(every time.Duration, gen int) tea.Cmd {
+	return tea.Tick(every, func(time.Time) tea.Msg {
+		return autoRefreshMsg{gen: gen}
+	})
+}
+
+// toggleAutoRefresh turns auto-refresh on or off. Turning it off invalidates
+// the pending tick, which stops the ticks.
+func (m *model) toggleAutoRefresh() tea.Cmd {
+	m.autoRefresh = !m.autoRefresh
+	m.autoRefreshGen++
+	logger.Debug("toggle auto-refresh", "on", m.autoRefresh)
+	if !m.autoRefresh {
+		return nil
+	}
+	return scheduleAutoRefresh(m.config.refreshEvery, m.autoRefreshGen)
+}
+
+// handleAutoRefresh refreshes the active pane and schedules the next tick
+func (m *model) handleAutoRefresh(msg autoRefreshMsg) tea.Cmd {
+	if !m.autoRefresh || msg.gen != m.autoRefreshGen {
+		return nil
+	}
+	return tea.Batch(m.refreshActivePane(), scheduleAutoRefresh(m.config.refreshEvery, m.autoRefreshGen))
+}
+
+// refreshActivePane re-fetches the data shown in the current pane, if it
+// shows any. Panes already loading, or with an overlay open, are left alone.
+func (m *model) refreshActivePane() tea.Cmd {
+	switch m.currentPane {
+	case 1:
+		if m.results.loading || m.results.capturingInput() {
+			return nil
+		}
+		m.results.loading = true
+		m.results.lastError = ""
+		return tea.Batch(m.results.refresh(), m.results.spinner.Tick)
+	case 2:
+		return m.refreshStats()
+	case 5:
+		m.watchlist.Load()
+	case 6:
+		m.favorites.Load()
+	}
+	return nil
+}
+
+// refreshStats re-fetches the statistics, bypassing the cache, unless they
+// are already loading
+func (m *model) refreshStats() tea.Cmd {
+	if m.stats.loading {
+		return nil
+	}
+	m.stats.loading = true
+	db, client, window := m.db, m.apiClient, m.stats.window()
+	return tea.Batch(func() tea.Msg {
+		return collectStats(db, client, true, window)
+	}, m.stats.spinner.Tick)
+}
+
+// refreshAll refreshes the current pane, the statistics and the status
+// bar's connectivity check at once
+func (m *model) refreshAll() tea.Cmd {
+	logger.Debug("refresh all", "pane", paneNames[m.currentPane])
+	cmds := []tea.Cmd{m.refreshActivePane(), checkConnectivity(m.apiClient)}
+	if m.currentPane != 2 {
+		cmds = append(cmds, m.refreshStats())
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAutoRefreshTickRefreshesActivePane(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{{ID: 1, Title: "RTX 3060", Price: 299.99}}, Total: 1})
+	}))
+	defer server.Close()
+
+	var m tea.Model = newTestModel(server.URL)
+	m, cmd := m.Update(keyMsg("R"))
+	if cmd == nil {
+		t.Fatal("Expected 'R' to schedule an auto-refresh tick")
+	}
+	if !m.(model).autoRefresh {
+		t.Fatal("Expected auto-refresh to be on")
+	}
+	if view := m.View(); !strings.Contains(view, "auto-refresh every 60s") {
+		t.Errorf("Expected the status bar to show the interval, got:\n%s", view)
+	}
+
+	// A tick of the current run refreshes the results pane and schedules the next
+	m, cmd = m.Update(autoRefreshMsg{gen: m.(model).autoRefreshGen})
+	if cmd == nil {
+		t.Fatal("Expected the tick to refresh and reschedule")
+	}
+	if !m.(model).results.loading {
+		t.Error("Expected the results pane to be refreshing")
+	}
+	refresh := m.(model).results.refresh()
+	m, _ = m.Update(refresh())
+	if len(m.(model).results.results) != 1 {
+		t.Errorf("Expected refreshed results, got %d", len(m.(model).results.results))
+	}
+
+	// Turning auto-refresh off drops the tick already in flight
+	gen := m.(model).autoRefreshGen
+	m, cmd = m.Update(keyMsg("R"))
+	if cmd != nil {
+		t.Error("Expected no tick once auto-refresh is off")
+	}
+	if view := m.View(); !strings.Contains(view, "auto-refresh off") {
+		t.Errorf("Expected the status bar to show auto-refresh off, got:\n%s", view)
+	}
+	if _, cmd := m.Update(autoRefreshMsg{gen: gen}); cmd != nil {
+		t.Error("Expected a stale tick to stop the ticks")
+	}
+}
+
+func TestAutoRefreshSkipsPanesWithoutData(t *testing.T) {
+	m := newTestModel("")
+	m.currentPane = 0
+	m.autoRefresh = true
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(autoRefreshMsg{gen: m.autoRefreshGen})
+	if cmd == nil {
+		t.Fatal("Expected the next tick to be scheduled")
+	}
+	if tm.(model).results.loading {
+		t.Error("Expected the results pane to be left alone while another pane is active")
+	}
+}
+
+func TestParseRefreshSeconds(t *testing.T) {
+	if got, err := parseRefreshSeconds(""); err != nil || got != defaultAutoRefreshSeconds {
+		t.Errorf("Expected the default for an empty field, got %d, %v", got, err)
+	}
+	if got, err := parseRefreshSeconds(" 90 "); err != nil || got != 90 {
+		t.Errorf("Expected 90, got %d, %v", got, err)
+	}
+	for _, raw := range []string{"abc", "1.5", "2"} {
+		if _, err := parseRefreshSeconds(raw); err == nil {
+			t.Errorf("Expected an error for %q", raw)
+		}
+	}
+}
+
+// batchMsgTypes runs cmd and every command batched inside it, returning the
+// types of the messages they produce, spinner ticks aside
+func batchMsgTypes(cmd tea.Cmd) []string {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var types []string
+		for _, c := range msg {
+			types = append(types, batchMsgTypes(c)...)
+		}
+		return types
+	case nil:
+		return nil
+	default:
+		if isSpinnerTick(msg) {
+			return nil
+		}
+		return []string{fmt.Sprintf("%T", msg)}
+	}
+}
+
+func TestRefreshAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{{ID: 1, Title: "RTX 3060", Price: 299.99}}, Total: 1})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		pane int
+		key  string
+		want []string
+	}{
+		{"results", 1, "f5", []string{"main.SearchResultMsg", "main.ConnectivityMsg", "main.StatsLoadedMsg"}},
+		{"stats", 2, "ctrl+r", []string{"main.StatsLoadedMsg", "main.ConnectivityMsg"}},
+		{"search", 0, "f5", []string{"main.ConnectivityMsg", "main.StatsLoadedMsg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(server.URL)
+			m.currentPane = tt.pane
+
+			var tm tea.Model = m
+			tm, cmd := tm.Update(keyMsg(tt.key))
+			if !tm.(model).stats.loading {
+				t.Error("Expected the statistics to be reloading")
+			}
+			got := strings.Join(batchMsgTypes(cmd), ",")
+			if want := strings.Join(tt.want, ","); got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+		})
+	}
+}
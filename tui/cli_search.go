@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// searchOnce runs the --query search against client and prints the listings
+// to w, as CSV or, with --json, as JSON, for piping into other tools, or with
+// --report writes an opportunities report instead. A provider set in the
+// configuration narrows the search to that source; otherwise every source is
+// searched.
+func searchOnce(client *APIClient, opts cliOptions, cfg AppConfig, w io.Writer) error {
+	source := ""
+	if cfg.explicit[settingProvider] {
+		if cfg.Provider == manualProvider {
+			return fmt.Errorf("manual listings are only kept in the TUI's local database")
+		}
+		source = cfg.Provider
+	}
+
+	terms, excludes := parseQuery(opts.query)
+	page, err := client.SearchListingsPage(strings.Join(terms, " "), source, SearchFilter{}, opts.limit, 0)
+	if err != nil {
+		return fmt.Errorf("search failed: %s", describeError(err))
+	}
+
+	listings := excludeListings(page.Items, excludes)
+	if len(listings) > opts.limit {
+		listings = listings[:opts.limit]
+	}
+	if opts.report {
+		return reportOnce(client, opts.query, listings, w)
+	}
+	if opts.asJSON {
+		return writeListingsJSON(w, listings)
+	}
+	return writeListingsCSV(w, listings)
+}
+
+// reportOnce fetches comps for query, writes the opportunities report for
+// listings to the home directory and prints where it went to w
+func reportOnce(client *APIClient, query string, listings []APIListing, w io.Writer) error {
+	comps, err := client.GetComps(serverQuery(query))
+	if err != nil {
+		return fmt.Errorf("comps failed: %s", describeError(err))
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	report := newReport(query, listings, comps, time.Now())
+	mdPath, csvPath, err := report.writeFiles(homeDir)
+	if err != nil {
+		return err
+	}
+
+	s := report.summary()
+	fmt.Fprintf(w, "%d listings, %d with comps, %d profitable\n", s.Listings, s.Matched, s.Profitable)
+	fmt.Fprintf(w, "Wrote %s\nWrote %s\n", mdPath, csvPath)
+	return nil
+}
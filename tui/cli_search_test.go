@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSearchOncePrintsResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings/search" {
+			t.Errorf("Expected path '/api/listings/search', got '%s'", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("q") != "rtx 3060" {
+			t.Errorf("Expected query 'rtx 3060', got '%s'", query.Get("q"))
+		}
+		if query.Get("limit") != "2" {
+			t.Errorf("Expected limit '2', got '%s'", query.Get("limit"))
+		}
+		if query.Has("source") {
+			t.Errorf("Expected no source, got '%s'", query.Get("source"))
+		}
+		json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{
+			{ID: 1, Source: "ebay", Title: "RTX 3060, 12GB", Price: 250, Currency: "USD", URL: "https://ebay.com/1"},
+			{ID: 2, Source: "govdeals", Title: "RTX 3060 Ti", Price: 300, Currency: "USD", URL: "https://govdeals.com/2"},
+		}, Total: 2})
+	}))
+	defer server.Close()
+
+	args := []string{"--api-url", server.URL, "--query", "rtx 3060", "--limit", "2"}
+	opts, err := parseFlags(args, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	cfg, err := loadAppConfig(opts, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := searchOnce(NewAPIClient(cfg.APIURL), opts, cfg, &out); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 CSV rows, got %q", out.String())
+	}
+	if !strings.Contains(lines[1], `"RTX 3060, 12GB"`) {
+		t.Errorf("Expected the quoted title in the first row, got '%s'", lines[1])
+	}
+
+	opts, err = parseFlags(append(args, "--json"), io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	out.Reset()
+	if err := searchOnce(NewAPIClient(cfg.APIURL), opts, cfg, &out); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	var listings []APIListing
+	if err := json.Unmarshal(out.Bytes(), &listings); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+	if len(listings) != 2 || listings[1].Source != "govdeals" {
+		t.Errorf("Expected both listings, got %+v", listings)
+	}
+}
+
+func TestSearchOnceFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	opts := cliOptions{query: "rtx 3060", limit: 10}
+	var out bytes.Buffer
+	if err := searchOnce(NewAPIClient(server.URL), opts, AppConfig{}, &out); err == nil {
+		t.Error("Expected a failed search to be reported")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", out.String())
+	}
+
+	if _, err := parseFlags([]string{"--limit", "0"}, io.Discard); err == nil {
+		t.Error("Expected a zero limit to be rejected")
+	}
+}
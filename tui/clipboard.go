@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// clipboardWriter copies text to the clipboard
+type clipboardWriter interface {
+	WriteAll(text string) error
+}
+
+// systemClipboard uses the OS clipboard (pbcopy, clip, xclip/xsel or
+// wl-copy); tests replace it with a recorder
+var systemClipboard clipboardWriter = osClipboard{}
+
+type osClipboard struct{}
+
+func (osClipboard) WriteAll(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// copiedNoticeDuration is how long the "Copied!" notice stays up
+const copiedNoticeDuration = 3 * time.Second
+
+// noticeExpiredMsg clears the results notice if it is still the one shown
+type noticeExpiredMsg struct {
+	notice string
+}
+
+// expireNotice clears notice after d unless it has been replaced
+func expireNotice(notice string, d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return noticeExpiredMsg{notice: notice}
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type CompsPane struct {
+	queryInput  textinput.Model
+	comps       []APIComp
+	selectedIdx int
+	offset      int
+	pageSize    int
+	loading     bool
+	spinner     spinner.Model
+	lastQuery   string
+	lastError   string
+	apiClient   *APIClient
+}
+
+func NewCompsPane(apiClient *APIClient) *CompsPane {
+	queryInput := textinput.New()
+	queryInput.Placeholder = "Filter comps by title (empty for recent)"
+	queryInput.Focus()
+	queryInput.Width = 50
+
+	return &CompsPane{
+		queryInput: queryInput,
+		comps:      []APIComp{},
+		pageSize:   10,
+		spinner:    newSpinner(),
+		apiClient:  apiClient,
+	}
+}
+
+func (p *CompsPane) Update(msg tea.Msg) (CompsPane, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Comps.Fetch):
+			p.lastQuery = strings.TrimSpace(p.queryInput.Value())
+			p.loading = true
+			p.lastError = ""
+			return *p, tea.Batch(p.fetch(p.lastQuery), p.spinner.Tick)
+
+		case key.Matches(msg, keys.Comps.HalfPageUp):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx-p.pageSize/2, p.offset, len(p.comps), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Comps.HalfPageDn):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx+p.pageSize/2, p.offset, len(p.comps), p.pageSize)
+			return *p, nil
+
+		case navMatches(msg, true, keys.Comps.Up):
+			if p.selectedIdx > 0 {
+				p.selectedIdx--
+				if p.selectedIdx < p.offset {
+					p.offset = p.selectedIdx
+				}
+			}
+			return *p, nil
+
+		case navMatches(msg, true, keys.Comps.Down):
+			if p.selectedIdx < len(p.comps)-1 {
+				p.selectedIdx++
+				if p.selectedIdx >= p.offset+p.pageSize {
+					p.offset = p.selectedIdx - p.pageSize + 1
+				}
+			}
+			return *p, nil
+		}
+	}
+
+	p.queryInput, cmd = p.queryInput.Update(msg)
+	return *p, cmd
+}
+
+// fetch loads comps matching query off the UI goroutine
+func (p *CompsPane) fetch(query string) tea.Cmd {
+	client := p.apiClient
+	return func() tea.Msg {
+		comps, err := client.GetComps(query)
+		return CompsLoadedMsg{
+			Comps: comps,
+			Error: err,
+		}
+	}
+}
+
+func (p *CompsPane) View(width, height int) string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Text).
+		Background(theme.HeaderBg).
+		Padding(0, 1)
+
+	itemStyle := lipgloss.NewStyle().
+		Padding(0, 1)
+
+	selectedItemStyle := itemStyle.Copy().
+		Background(theme.SelectedBg).
+		Bold(true)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	// Title
+	b.WriteString(titleStyle.Render(fmt.Sprintf("💲 Comparable Prices (%d comps)", len(p.comps))))
+	b.WriteString("\n\n")
+
+	// Query input
+	b.WriteString(labelStyle.Render("Comp Query:"))
+	b.WriteString("\n")
+	b.WriteString(p.queryInput.View())
+	b.WriteString("\n\n")
+
+	if p.loading {
+		statusStyle := lipgloss.NewStyle().
+			Foreground(theme.Success).
+			Bold(true)
+		b.WriteString(statusStyle.Render(p.spinner.View() + " Loading..."))
+		b.WriteString("\n")
+	} else if len(p.comps) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(theme.Subtle).
+			Italic(true)
+		b.WriteString(emptyStyle.Render("No comps loaded. Press Enter to fetch comparable prices."))
+		b.WriteString("\n")
+	} else {
+		// Header
+		header := fmt.Sprintf("%-40s %10s %10s %7s", "Title", "Avg", "Median", "Count")
+		b.WriteString(headerStyle.Render(header))
+		b.WriteString("\n")
+
+		end := p.offset + p.pageSize
+		if end > len(p.comps) {
+			end = len(p.comps)
+		}
+
+		for i := p.offset; i < end; i++ {
+			comp := p.comps[i]
+			title := truncate(comp.KeyTitle, 40)
+
+			line := fmt.Sprintf("%s $%9.2f $%9.2f %7d",
+				padRight(title, 40),
+				comp.AvgPrice,
+				comp.MedianPrice,
+				comp.Count,
+			)
+
+			if i == p.selectedIdx {
+				b.WriteString(selectedItemStyle.Render("▸ " + line))
+			} else {
+				b.WriteString(itemStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+		}
+
+		// Pagination info
+		b.WriteString("\n")
+		pageInfo := fmt.Sprintf("Showing %d-%d of %d", p.offset+1, end, len(p.comps))
+		b.WriteString(infoStyle.Render(pageInfo))
+	}
+
+	// Instructions
+	b.WriteString("\n\n")
+	b.WriteString(infoStyle.Render("Enter: Fetch comps • ↑/↓: Navigate • Tab: Switch pane"))
+
+	// Error
+	if p.lastError != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(theme.Error).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Error: %s", p.lastError)))
+	}
+
+	return b.String()
+}
+
+func (p *CompsPane) SetComps(comps []APIComp) {
+	p.comps = comps
+	p.selectedIdx = 0
+	p.offset = 0
+	p.loading = false
+}
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,38 +23,247 @@ type ConfigPane struct {
 	selectedIdx   int
 	newConfigName textinput.Model
 	apiURL        textinput.Model
+	authToken     textinput.Model
+	retention     textinput.Model
+	refresh       textinput.Model
+	timeout       textinput.Model
+	pageSize      textinput.Model
+	themeIdx      int  // index into themes of the selected theme
+	vimKeys       bool // whether h/j/k/l and g/G navigate
+	notify        bool // whether price alerts raise desktop notifications
 	focusIndex    int
 	saving        bool
 	loading       bool
+	spinner       spinner.Model
 	lastError     string
 	lastSuccess   string
 	db            *Database
+	apiClient     *APIClient
+
+	// testing is the URL a connection test is running against, if any
+	testing string
+	// tested is the last URL a connection test reached and testResult
+	// describes how the last test went
+	tested     string
+	testResult string
+	testFailed bool
+
+	// refreshEvery is the auto-refresh interval last applied
+	refreshEvery time.Duration
+	// maxRows is the most result rows shown at once last applied, zero to
+	// fit the window
+	maxRows int
+	// confirm is non-nil while a destructive action awaits confirmation
+	confirm *ConfirmDialog
 }
 
 func NewConfigPane() *ConfigPane {
 	nameInput := textinput.New()
 	nameInput.Placeholder = "config_name"
+	nameInput.Focus()
 	nameInput.Width = 30
 
 	apiInput := textinput.New()
 	apiInput.Placeholder = "http://localhost:8080"
 	apiInput.Width = 40
 
+	tokenInput := textinput.New()
+	tokenInput.Placeholder = "optional bearer token / API key"
+	tokenInput.Width = 40
+	tokenInput.EchoMode = textinput.EchoPassword
+
+	retentionInput := textinput.New()
+	retentionInput.Placeholder = strconv.Itoa(defaultCacheRetentionDays)
+	retentionInput.Width = 10
+
+	refreshInput := textinput.New()
+	refreshInput.Placeholder = strconv.Itoa(defaultAutoRefreshSeconds)
+	refreshInput.Width = 10
+
+	timeoutInput := textinput.New()
+	timeoutInput.Placeholder = strconv.Itoa(int(defaultTimeout / time.Second))
+	timeoutInput.Width = 10
+
+	pageSizeInput := textinput.New()
+	pageSizeInput.Placeholder = "fit to window"
+	pageSizeInput.Width = 14
+
 	return &ConfigPane{
 		configs:       []SavedConfig{},
 		newConfigName: nameInput,
 		apiURL:        apiInput,
+		authToken:     tokenInput,
+		retention:     retentionInput,
+		refresh:       refreshInput,
+		timeout:       timeoutInput,
+		pageSize:      pageSizeInput,
 		focusIndex:    0,
+		refreshEvery:  defaultAutoRefreshSeconds * time.Second,
+		vimKeys:       true,
+		notify:        true,
+		spinner:       newSpinner(),
 	}
 }
 
+// normalizeAPIURL checks the API URL field and returns it in the form the
+// client uses: http:// is assumed when no scheme is given and trailing
+// slashes are dropped. Empty means keep the current URL.
+func normalizeAPIURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid API URL: %w", err)
+	}
+	switch {
+	case u.Scheme == "":
+		return "", fmt.Errorf("invalid API URL %q: missing scheme", raw)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return "", fmt.Errorf("invalid API URL %q: scheme must be http or https", raw)
+	case !validHost(u.Hostname()):
+		return "", fmt.Errorf("invalid API URL %q: bad host", raw)
+	}
+
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+// validHost reports whether host is an IP address or a DNS name
+func validHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	if host == "" || strings.HasPrefix(host, ".") || strings.HasSuffix(host, ".") {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Focus indexes of the theme selector and the saved configurations list,
+// which follow the text inputs
+const (
+	themeFocus = 7
+	listFocus  = 8
+)
+
+// defaultCacheRetentionDays is used when no retention is entered
+const defaultCacheRetentionDays = 30
+
+// parseRetentionDays reads the cache retention field; empty means the default
+func parseRetentionDays(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultCacheRetentionDays, nil
+	}
+
+	days, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("cache retention must be a whole number of days, got %q", raw)
+	}
+	if days < 1 {
+		return 0, fmt.Errorf("cache retention must be at least 1 day, got %d", days)
+	}
+
+	return days, nil
+}
+
+// defaultAutoRefreshSeconds is used when no auto-refresh interval is entered
+const defaultAutoRefreshSeconds = 60
+
+// minAutoRefreshSeconds keeps auto-refresh from hammering the API
+const minAutoRefreshSeconds = 5
+
+// parseTimeoutSeconds reads the request timeout field; empty means the
+// client's default
+func parseTimeoutSeconds(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultTimeout, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("request timeout must be a whole number of seconds, got %q", raw)
+	}
+	if seconds < 1 {
+		return 0, fmt.Errorf("request timeout must be at least 1 second, got %d", seconds)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// parsePageSize reads the results page size field, the most rows the Results
+// pane shows at once; empty means as many as fit, reported as zero
+func parsePageSize(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+
+	rows, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("page size must be a whole number of rows, got %q", raw)
+	}
+	if rows < 1 {
+		return 0, fmt.Errorf("page size must be at least 1 row, got %d", rows)
+	}
+
+	return rows, nil
+}
+
+// parseRefreshSeconds reads the auto-refresh interval field; empty means the
+// default
+func parseRefreshSeconds(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultAutoRefreshSeconds, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("auto-refresh interval must be a whole number of seconds, got %q", raw)
+	}
+	if seconds < minAutoRefreshSeconds {
+		return 0, fmt.Errorf("auto-refresh interval must be at least %d seconds, got %d", minAutoRefreshSeconds, seconds)
+	}
+
+	return seconds, nil
+}
+
 func (p *ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if p.confirm != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			var done bool
+			if done, cmd = p.confirm.Update(msg); done {
+				p.confirm = nil
+			}
+		}
+		return *p, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up":
+		typing := p.typing()
+
+		switch {
+		case navMatches(msg, typing, keys.Config.Up):
 			if p.focusIndex > 0 {
 				p.focusIndex--
 				p.updateFocus()
@@ -54,8 +272,8 @@ func (p *ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
 			}
 			return *p, nil
 
-		case "down":
-			if p.focusIndex < 2 {
+		case navMatches(msg, typing, keys.Config.Down):
+			if p.focusIndex < listFocus {
 				p.focusIndex++
 				p.updateFocus()
 			} else if len(p.configs) > 0 && p.selectedIdx < len(p.configs)-1 {
@@ -63,37 +281,107 @@ func (p *ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
 			}
 			return *p, nil
 
-		case "s":
+		case key.Matches(msg, keys.Config.PrevTheme) && p.focusIndex == themeFocus:
+			p.selectTheme((p.themeIdx + len(themes) - 1) % len(themes))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.NextTheme) && p.focusIndex == themeFocus:
+			p.selectTheme((p.themeIdx + 1) % len(themes))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Top) && p.focusIndex == listFocus:
+			p.selectedIdx, _ = scrollTo(0, 0, len(p.configs), len(p.configs))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Bottom) && p.focusIndex == listFocus:
+			p.selectedIdx, _ = scrollTo(len(p.configs)-1, 0, len(p.configs), len(p.configs))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.HalfPageUp) && p.focusIndex == listFocus:
+			p.selectedIdx, _ = scrollTo(p.selectedIdx-len(p.configs)/2, 0, len(p.configs), len(p.configs))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.HalfPageDn) && p.focusIndex == listFocus:
+			p.selectedIdx, _ = scrollTo(p.selectedIdx+len(p.configs)/2, 0, len(p.configs), len(p.configs))
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.VimToggle) && !typing:
+			p.vimKeys = !p.vimKeys
+			setVimNavigation(p.vimKeys)
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Notify) && !typing:
+			p.notify = !p.notify
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Save) && !typing:
 			// Save current configuration
 			if p.newConfigName.Value() != "" {
-				p.saving = true
-				// TODO: Save config
-				p.lastSuccess = fmt.Sprintf("Configuration '%s' saved", p.newConfigName.Value())
-				p.newConfigName.SetValue("")
+				p.saveConfig()
 			}
 			return *p, nil
 
-		case "l":
+		case key.Matches(msg, keys.Config.Load) && !typing:
 			// Load selected configuration
 			if len(p.configs) > 0 && p.selectedIdx < len(p.configs) {
-				// TODO: Load config
-				p.lastSuccess = fmt.Sprintf("Configuration '%s' loaded", p.configs[p.selectedIdx].Name)
+				return *p, p.loadConfig(p.configs[p.selectedIdx].Name)
+			}
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Test) && !typing:
+			return *p, p.testConnection()
+
+		case key.Matches(msg, keys.Config.Apply) && !typing:
+			// Apply the entered settings to the running session, but only
+			// point it at a new server once that server has answered
+			if apiURL, err := normalizeAPIURL(p.apiURL.Value()); err == nil && !p.canApplyURL(apiURL) {
+				p.lastSuccess = ""
+				p.lastError = fmt.Sprintf("test the connection to %s (t) before applying it", apiURL)
+				return *p, nil
+			}
+			if p.applyConfig() {
+				p.lastSuccess = fmt.Sprintf("Using API at %s", p.apiClient.BaseURL())
 			}
 			return *p, nil
 
-		case "d":
-			// Delete selected configuration
+		case key.Matches(msg, keys.Config.Prune) && !typing:
+			// Prune cached listings past the retention window
+			p.pruneCache()
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Export) && !typing:
+			// Back up the whole database
+			p.exportDatabase()
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Delete) && !typing:
+			// Delete selected configuration, once confirmed
 			if len(p.configs) > 0 && p.selectedIdx < len(p.configs) {
-				// TODO: Delete config
-				p.lastSuccess = "Configuration deleted"
+				name := p.configs[p.selectedIdx].Name
+				p.confirm = NewConfirmDialog(fmt.Sprintf("Delete '%s'?", name), func() tea.Cmd {
+					p.deleteConfig(name)
+					return nil
+				})
 			}
 			return *p, nil
 
-		case "r":
-			// Refresh config list
-			p.loading = true
-			// TODO: Refresh
+		case key.Matches(msg, keys.Config.Clear) && !typing:
+			p.confirmClear(ClearOptions{KeepConfigs: true})
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.ClearAll) && !typing:
+			p.confirmClear(ClearOptions{})
+			return *p, nil
+
+		case key.Matches(msg, keys.Config.Compact) && !typing:
+			p.compactDatabase()
 			return *p, nil
+
+		case key.Matches(msg, keys.Config.Refresh) && !typing:
+			// Reload the config list, e.g. after another session saved one
+			p.loading = true
+			p.lastError = ""
+			return *p, tea.Batch(reloadConfigs(p.db), p.spinner.Tick)
 		}
 	}
 
@@ -101,6 +389,16 @@ func (p *ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
 		p.newConfigName, cmd = p.newConfigName.Update(msg)
 	} else if p.focusIndex == 1 {
 		p.apiURL, cmd = p.apiURL.Update(msg)
+	} else if p.focusIndex == 2 {
+		p.authToken, cmd = p.authToken.Update(msg)
+	} else if p.focusIndex == 3 {
+		p.retention, cmd = p.retention.Update(msg)
+	} else if p.focusIndex == 4 {
+		p.refresh, cmd = p.refresh.Update(msg)
+	} else if p.focusIndex == 5 {
+		p.timeout, cmd = p.timeout.Update(msg)
+	} else if p.focusIndex == 6 {
+		p.pageSize, cmd = p.pageSize.Update(msg)
 	}
 
 	return *p, cmd
@@ -109,49 +407,516 @@ func (p *ConfigPane) Update(msg tea.Msg) (ConfigPane, tea.Cmd) {
 func (p *ConfigPane) updateFocus() {
 	p.newConfigName.Blur()
 	p.apiURL.Blur()
+	p.authToken.Blur()
+	p.retention.Blur()
+	p.refresh.Blur()
+	p.timeout.Blur()
+	p.pageSize.Blur()
 
 	if p.focusIndex == 0 {
 		p.newConfigName.Focus()
 	} else if p.focusIndex == 1 {
 		p.apiURL.Focus()
+	} else if p.focusIndex == 2 {
+		p.authToken.Focus()
+	} else if p.focusIndex == 3 {
+		p.retention.Focus()
+	} else if p.focusIndex == 4 {
+		p.refresh.Focus()
+	} else if p.focusIndex == 5 {
+		p.timeout.Focus()
+	} else if p.focusIndex == 6 {
+		p.pageSize.Focus()
+	}
+}
+
+// selectTheme switches the whole UI to themes[idx]
+func (p *ConfigPane) selectTheme(idx int) {
+	p.themeIdx = idx
+	theme = themes[idx]
+}
+
+// currentConfig returns the settings entered in the form
+func (p *ConfigPane) currentConfig() (map[string]interface{}, error) {
+	days, err := parseRetentionDays(p.retention.Value())
+	if err != nil {
+		return nil, err
+	}
+	seconds, err := parseRefreshSeconds(p.refresh.Value())
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := parseTimeoutSeconds(p.timeout.Value())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := parsePageSize(p.pageSize.Value())
+	if err != nil {
+		return nil, err
+	}
+	apiURL, err := normalizeAPIURL(p.apiURL.Value())
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{
+		"api_url":              apiURL,
+		"auth_token":           p.authToken.Value(),
+		"cache_retention_days": days,
+		"auto_refresh_seconds": seconds,
+		"timeout_seconds":      int(timeout / time.Second),
+		"theme":                themes[p.themeIdx].Name,
+		"vim_keys":             p.vimKeys,
+		"notifications":        p.notify,
+	}
+	if rows > 0 {
+		config["page_size"] = rows
+	}
+	return config, nil
+}
+
+// loadConfig fills the form from a saved configuration and applies it. A
+// saved search is run instead, through the returned command.
+func (p *ConfigPane) loadConfig(name string) tea.Cmd {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return nil
+	}
+
+	config, err := p.db.LoadConfig(name)
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+
+	// A saved search leaves the settings alone and runs the search instead
+	if query, ok := config["search_query"].(string); ok {
+		search := RunSavedSearchMsg{Query: query}
+		search.Provider, _ = config["provider"].(string)
+		search.Threshold, _ = config["threshold"].(float64)
+		search.Filter.MinPrice, _ = config["min_price"].(float64)
+		search.Filter.MaxPrice, _ = config["max_price"].(float64)
+		conditions, _ := config["conditions"].([]interface{})
+		for _, condition := range conditions {
+			if condition, ok := condition.(string); ok {
+				search.Filter.Conditions = append(search.Filter.Conditions, condition)
+			}
+		}
+		p.lastSuccess = fmt.Sprintf("Search '%s' loaded", name)
+		return func() tea.Msg { return search }
+	}
+
+	apiURL, _ := config["api_url"].(string)
+	authToken, _ := config["auth_token"].(string)
+	p.apiURL.SetValue(apiURL)
+	p.authToken.SetValue(authToken)
+	p.retention.SetValue("")
+	if days, ok := config["cache_retention_days"].(float64); ok {
+		p.retention.SetValue(strconv.Itoa(int(days)))
+	}
+	p.refresh.SetValue("")
+	if seconds, ok := config["auto_refresh_seconds"].(float64); ok {
+		p.refresh.SetValue(strconv.Itoa(int(seconds)))
+	}
+	p.timeout.SetValue("")
+	if seconds, ok := config["timeout_seconds"].(float64); ok {
+		p.timeout.SetValue(strconv.Itoa(int(seconds)))
+	}
+	p.pageSize.SetValue("")
+	if rows, ok := config["page_size"].(float64); ok {
+		p.pageSize.SetValue(strconv.Itoa(int(rows)))
+	}
+	if vim, ok := config["vim_keys"].(bool); ok {
+		p.vimKeys = vim
+		setVimNavigation(vim)
+	}
+	if notify, ok := config["notifications"].(bool); ok {
+		p.notify = notify
+	}
+	if name, ok := config["theme"].(string); ok {
+		if idx, ok := themeIndex(name); ok {
+			p.selectTheme(idx)
+		}
+	}
+
+	if p.applyConfig() {
+		p.lastSuccess = fmt.Sprintf("Configuration '%s' loaded", name)
+	}
+	return nil
+}
+
+// applyConfig points the shared API client at the entered URL and
+// credentials, sets its request timeout and takes up the auto-refresh
+// interval and results page size. An empty URL keeps the current one.
+func (p *ConfigPane) applyConfig() bool {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.apiClient == nil {
+		p.lastError = "no API client available"
+		return false
+	}
+
+	seconds, err := parseRefreshSeconds(p.refresh.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return false
+	}
+
+	timeout, err := parseTimeoutSeconds(p.timeout.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return false
+	}
+
+	rows, err := parsePageSize(p.pageSize.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return false
+	}
+
+	apiURL, err := normalizeAPIURL(p.apiURL.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return false
+	}
+	if apiURL != "" {
+		if err := p.apiClient.SetBaseURL(apiURL); err != nil {
+			p.lastError = err.Error()
+			return false
+		}
+		p.apiURL.SetValue(apiURL)
+	}
+	p.apiClient.SetAuth(p.authToken.Value(), "")
+	p.apiClient.SetTimeout(timeout)
+	p.refreshEvery = time.Duration(seconds) * time.Second
+	p.maxRows = rows
+
+	return true
+}
+
+// canApplyURL reports whether the session may switch to apiURL: it is empty
+// or already in use, or passed a connection test
+func (p *ConfigPane) canApplyURL(apiURL string) bool {
+	if apiURL == "" || p.apiClient == nil || apiURL == p.apiClient.BaseURL() {
+		return true
 	}
+	return apiURL == p.tested
+}
+
+// testConnection checks the entered API URL, with the entered credentials
+// and timeout, using a throwaway client so the running session is left alone
+func (p *ConfigPane) testConnection() tea.Cmd {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	timeout, err := parseTimeoutSeconds(p.timeout.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	apiURL, err := normalizeAPIURL(p.apiURL.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	if apiURL == "" {
+		p.lastError = "enter an API URL to test"
+		return nil
+	}
+
+	client := NewAPIClient(apiURL)
+	client.SetAuth(p.authToken.Value(), "")
+	client.SetTimeout(timeout)
+	p.testing = apiURL
+	p.testResult = ""
+
+	return func() tea.Msg {
+		health, err := client.Health()
+		return ConnectionTestMsg{URL: apiURL, Health: health, Error: err}
+	}
+}
+
+// ApplyConnectionTest records the outcome of a connection test. Only a test
+// that reached the server allows applying its URL.
+func (p *ConfigPane) ApplyConnectionTest(msg ConnectionTestMsg) {
+	if msg.URL != p.testing {
+		// A newer test has started since
+		return
+	}
+	p.testing = ""
+
+	if msg.Error != nil {
+		p.testFailed = true
+		p.testResult = fmt.Sprintf("%s: %s", msg.URL, describeError(msg.Error))
+		if p.tested == msg.URL {
+			p.tested = ""
+		}
+		return
+	}
+
+	p.testFailed = false
+	p.tested = msg.URL
+	p.testResult = fmt.Sprintf("%s is reachable", msg.URL)
+	if !msg.Health.Basic {
+		p.testResult = fmt.Sprintf("%s is %s", msg.URL, msg.Health.Status)
+		if msg.Health.Version != "" {
+			p.testResult += fmt.Sprintf(" (version %s)", msg.Health.Version)
+		}
+	}
+}
+
+// saveConfig stores the form under the entered name and reloads the list
+func (p *ConfigPane) saveConfig() {
+	name := p.newConfigName.Value()
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+	// The session row is hidden from the list, so a config saved over it
+	// would vanish
+	if name == sessionConfigName {
+		p.lastError = fmt.Sprintf("'%s' is reserved, choose another name", name)
+		return
+	}
+
+	config, err := p.currentConfig()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	if err := p.db.SaveConfig(name, config); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Configuration '%s' saved", name)
+	p.newConfigName.SetValue("")
+	p.LoadConfigs(p.db)
+}
+
+// deleteConfig removes a saved configuration and reloads the list
+func (p *ConfigPane) deleteConfig(name string) {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	if err := p.db.DeleteConfig(name); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Configuration '%s' deleted", name)
+	p.LoadConfigs(p.db)
+	if p.selectedIdx >= len(p.configs) && p.selectedIdx > 0 {
+		p.selectedIdx = len(p.configs) - 1
+	}
+}
+
+// typing reports whether one of the form's text fields has focus
+func (p *ConfigPane) typing() bool {
+	return p.focusIndex < themeFocus
+}
+
+// capturingInput reports whether the pane needs every key, e.g. while a
+// confirmation is open
+func (p *ConfigPane) capturingInput() bool {
+	return p.confirm != nil
+}
+
+// pruneCache drops cached listings older than the entered retention window
+func (p *ConfigPane) pruneCache() {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	days, err := parseRetentionDays(p.retention.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	pruned, err := p.db.PruneCachedListings(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Pruned %d cached listings older than %d days", pruned, days)
+}
+
+// clearedData describes what ClearData deletes with opts
+func clearedData(opts ClearOptions) string {
+	if opts.KeepConfigs {
+		return "search history, price history and cached listings"
+	}
+	return "search history, price history, cached listings and saved configs"
+}
+
+// confirmClear asks before clearing the local data
+func (p *ConfigPane) confirmClear(opts ClearOptions) {
+	p.confirm = NewConfirmDialog(fmt.Sprintf("Clear all %s?", clearedData(opts)), func() tea.Cmd {
+		return p.clearData(opts)
+	})
+}
+
+// clearData deletes the local data, returning a command that has the other
+// panes reload what they show from it
+func (p *ConfigPane) clearData(opts ClearOptions) tea.Cmd {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return nil
+	}
+	if err := p.db.ClearData(opts); err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+
+	p.lastSuccess = "Cleared " + clearedData(opts)
+	if !opts.KeepConfigs {
+		p.LoadConfigs(p.db)
+		p.selectedIdx = 0
+	}
+	return func() tea.Msg { return DataClearedMsg{} }
+}
+
+// compactDatabase vacuums the database, reporting how much it shrank
+func (p *ConfigPane) compactDatabase() {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	before, err := p.db.Size()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	if err := p.db.Vacuum(); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	after, err := p.db.Size()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Database compacted: %s → %s", formatBytes(before), formatBytes(after))
+}
+
+// formatBytes renders a size in bytes with a binary unit, e.g. "1.5 MiB"
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// backupFileName is written to the home directory by the export action
+const backupFileName = "arbfinder_backup.json"
+
+// exportDatabase writes a JSON backup of the database to the home directory
+func (p *ConfigPane) exportDatabase() {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	path := filepath.Join(homeDir, backupFileName)
+
+	f, err := os.Create(path)
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	if err := p.db.ExportJSON(f); err != nil {
+		f.Close()
+		p.lastError = err.Error()
+		return
+	}
+	if err := f.Close(); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Database exported to %s", path)
 }
 
 func (p *ConfigPane) View(width, height int) string {
+	if p.confirm != nil {
+		return p.confirm.View(width, height)
+	}
+
 	var b strings.Builder
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
+		Foreground(theme.Primary).
 		MarginBottom(1)
 
 	sectionStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Text).
 		MarginTop(1).
 		MarginBottom(1)
 
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Text).
 		Bold(true)
 
 	itemStyle := lipgloss.NewStyle().
 		Padding(0, 1)
 
 	selectedItemStyle := itemStyle.Copy().
-		Background(lipgloss.Color("#7D56F4")).
+		Background(theme.SelectedBg).
 		Bold(true)
 
 	infoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Muted).
 		Italic(true)
 
 	successStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00FF00")).
+		Foreground(theme.Success).
 		Bold(true)
 
 	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF0000")).
+		Foreground(theme.Error).
 		Bold(true)
 
 	// Title
@@ -161,7 +926,7 @@ func (p *ConfigPane) View(width, height int) string {
 	// New configuration section
 	b.WriteString(sectionStyle.Render("📝 New Configuration"))
 	b.WriteString("\n")
-	
+
 	b.WriteString(labelStyle.Render("Config Name:"))
 	b.WriteString("\n")
 	b.WriteString(p.newConfigName.View())
@@ -171,7 +936,102 @@ func (p *ConfigPane) View(width, height int) string {
 	b.WriteString("\n")
 	b.WriteString(p.apiURL.View())
 	b.WriteString("\n")
-	b.WriteString(infoStyle.Render("Press 's' to save current configuration"))
+	apiURL, urlErr := normalizeAPIURL(p.apiURL.Value())
+	if urlErr != nil {
+		b.WriteString(errorStyle.Render("✗ " + urlErr.Error()))
+		b.WriteString("\n")
+	} else if apiURL != strings.TrimSpace(p.apiURL.Value()) {
+		b.WriteString(infoStyle.Render("Will use " + apiURL))
+		b.WriteString("\n")
+	}
+	switch {
+	case p.testing != "":
+		b.WriteString(infoStyle.Render(fmt.Sprintf("Testing connection to %s...", p.testing)))
+		b.WriteString("\n")
+	case p.testResult != "" && p.testFailed:
+		b.WriteString(errorStyle.Render("✗ Connection failed: " + p.testResult))
+		b.WriteString("\n")
+	case p.testResult != "":
+		b.WriteString(successStyle.Render("✓ Connected: " + p.testResult))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(labelStyle.Render("Auth Token:"))
+	b.WriteString("\n")
+	b.WriteString(p.authToken.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(labelStyle.Render("Cache Retention (days):"))
+	b.WriteString("\n")
+	b.WriteString(p.retention.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(labelStyle.Render("Auto-Refresh Interval (seconds):"))
+	b.WriteString("\n")
+	b.WriteString(p.refresh.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(labelStyle.Render("Request Timeout (seconds):"))
+	b.WriteString("\n")
+	b.WriteString(p.timeout.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(labelStyle.Render("Results Page Size (rows):"))
+	b.WriteString("\n")
+	b.WriteString(p.pageSize.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(labelStyle.Render("Theme:"))
+	b.WriteString("\n")
+
+	themeStyle := lipgloss.NewStyle().
+		Padding(0, 1).
+		Margin(0, 1, 0, 0)
+
+	selectedThemeStyle := themeStyle.Copy().
+		Foreground(theme.Text).
+		Background(theme.SelectedBg).
+		Bold(p.focusIndex == themeFocus)
+
+	for i, t := range themes {
+		if i == p.themeIdx {
+			b.WriteString(selectedThemeStyle.Render(t.Name))
+		} else {
+			b.WriteString(themeStyle.Render(t.Name))
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render("Use ←/→ to select theme"))
+	b.WriteString("\n\n")
+
+	vim := "off (arrow keys only)"
+	if p.vimKeys {
+		vim = "on (h/j/k/l, g/G)"
+	}
+	b.WriteString(labelStyle.Render("Vim Navigation: "))
+	b.WriteString(vim)
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render("Press 'v' outside the text fields to toggle"))
+	b.WriteString("\n")
+
+	notify := "off"
+	if p.notify {
+		notify = "on"
+	}
+	b.WriteString(labelStyle.Render("Desktop Notifications: "))
+	b.WriteString(notify)
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render("Press 'n' outside the text fields to toggle price alert notifications"))
+	b.WriteString("\n")
+	disabledStyle := infoStyle.Copy().Foreground(theme.Subtle)
+	if urlErr != nil {
+		b.WriteString(disabledStyle.Render("Save (s) and apply (a) are disabled until the API URL is valid"))
+	} else if !p.canApplyURL(apiURL) {
+		b.WriteString(disabledStyle.Render("Press 't' outside the text fields to test the new API URL; apply (a) is enabled once it connects"))
+	} else {
+		b.WriteString(infoStyle.Render("Press 's' to save or 'a' to apply current configuration"))
+	}
 	b.WriteString("\n")
 
 	// Saved configurations
@@ -181,9 +1041,9 @@ func (p *ConfigPane) View(width, height int) string {
 
 	if p.loading {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
+			Foreground(theme.Success).
 			Bold(true)
-		b.WriteString(statusStyle.Render("🔄 Loading..."))
+		b.WriteString(statusStyle.Render(p.spinner.View() + " Loading..."))
 		b.WriteString("\n")
 	} else if len(p.configs) == 0 {
 		b.WriteString(infoStyle.Render("No saved configurations yet"))
@@ -194,7 +1054,7 @@ func (p *ConfigPane) View(width, height int) string {
 				config.Name,
 				config.CreatedAt.Format("2006-01-02 15:04"),
 			)
-			if i == p.selectedIdx && p.focusIndex == 2 {
+			if i == p.selectedIdx && p.focusIndex == listFocus {
 				b.WriteString(selectedItemStyle.Render("▸ " + line))
 			} else {
 				b.WriteString(itemStyle.Render("  " + line))
@@ -205,7 +1065,7 @@ func (p *ConfigPane) View(width, height int) string {
 
 	// Instructions
 	b.WriteString("\n")
-	b.WriteString(infoStyle.Render("↑/↓: Navigate • s: Save • l: Load • d: Delete • r: Refresh • Tab: Switch pane"))
+	b.WriteString(infoStyle.Render("↑/↓: Navigate • ←/→: Theme • s: Save • t: Test connection • a: Apply • l: Load • d: Delete • r: Refresh • p: Prune cache • e: Export • c: Compact • X: Clear data • Tab: Switch pane"))
 
 	// Status messages
 	if p.lastSuccess != "" {
@@ -221,14 +1081,41 @@ func (p *ConfigPane) View(width, height int) string {
 	return b.String()
 }
 
-func (p *ConfigPane) LoadConfigs(db *Database) {
-	if db != nil {
-		configs, err := db.GetAllConfigs()
-		if err == nil {
-			p.configs = configs
-		} else {
-			p.lastError = err.Error()
+// LoadConfigs reads the saved configs from db, returning the error it also
+// shows in the pane
+func (p *ConfigPane) LoadConfigs(db *Database) error {
+	p.loading = false
+	if db == nil {
+		return nil
+	}
+
+	configs, err := db.GetAllConfigs()
+	if err != nil {
+		p.lastError = err.Error()
+		return err
+	}
+	p.configs = configs
+	return nil
+}
+
+// reloadConfigs reads the saved configs off the UI goroutine
+func reloadConfigs(db *Database) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return ConfigLoadedMsg{Error: errors.New("no database available")}
 		}
+		configs, err := db.GetAllConfigs()
+		return ConfigLoadedMsg{Configs: configs, Error: err}
 	}
+}
+
+// ApplyConfigs shows a reloaded config list
+func (p *ConfigPane) ApplyConfigs(msg ConfigLoadedMsg) {
 	p.loading = false
+	if msg.Error != nil {
+		p.lastError = msg.Error.Error()
+		return
+	}
+	p.configs = msg.Configs
+	p.selectedIdx, _ = scrollTo(p.selectedIdx, 0, len(p.configs), len(p.configs))
 }
@@ -0,0 +1,592 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigPaneSavesAuthToken(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.newConfigName.SetValue("prod")
+	pane.apiURL.SetValue("https://api.example.com")
+	pane.authToken.SetValue("secret")
+	pane.focusIndex = listFocus
+
+	pane.Update(keyMsg("s"))
+	if pane.lastError != "" {
+		t.Fatalf("Failed to save config: %s", pane.lastError)
+	}
+
+	config, err := db.LoadConfig("prod")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if config["api_url"] != "https://api.example.com" {
+		t.Errorf("Expected api_url 'https://api.example.com', got '%v'", config["api_url"])
+	}
+	if config["auth_token"] != "secret" {
+		t.Errorf("Expected auth_token 'secret', got '%v'", config["auth_token"])
+	}
+	if len(pane.configs) != 1 {
+		t.Errorf("Expected 1 config in list, got %d", len(pane.configs))
+	}
+}
+
+func TestConfigPaneRejectsSessionName(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	if err := db.SaveSession(map[string]interface{}{"provider": "govdeals"}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.newConfigName.SetValue(sessionConfigName)
+	pane.focusIndex = listFocus
+	pane.Update(keyMsg("s"))
+	if !strings.Contains(pane.lastError, "reserved") || pane.lastSuccess != "" {
+		t.Errorf("Expected the session name to be rejected, got '%s' (success '%s')", pane.lastError, pane.lastSuccess)
+	}
+	if session, err := db.LoadConfig(sessionConfigName); err != nil || session["provider"] != "govdeals" {
+		t.Errorf("Expected the session row untouched, got %v (%v)", session, err)
+	}
+}
+
+func TestConfigPageSizeCapsResults(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("")
+	m.config.db = db
+	m.config.newConfigName.SetValue("big-monitor")
+	m.config.pageSize.SetValue("25")
+	m.config.saveConfig()
+	if m.config.lastError != "" {
+		t.Fatalf("Failed to save config: %s", m.config.lastError)
+	}
+	if config, _ := db.LoadConfig("big-monitor"); config["page_size"] != float64(25) {
+		t.Errorf("Expected page_size 25 to be saved, got %v", config["page_size"])
+	}
+
+	listings := make([]APIListing, 40)
+	for i := range listings {
+		listings[i] = APIListing{ID: i + 1, Title: "RTX 3060", Price: float64(200 + i)}
+	}
+	m.results.SetResults(listings)
+	if view := m.results.View(150, 60); !strings.Contains(view, "Showing 1-4") {
+		t.Fatalf("Expected the tall window to fit over 25 rows, got:\n%s", view)
+	}
+
+	// Loading the config caps the window
+	m.config.pageSize.SetValue("")
+	m.currentPane = 3
+	m.config.focusIndex = listFocus
+	m.Update(keyMsg("l"))
+	if m.config.lastError != "" {
+		t.Fatalf("Failed to load config: %s", m.config.lastError)
+	}
+	if view := m.results.View(150, 60); !strings.Contains(view, "Showing 1-25 of 40") {
+		t.Errorf("Expected 25 rows, got:\n%s", view)
+	}
+
+	// The cap is an upper bound: a short window still fits fewer
+	if view := m.results.View(150, 20); strings.Contains(view, "Showing 1-25") {
+		t.Errorf("Expected fewer rows in a short window, got:\n%s", view)
+	}
+}
+
+func TestParsePageSize(t *testing.T) {
+	if rows, err := parsePageSize(""); err != nil || rows != 0 {
+		t.Errorf("Expected no cap for an empty field, got %d, %v", rows, err)
+	}
+	if rows, err := parsePageSize(" 25 "); err != nil || rows != 25 {
+		t.Errorf("Expected 25, got %d, %v", rows, err)
+	}
+	for _, raw := range []string{"0", "-3", "ten", "2.5"} {
+		if _, err := parsePageSize(raw); err == nil {
+			t.Errorf("Expected an error for %q", raw)
+		}
+	}
+}
+
+func TestConfigPaneTimeout(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.newConfigName.SetValue("slow")
+	pane.timeout.SetValue("90")
+	pane.focusIndex = listFocus
+	pane.Update(keyMsg("s"))
+	if pane.lastError != "" {
+		t.Fatalf("Failed to save config: %s", pane.lastError)
+	}
+
+	client := NewAPIClient("")
+	loaded := NewConfigPane()
+	loaded.db = db
+	loaded.apiClient = client
+	loaded.loadConfig("slow")
+	if got := loaded.timeout.Value(); got != "90" {
+		t.Errorf("Expected timeout field '90', got '%s'", got)
+	}
+	if got := client.Timeout(); got != 90*time.Second {
+		t.Errorf("Expected the client timeout to be 90s, got %v", got)
+	}
+
+	loaded.timeout.SetValue("0")
+	if loaded.applyConfig() {
+		t.Error("Expected a zero timeout to be rejected")
+	}
+	if got := client.Timeout(); got != 90*time.Second {
+		t.Errorf("Expected the client timeout to stay 90s, got %v", got)
+	}
+}
+
+func TestConfigPaneExportsDatabase(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.focusIndex = 1
+	pane.updateFocus()
+	pane.apiURL.SetValue("https://")
+	for _, r := range "exe.net" {
+		pane.Update(keyMsg(string(r)))
+	}
+	if got := pane.apiURL.Value(); got != "https://exe.net" {
+		t.Errorf("Expected the URL to be typed in full, got '%s'", got)
+	}
+	if _, err := os.Stat(filepath.Join(home, backupFileName)); !os.IsNotExist(err) {
+		t.Fatalf("Expected no backup while typing, got %v", err)
+	}
+
+	pane.focusIndex = listFocus
+	pane.updateFocus()
+	pane.Update(keyMsg("e"))
+	if pane.lastError != "" {
+		t.Fatalf("Failed to export database: %s", pane.lastError)
+	}
+
+	f, err := os.Open(filepath.Join(home, backupFileName))
+	if err != nil {
+		t.Fatalf("Expected backup file in home directory: %v", err)
+	}
+	defer f.Close()
+
+	var export DatabaseExport
+	if err := json.NewDecoder(f).Decode(&export); err != nil {
+		t.Fatalf("Failed to decode backup: %v", err)
+	}
+	if len(export.SavedConfigs) != 1 || export.SavedConfigs[0].Name != "prod" {
+		t.Errorf("Expected backup to contain config 'prod', got %+v", export.SavedConfigs)
+	}
+}
+
+func TestConfigPaneClearsData(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveSearchHistory("rtx 3060", 4); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	m := newTestModel("")
+	m.db, m.search.db, m.config.db, m.currentPane = db, db, db, 3
+	m.config.LoadConfigs(db)
+	m.config.focusIndex = listFocus
+	m.cachedPrices["https://ebay.com/1"] = 250
+
+	// Cancelling leaves everything alone
+	m.config.Update(keyMsg("X"))
+	m.config.Update(keyMsg("n"))
+	if history, _ := db.GetSearchHistory(10); len(history) != 1 {
+		t.Fatalf("Expected the history to survive a cancelled clear, got %+v", history)
+	}
+
+	m.config.Update(keyMsg("X"))
+	if !m.config.capturingInput() {
+		t.Fatal("Expected X to ask for confirmation")
+	}
+	_, cmd := m.config.Update(keyMsg("y"))
+	if cmd == nil {
+		t.Fatalf("Expected a command after clearing, got error '%s'", m.config.lastError)
+	}
+	if _, ok := cmd().(DataClearedMsg); !ok {
+		t.Fatal("Expected clearing to report DataClearedMsg")
+	}
+	if history, _ := db.GetSearchHistory(10); len(history) != 0 {
+		t.Errorf("Expected the search history to be cleared, got %+v", history)
+	}
+	if len(m.config.configs) != 1 {
+		t.Errorf("Expected X to keep the saved configs, got %+v", m.config.configs)
+	}
+
+	tm, cmd := m.Update(DataClearedMsg{})
+	m = tm.(model)
+	if cmd == nil || !m.stats.loading {
+		t.Error("Expected the stats to reload after clearing")
+	}
+	if len(m.cachedPrices) != 0 {
+		t.Errorf("Expected the cached prices of this run to be forgotten, got %v", m.cachedPrices)
+	}
+
+	// Like X, ctrl+x waits until no field has focus
+	m.config.focusIndex = 0
+	m.config.Update(keyMsg("ctrl+x"))
+	if m.config.capturingInput() {
+		t.Fatal("Expected ctrl+x to be ignored in a text field")
+	}
+
+	m.config.focusIndex = listFocus
+	m.config.Update(keyMsg("ctrl+x"))
+	m.config.Update(keyMsg("y"))
+	if len(m.config.configs) != 0 || !strings.Contains(m.config.lastSuccess, "saved configs") {
+		t.Errorf("Expected ctrl+x to clear the saved configs too, got %+v ('%s')", m.config.configs, m.config.lastSuccess)
+	}
+}
+
+func TestConfigPaneCompactsDatabase(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.Update(keyMsg("c"))
+	if pane.lastSuccess != "" {
+		t.Fatal("Expected 'c' to be typed into the focused field, not to compact")
+	}
+
+	pane.focusIndex = listFocus
+	pane.Update(keyMsg("c"))
+	if pane.lastError != "" || !strings.HasPrefix(pane.lastSuccess, "Database compacted: ") || !strings.Contains(pane.lastSuccess, "KiB → ") {
+		t.Errorf("Expected the sizes before and after compacting, got '%s' (error '%s')", pane.lastSuccess, pane.lastError)
+	}
+
+	for n, want := range map[int64]string{0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 20: "5.0 MiB"} {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d): expected '%s', got '%s'", n, want, got)
+		}
+	}
+}
+
+func TestConfigPaneNameFieldTakesLetters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	// The pane opens with the name field focused
+	pane := NewConfigPane()
+	pane.db = db
+	pane.apiClient = NewAPIClient("http://localhost:8080")
+	pane.LoadConfigs(db)
+	for _, r := range "spreadall" {
+		pane.Update(keyMsg(string(r)))
+	}
+
+	if got := pane.newConfigName.Value(); got != "spreadall" {
+		t.Errorf("Expected config name 'spreadall', got '%s'", got)
+	}
+	if pane.capturingInput() || pane.loading || pane.lastSuccess != "" || pane.lastError != "" {
+		t.Errorf("Expected only the text to change, got '%s' (error '%s')", pane.lastSuccess, pane.lastError)
+	}
+	if configs, _ := db.GetAllConfigs(); len(configs) != 1 {
+		t.Errorf("Expected the saved configs untouched, got %+v", configs)
+	}
+	if _, err := os.Stat(filepath.Join(home, backupFileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup written, got %v", err)
+	}
+}
+
+func TestConfigPaneRefreshReloadsConfigs(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("")
+	m.db, m.config.db, m.currentPane = db, db, 3
+	m.config.LoadConfigs(db)
+	m.config.focusIndex = listFocus
+
+	// Saved elsewhere after the list was loaded
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	tm, cmd := m.Update(keyMsg("r"))
+	m = tm.(model)
+	if !m.config.loading || cmd == nil {
+		t.Fatal("Expected 'r' to start reloading the configs")
+	}
+	tm, _ = m.Update(runCmd(cmd))
+	m = tm.(model)
+	if m.config.loading {
+		t.Error("Expected the spinner to stop once the configs are loaded")
+	}
+	if len(m.config.configs) != 1 || m.config.configs[0].Name != "prod" {
+		t.Errorf("Expected the reloaded list to show 'prod', got %+v", m.config.configs)
+	}
+}
+
+func TestConfigPanePruneWaitsForTyping(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.Update(keyMsg("p"))
+	if got := pane.newConfigName.Value(); got != "p" {
+		t.Errorf("Expected 'p' to be typed into the name, got '%s'", got)
+	}
+	if pane.lastSuccess != "" || pane.lastError != "" {
+		t.Errorf("Expected no prune while typing, got '%s' (error '%s')", pane.lastSuccess, pane.lastError)
+	}
+
+	pane.focusIndex = listFocus
+	pane.updateFocus()
+	pane.Update(keyMsg("p"))
+	if !strings.HasPrefix(pane.lastSuccess, "Pruned 0 cached listings") {
+		t.Errorf("Expected 'p' to prune from the list, got '%s' (error '%s')", pane.lastSuccess, pane.lastError)
+	}
+}
+
+func TestParseRetentionDays(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected int
+		wantErr  bool
+	}{
+		{"", defaultCacheRetentionDays, false},
+		{" 7 ", 7, false},
+		{"0", 0, true},
+		{"-3", 0, true},
+		{"1.5", 0, true},
+		{"week", 0, true},
+	}
+
+	for _, tt := range tests {
+		days, err := parseRetentionDays(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRetentionDays(%q): expected error %v, got %v", tt.raw, tt.wantErr, err)
+			continue
+		}
+		if days != tt.expected {
+			t.Errorf("parseRetentionDays(%q): expected %d, got %d", tt.raw, tt.expected, days)
+		}
+	}
+}
+
+func TestNormalizeAPIURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: ""},
+		{raw: "   ", want: ""},
+		{raw: "http://localhost:8080", want: "http://localhost:8080"},
+		{raw: "localhost:8080", want: "http://localhost:8080"},
+		{raw: "api.example.com", want: "http://api.example.com"},
+		{raw: " https://api.example.com/ ", want: "https://api.example.com"},
+		{raw: "https://api.example.com/v1///", want: "https://api.example.com/v1"},
+		{raw: "192.168.1.10:9000/", want: "http://192.168.1.10:9000"},
+		{raw: "http://[::1]:8080", want: "http://[::1]:8080"},
+		{raw: "://example.com", wantErr: true},
+		{raw: "ftp://example.com", wantErr: true},
+		{raw: "http://", wantErr: true},
+		{raw: "not a url", wantErr: true},
+		{raw: "http://bad_host", wantErr: true},
+		{raw: "http://-example.com", wantErr: true},
+		{raw: "http://example..com", wantErr: true},
+		{raw: "http://example.com:port", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeAPIURL(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("normalizeAPIURL(%q): expected error, got '%s'", tt.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("normalizeAPIURL(%q): unexpected error: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeAPIURL(%q): expected '%s', got '%s'", tt.raw, tt.want, got)
+		}
+	}
+}
+
+func TestConfigPaneNormalizesAppliedURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(HealthStatus{Status: "ok"})
+	}))
+	defer server.Close()
+
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 3
+
+	m.config.apiURL.SetValue(strings.TrimPrefix(server.URL, "http://") + "/")
+	m.config.focusIndex = themeFocus
+	_, cmd := m.Update(keyMsg("t"))
+	m.Update(runCmd(cmd))
+	m.Update(keyMsg("a"))
+	if m.config.lastError != "" {
+		t.Fatalf("Failed to apply config: %s", m.config.lastError)
+	}
+	if got := m.apiClient.BaseURL(); got != server.URL {
+		t.Errorf("Expected base URL '%s', got '%s'", server.URL, got)
+	}
+	if got := m.config.apiURL.Value(); got != server.URL {
+		t.Errorf("Expected the field to show the normalized URL, got '%s'", got)
+	}
+}
+
+func TestConfigPaneApplyRequiresConnectionTest(t *testing.T) {
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(HealthStatus{Status: "ok", Version: "1.4.0"})
+	}))
+	defer server.Close()
+
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 3
+	m.config.apiURL.SetValue(server.URL)
+	m.config.focusIndex = themeFocus
+
+	// Untested
+	m.Update(keyMsg("a"))
+	if m.config.lastError == "" || m.apiClient.BaseURL() != "http://localhost:8080" {
+		t.Fatalf("Expected apply to wait for a connection test, now using %s", m.apiClient.BaseURL())
+	}
+
+	// Failing test
+	up = false
+	_, cmd := m.Update(keyMsg("t"))
+	if !strings.Contains(m.config.View(120, 60), "Testing connection to "+server.URL) {
+		t.Error("Expected the test to show while it runs")
+	}
+	m.Update(runCmd(cmd))
+	m.Update(keyMsg("a"))
+	if m.apiClient.BaseURL() != "http://localhost:8080" {
+		t.Fatal("Expected a failed test to block apply")
+	}
+	if view := m.config.View(120, 60); !strings.Contains(view, "Connection failed") {
+		t.Errorf("Expected the failure inline, got:\n%s", view)
+	}
+
+	// Succeeding test
+	up = true
+	_, cmd = m.Update(keyMsg("t"))
+	m.Update(runCmd(cmd))
+	if view := m.config.View(120, 60); !strings.Contains(view, "Connected: "+server.URL+" is ok (version 1.4.0)") {
+		t.Errorf("Expected the success inline, got:\n%s", view)
+	}
+	m.Update(keyMsg("a"))
+	if m.config.lastError != "" {
+		t.Fatalf("Failed to apply config: %s", m.config.lastError)
+	}
+	if got := m.apiClient.BaseURL(); got != server.URL {
+		t.Errorf("Expected base URL '%s', got '%s'", server.URL, got)
+	}
+
+	// A stale failure for another URL doesn't undo a passed test
+	m.config.testing = server.URL
+	m.config.ApplyConnectionTest(ConnectionTestMsg{URL: "http://elsewhere.example.com", Error: errors.New("boom")})
+	if m.config.testFailed {
+		t.Error("Expected a result for another URL to be ignored")
+	}
+}
+
+func TestConfigPaneApplyWaitsForTyping(t *testing.T) {
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 3
+	m.config.focusIndex = 1
+	m.config.updateFocus()
+	m.config.apiURL.SetValue("http://api.ex")
+
+	m.Update(keyMsg("a"))
+	if got := m.config.apiURL.Value(); got != "http://api.exa" {
+		t.Errorf("Expected 'a' to be typed into the URL, got '%s'", got)
+	}
+	if m.apiClient.BaseURL() != "http://localhost:8080" || m.config.lastError != "" {
+		t.Errorf("Expected nothing applied while typing, now using %s ('%s')", m.apiClient.BaseURL(), m.config.lastError)
+	}
+}
+
+func TestConfigPaneFlagsInvalidURLWhileTyping(t *testing.T) {
+	pane := NewConfigPane()
+	pane.apiURL.SetValue("ftp://x")
+
+	view := pane.View(120, 60)
+	if !strings.Contains(view, "scheme must be http or https") {
+		t.Errorf("Expected the view to flag the scheme, got:\n%s", view)
+	}
+	if !strings.Contains(view, "disabled until the API URL is valid") {
+		t.Errorf("Expected save and apply to be shown as disabled, got:\n%s", view)
+	}
+}
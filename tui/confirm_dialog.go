@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ConfirmDialog asks a yes/no question before a destructive action. While it
+// is open the pane hands it every key, so nothing else acts on them.
+type ConfirmDialog struct {
+	prompt    string
+	onConfirm func() tea.Cmd
+}
+
+func NewConfirmDialog(prompt string, onConfirm func() tea.Cmd) *ConfirmDialog {
+	return &ConfirmDialog{prompt: prompt, onConfirm: onConfirm}
+}
+
+// Update handles a key press and reports whether the dialog was answered. A
+// confirmation runs the callback and returns its command.
+func (d *ConfirmDialog) Update(msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch {
+	case key.Matches(msg, keys.Confirm.Yes):
+		return true, d.onConfirm()
+	case key.Matches(msg, keys.Confirm.No):
+		return true, nil
+	}
+	return false, nil
+}
+
+// View renders the dialog centred in the pane
+func (d *ConfirmDialog) View(width, height int) string {
+	promptStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Error).
+		Padding(1, 2)
+
+	box := boxStyle.Render(promptStyle.Render(d.prompt+" (y/n)") + "\n\n" + infoStyle.Render("y: Confirm • n/Esc: Cancel"))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
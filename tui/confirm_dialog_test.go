@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestConfirmDialogAnswers(t *testing.T) {
+	calls := 0
+	confirm := func() tea.Cmd {
+		calls++
+		return nil
+	}
+
+	dialog := NewConfirmDialog("Delete 'prod'?", confirm)
+	if done, _ := dialog.Update(keyMsg("x")); done {
+		t.Error("Expected other keys to leave the dialog open")
+	}
+	if done, _ := dialog.Update(keyMsg("n")); !done {
+		t.Error("Expected 'n' to close the dialog")
+	}
+	if calls != 0 {
+		t.Errorf("Expected 'n' not to run the callback, ran %d times", calls)
+	}
+
+	dialog = NewConfirmDialog("Delete 'prod'?", confirm)
+	if done, _ := dialog.Update(keyMsg("y")); !done {
+		t.Error("Expected 'y' to close the dialog")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 'y' to run the callback once, ran %d times", calls)
+	}
+}
+
+func TestConfigDeleteNeedsConfirmation(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	m := newTestModel("")
+	m.db = db
+	m.config.db = db
+	m.config.LoadConfigs(db)
+	m.config.focusIndex = listFocus
+	m.currentPane = 3
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(keyMsg("d"))
+	if !tm.(model).config.capturingInput() {
+		t.Fatal("Expected 'd' to ask for confirmation")
+	}
+
+	// Keys are held by the dialog, so 'q' neither quits nor deletes
+	tm, cmd := tm.Update(keyMsg("q"))
+	if cmd != nil {
+		t.Error("Expected the dialog to swallow 'q'")
+	}
+	tm, _ = tm.Update(keyMsg("n"))
+	if configs, _ := db.GetAllConfigs(); len(configs) != 1 {
+		t.Fatalf("Expected 'n' to keep the config, got %d configs", len(configs))
+	}
+
+	tm, _ = tm.Update(keyMsg("d"))
+	tm, _ = tm.Update(keyMsg("y"))
+	if configs, _ := db.GetAllConfigs(); len(configs) != 0 {
+		t.Errorf("Expected 'y' to delete the config, got %d configs", len(configs))
+	}
+	config := tm.(model).config
+	if config.capturingInput() || len(config.configs) != 0 {
+		t.Errorf("Expected the dialog closed and the list reloaded, got %d configs", len(config.configs))
+	}
+	if toasts := tm.(model).toasts; len(toasts) != 1 || toasts[0].text != "Configuration 'prod' deleted" {
+		t.Errorf("Expected a deleted notification, got %+v", toasts)
+	}
+}
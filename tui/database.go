@@ -3,132 +3,242 @@ package main
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Database struct {
-	db *sql.DB
+	db        *sql.DB
+	closeOnce sync.Once
 }
 
 type SearchHistory struct {
-	ID        int
-	Query     string
-	Timestamp time.Time
-	Results   int
+	ID        int       `json:"id"`
+	Query     string    `json:"query"`
+	Timestamp time.Time `json:"timestamp"`
+	Results   int       `json:"results"`
+	Count     int       `json:"count"`
 }
 
 type SavedConfig struct {
-	ID        int
-	Name      string
-	Config    string
-	CreatedAt time.Time
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Config    string    `json:"config"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type PriceHistory struct {
-	ID        int
+	ID        int       `json:"id"`
+	ItemTitle string    `json:"item_title"`
+	Price     float64   `json:"price"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+	Metadata  string    `json:"metadata"`
+}
+
+// PriceDrop is an item whose latest recorded price fell from the one before
+type PriceDrop struct {
+	ItemTitle     string
+	Source        string
+	PreviousPrice float64
+	CurrentPrice  float64
+	DropPct       float64
+	Timestamp     time.Time
+}
+
+// Mover is an item whose price changed between its first and last
+// recorded prices in a window
+type Mover struct {
 	ItemTitle string
-	Price     float64
-	Source    string
-	Timestamp time.Time
-	Metadata  string
+	OldPrice  float64
+	NewPrice  float64
+	Delta     float64 // NewPrice - OldPrice
+	Pct       float64 // Delta as a percentage of OldPrice, 0 when OldPrice is 0
+}
+
+// WatchlistItem is a tracked item title with the latest cached price of a
+// listing whose title contains it
+type WatchlistItem struct {
+	ID          int
+	Title       string
+	TargetPrice float64
+	CreatedAt   time.Time
+	LatestPrice float64
+	HasPrice    bool // false when no cached listing matches the title
+}
+
+// UnderTarget reports whether the latest cached price is at or below the
+// target price
+func (w WatchlistItem) UnderTarget() bool {
+	return w.HasPrice && w.LatestPrice <= w.TargetPrice
+}
+
+// Favorite is a listing the user starred, as it was when last starred
+type Favorite struct {
+	Listing   APIListing
+	CreatedAt time.Time
+}
+
+// Alert records a watched item's price reaching its target
+type Alert struct {
+	ID          int
+	Title       string
+	Price       float64
+	TargetPrice float64
+	CreatedAt   time.Time
+	Read        bool
+}
+
+// SourceStat summarises the listings of one source
+type SourceStat struct {
+	Count    int     `json:"count"`
+	AvgPrice float64 `json:"avg_price"`
+	MinPrice float64 `json:"min_price"`
+	MaxPrice float64 `json:"max_price"`
 }
 
 type Listing struct {
-	ID        int
-	Source    string
-	URL       string
-	Title     string
-	Price     float64
-	Condition string
-	Timestamp time.Time
-	Metadata  string
-}
-
-// NewDatabase creates and initializes the database
-func NewDatabase() *Database {
+	ID        int       `json:"id"`
+	Source    string    `json:"source"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+	Condition string    `json:"condition"`
+	Timestamp time.Time `json:"timestamp"`
+	Metadata  string    `json:"metadata"`
+	Note      string    `json:"note,omitempty"` // only filled in by exports
+}
+
+// dbPathEnv names the environment variable that overrides the database path
+const dbPathEnv = "ARBFINDER_DB"
+
+// NewDatabase creates and initializes the database. The path is taken from
+// the ARBFINDER_DB environment variable if set, otherwise it defaults to
+// ~/.arbfinder_tui.db.
+func NewDatabase() (*Database, error) {
+	if path := os.Getenv(dbPathEnv); path != "" {
+		return NewDatabaseAt(path)
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to locate home directory: %w", err)
+	}
+
+	return NewDatabaseAt(filepath.Join(homeDir, ".arbfinder_tui.db"))
+}
+
+// DatabaseOptions tunes how SQLite connections are opened
+type DatabaseOptions struct {
+	// JournalMode is passed to PRAGMA journal_mode; empty keeps SQLite's
+	// default rollback journal
+	JournalMode string
+	// BusyTimeout is how long a connection waits on a lock before returning
+	// "database is locked"; zero keeps the driver default
+	BusyTimeout time.Duration
+}
+
+// DefaultDatabaseOptions uses WAL so the refresh writers don't block readers
+func DefaultDatabaseOptions() DatabaseOptions {
+	return DatabaseOptions{
+		JournalMode: "WAL",
+		BusyTimeout: 5 * time.Second,
 	}
+}
+
+// dsn appends the options as go-sqlite3 connection parameters. Pragmas set
+// this way apply to every pooled connection, not just the first one.
+func (o DatabaseOptions) dsn(dbPath string) string {
+	params := url.Values{}
+	if o.JournalMode != "" {
+		params.Set("_journal_mode", o.JournalMode)
+	}
+	if o.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	}
+	if len(params) == 0 {
+		return dbPath
+	}
+	return dbPath + "?" + params.Encode()
+}
+
+// NewDatabaseAt creates and initializes the database at path with the default
+// options. Use ":memory:" for an ephemeral database that lives only as long
+// as the returned handle.
+func NewDatabaseAt(dbPath string) (*Database, error) {
+	return NewDatabaseWithOptions(dbPath, DefaultDatabaseOptions())
+}
 
-	dbPath := filepath.Join(homeDir, ".arbfinder_tui.db")
-	db, err := sql.Open("sqlite3", dbPath)
+// NewDatabaseWithOptions creates and initializes the database at path
+func NewDatabaseWithOptions(dbPath string, opts DatabaseOptions) (*Database, error) {
+	db, err := sql.Open("sqlite3", opts.dsn(dbPath))
 	if err != nil {
-		panic(err)
-	}
-
-	// Create tables
-	if err := createTables(db); err != nil {
-		panic(err)
-	}
-
-	return &Database{db: db}
-}
-
-func createTables(db *sql.DB) error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS search_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			query TEXT NOT NULL,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			results INTEGER DEFAULT 0
-		)`,
-		`CREATE TABLE IF NOT EXISTS saved_configs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT UNIQUE NOT NULL,
-			config TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS price_history (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			item_title TEXT NOT NULL,
-			price REAL NOT NULL,
-			source TEXT NOT NULL,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			metadata TEXT
-		)`,
-		`CREATE TABLE IF NOT EXISTS cached_listings (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			source TEXT NOT NULL,
-			url TEXT UNIQUE NOT NULL,
-			title TEXT NOT NULL,
-			price REAL NOT NULL,
-			condition TEXT,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			metadata TEXT
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_search_history_timestamp ON search_history(timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_price_history_item ON price_history(item_title, timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_cached_listings_title ON cached_listings(title)`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
+		return nil, fmt.Errorf("failed to open database %s: %w", dbPath, err)
 	}
 
-	return nil
+	// Every connection to ":memory:" gets its own empty database, so pin the
+	// pool to a single connection
+	if dbPath == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %w", dbPath, err)
+	}
+
+	return &Database{db: db}, nil
 }
 
-// SaveSearchHistory saves a search query to history
+// SaveSearchHistory saves a search query to history. Repeating a query
+// (ignoring case and surrounding whitespace) bumps its count and refreshes
+// its timestamp and result count instead of adding a row.
 func (d *Database) SaveSearchHistory(query string, results int) error {
-	_, err := d.db.Exec(
-		"INSERT INTO search_history (query, results) VALUES (?, ?)",
-		query, results,
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Sub-second timestamps keep back-to-back searches in order
+	now := time.Now().UTC()
+	res, err := tx.Exec(
+		"UPDATE search_history SET query = ?, results = ?, timestamp = ?, count = count + 1 WHERE lower(trim(query)) = lower(trim(?))",
+		query, results, now, query,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if updated, err := res.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		if _, err := tx.Exec(
+			"INSERT INTO search_history (query, results, timestamp, count) VALUES (?, ?, ?, 1)",
+			query, results, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // GetSearchHistory retrieves recent search history
 func (d *Database) GetSearchHistory(limit int) ([]SearchHistory, error) {
 	rows, err := d.db.Query(
-		"SELECT id, query, timestamp, results FROM search_history ORDER BY timestamp DESC LIMIT ?",
+		"SELECT id, query, timestamp, results, count FROM search_history ORDER BY timestamp DESC LIMIT ?",
 		limit,
 	)
 	if err != nil {
@@ -136,10 +246,29 @@ func (d *Database) GetSearchHistory(limit int) ([]SearchHistory, error) {
 	}
 	defer rows.Close()
 
+	return scanSearchHistory(rows)
+}
+
+// GetFrequentQueries retrieves the most often repeated searches, breaking
+// ties by recency
+func (d *Database) GetFrequentQueries(limit int) ([]SearchHistory, error) {
+	rows, err := d.db.Query(
+		"SELECT id, query, timestamp, results, count FROM search_history ORDER BY count DESC, timestamp DESC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSearchHistory(rows)
+}
+
+func scanSearchHistory(rows *sql.Rows) ([]SearchHistory, error) {
 	var history []SearchHistory
 	for rows.Next() {
 		var h SearchHistory
-		if err := rows.Scan(&h.ID, &h.Query, &h.Timestamp, &h.Results); err != nil {
+		if err := rows.Scan(&h.ID, &h.Query, &h.Timestamp, &h.Results, &h.Count); err != nil {
 			return nil, err
 		}
 		history = append(history, h)
@@ -181,10 +310,34 @@ func (d *Database) LoadConfig(name string) (map[string]interface{}, error) {
 	return config, nil
 }
 
+// DeleteConfig removes a saved configuration
+func (d *Database) DeleteConfig(name string) error {
+	_, err := d.db.Exec("DELETE FROM saved_configs WHERE name = ?", name)
+	return err
+}
+
+// sessionConfigName is the saved_configs row holding the last session's
+// search choices. It is kept out of the saved configurations list.
+const sessionConfigName = "last_session"
+
+// SaveSession merges values into the last session's settings, so panes can
+// each remember their own choices without overwriting the others'
+func (d *Database) SaveSession(values map[string]interface{}) error {
+	session, err := d.LoadConfig(sessionConfigName)
+	if err != nil {
+		session = make(map[string]interface{}, len(values))
+	}
+	for k, v := range values {
+		session[k] = v
+	}
+	return d.SaveConfig(sessionConfigName, session)
+}
+
 // GetAllConfigs retrieves all saved configurations
 func (d *Database) GetAllConfigs() ([]SavedConfig, error) {
 	rows, err := d.db.Query(
-		"SELECT id, name, config, created_at FROM saved_configs ORDER BY created_at DESC",
+		"SELECT id, name, config, created_at FROM saved_configs WHERE name != ? ORDER BY created_at DESC",
+		sessionConfigName,
 	)
 	if err != nil {
 		return nil, err
@@ -219,10 +372,23 @@ func (d *Database) SavePriceHistory(title string, price float64, source string,
 
 // GetPriceHistory retrieves price history for an item
 func (d *Database) GetPriceHistory(title string, limit int) ([]PriceHistory, error) {
-	rows, err := d.db.Query(
-		"SELECT id, item_title, price, source, timestamp, metadata FROM price_history WHERE item_title LIKE ? ORDER BY timestamp DESC LIMIT ?",
-		"%"+title+"%", limit,
-	)
+	return d.GetPriceHistoryInRange(title, time.Time{}, limit)
+}
+
+// GetPriceHistoryInRange retrieves price history for an item recorded at or
+// after since, newest first. A zero since returns the whole history.
+func (d *Database) GetPriceHistoryInRange(title string, since time.Time, limit int) ([]PriceHistory, error) {
+	query := "SELECT id, item_title, price, source, timestamp, metadata FROM price_history WHERE item_title LIKE ?"
+	args := []interface{}{"%" + title + "%"}
+	if !since.IsZero() {
+		// Timestamps are stored in more than one text format, so compare
+		// through datetime() rather than as raw strings
+		query += " AND datetime(timestamp) >= datetime(?)"
+		args = append(args, since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	args = append(args, limit)
+
+	rows, err := d.db.Query(query+" ORDER BY timestamp DESC LIMIT ?", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -240,20 +406,400 @@ func (d *Database) GetPriceHistory(title string, limit int) ([]PriceHistory, err
 	return history, nil
 }
 
-// CacheListing saves a listing to the cache
+// DetectPriceDrops compares the two most recent prices of every tracked item
+// and returns those that fell by at least minDropPct percent, largest drop
+// first. Items with fewer than two data points are skipped.
+func (d *Database) DetectPriceDrops(minDropPct float64) ([]PriceDrop, error) {
+	rows, err := d.db.Query(`
+		WITH ranked AS (
+			SELECT item_title, price, source, timestamp,
+				ROW_NUMBER() OVER (
+					PARTITION BY item_title
+					ORDER BY datetime(timestamp) DESC, id DESC
+				) AS rn
+			FROM price_history
+		)
+		SELECT cur.item_title, cur.source, prev.price, cur.price,
+			(prev.price - cur.price) / prev.price * 100 AS drop_pct,
+			CAST(strftime('%s', cur.timestamp) AS INTEGER)
+		FROM ranked cur
+		JOIN ranked prev ON prev.item_title = cur.item_title AND prev.rn = 2
+		WHERE cur.rn = 1
+			AND prev.price > 0
+			AND (prev.price - cur.price) / prev.price * 100 >= ?
+		ORDER BY drop_pct DESC, cur.item_title`,
+		minDropPct,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drops []PriceDrop
+	for rows.Next() {
+		var drop PriceDrop
+		var unix int64
+		if err := rows.Scan(&drop.ItemTitle, &drop.Source, &drop.PreviousPrice, &drop.CurrentPrice, &drop.DropPct, &unix); err != nil {
+			return nil, err
+		}
+		drop.Timestamp = time.Unix(unix, 0)
+		drops = append(drops, drop)
+	}
+
+	return drops, rows.Err()
+}
+
+// GetTopMovers compares the first and last prices recorded for each item
+// within the last window (all history when zero) and returns the limit items
+// that moved most, by percentage and then by amount, in either direction.
+// Items with a single data point or an unchanged price are skipped.
+func (d *Database) GetTopMovers(window time.Duration, limit int) ([]Mover, error) {
+	where := ""
+	var args []interface{}
+	if window > 0 {
+		// Timestamps are stored in more than one text format, so compare
+		// through datetime() rather than as raw strings
+		where = "WHERE datetime(timestamp) >= datetime(?)"
+		args = append(args, time.Now().UTC().Add(-window).Format("2006-01-02 15:04:05"))
+	}
+	args = append(args, limit)
+
+	rows, err := d.db.Query(`
+		WITH windowed AS (
+			SELECT item_title, price,
+				ROW_NUMBER() OVER (
+					PARTITION BY item_title
+					ORDER BY datetime(timestamp) ASC, id ASC
+				) AS first_rn,
+				ROW_NUMBER() OVER (
+					PARTITION BY item_title
+					ORDER BY datetime(timestamp) DESC, id DESC
+				) AS last_rn
+			FROM price_history
+			`+where+`
+		),
+		moves AS (
+			SELECT oldest.item_title, oldest.price AS old_price, newest.price AS new_price,
+				newest.price - oldest.price AS delta,
+				CASE WHEN oldest.price > 0
+					THEN (newest.price - oldest.price) / oldest.price * 100
+					ELSE 0
+				END AS pct
+			FROM windowed oldest
+			JOIN windowed newest ON newest.item_title = oldest.item_title AND newest.last_rn = 1
+			WHERE oldest.first_rn = 1 AND newest.first_rn > 1
+		)
+		SELECT item_title, old_price, new_price, delta, pct
+		FROM moves
+		WHERE delta != 0
+		ORDER BY ABS(pct) DESC, ABS(delta) DESC, item_title
+		LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movers []Mover
+	for rows.Next() {
+		var m Mover
+		if err := rows.Scan(&m.ItemTitle, &m.OldPrice, &m.NewPrice, &m.Delta, &m.Pct); err != nil {
+			return nil, err
+		}
+		movers = append(movers, m)
+	}
+
+	return movers, rows.Err()
+}
+
+// AddToWatchlist starts tracking title, or updates the target price if it is
+// already tracked
+func (d *Database) AddToWatchlist(title string, targetPrice float64) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("watchlist title must not be empty")
+	}
+
+	_, err := d.db.Exec(
+		"INSERT INTO watchlist (title, target_price) VALUES (?, ?) ON CONFLICT(title) DO UPDATE SET target_price = excluded.target_price",
+		title, targetPrice,
+	)
+	return err
+}
+
+// RemoveFromWatchlist stops tracking title
+func (d *Database) RemoveFromWatchlist(title string) error {
+	_, err := d.db.Exec("DELETE FROM watchlist WHERE title = ?", title)
+	return err
+}
+
+// GetWatchlist retrieves every tracked item, newest first, along with the
+// most recently cached price of a matching listing
+func (d *Database) GetWatchlist() ([]WatchlistItem, error) {
+	rows, err := d.db.Query(`
+		SELECT w.id, w.title, w.target_price, w.created_at, (
+			SELECT c.price FROM cached_listings c
+			WHERE c.title LIKE '%' || w.title || '%'
+			ORDER BY datetime(c.timestamp) DESC, c.id DESC
+			LIMIT 1
+		)
+		FROM watchlist w
+		ORDER BY w.created_at DESC, w.id DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []WatchlistItem
+	for rows.Next() {
+		var item WatchlistItem
+		var latest sql.NullFloat64
+		if err := rows.Scan(&item.ID, &item.Title, &item.TargetPrice, &item.CreatedAt, &latest); err != nil {
+			return nil, err
+		}
+		item.LatestPrice, item.HasPrice = latest.Float64, latest.Valid
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// AddFavorite stores a snapshot of listing as a favorite, keyed by its URL.
+// Adding a listing that is already a favorite refreshes the snapshot and
+// keeps when it was first added.
+func (d *Database) AddFavorite(listing APIListing) error {
+	if strings.TrimSpace(listing.URL) == "" {
+		return fmt.Errorf("a favorite needs a listing URL")
+	}
+
+	snapshot, err := json.Marshal(listing)
+	if err != nil {
+		return fmt.Errorf("failed to encode favorite: %w", err)
+	}
+	_, err = d.db.Exec(
+		"INSERT INTO favorites (url, listing, created_at) VALUES (?, ?, ?) ON CONFLICT(url) DO UPDATE SET listing = excluded.listing",
+		listing.URL, string(snapshot), time.Now().UTC(),
+	)
+	return err
+}
+
+// RemoveFavorite deletes the favorite with the given URL, if there is one
+func (d *Database) RemoveFavorite(url string) error {
+	_, err := d.db.Exec("DELETE FROM favorites WHERE url = ?", url)
+	return err
+}
+
+// ToggleFavorite adds listing to the favorites, or removes it if its URL is
+// already a favorite, and reports whether it was added
+func (d *Database) ToggleFavorite(listing APIListing) (bool, error) {
+	var exists bool
+	if err := d.db.QueryRow(
+		"SELECT COUNT(*) > 0 FROM favorites WHERE url = ?", listing.URL,
+	).Scan(&exists); err != nil {
+		return false, err
+	}
+	if exists {
+		return false, d.RemoveFavorite(listing.URL)
+	}
+	return true, d.AddFavorite(listing)
+}
+
+// GetFavorites retrieves every favorite, most recently added first
+func (d *Database) GetFavorites() ([]Favorite, error) {
+	rows, err := d.db.Query("SELECT listing, created_at FROM favorites ORDER BY created_at DESC, rowid DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var favorites []Favorite
+	for rows.Next() {
+		var f Favorite
+		var snapshot string
+		if err := rows.Scan(&snapshot, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(snapshot), &f.Listing); err != nil {
+			return nil, fmt.Errorf("failed to decode favorite: %w", err)
+		}
+		favorites = append(favorites, f)
+	}
+
+	return favorites, rows.Err()
+}
+
+// RecordAlert stores an unread alert that the watched title reached price,
+// at or below its target
+func (d *Database) RecordAlert(title string, price, targetPrice float64) error {
+	_, err := d.db.Exec(
+		"INSERT INTO alerts (title, price, target_price, created_at) VALUES (?, ?, ?, ?)",
+		title, price, targetPrice, time.Now().UTC(),
+	)
+	return err
+}
+
+// GetUnreadAlerts retrieves the alerts not yet seen, newest first
+func (d *Database) GetUnreadAlerts() ([]Alert, error) {
+	rows, err := d.db.Query(
+		"SELECT id, title, price, target_price, created_at, read FROM alerts WHERE read = 0 ORDER BY created_at DESC, id DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(&a.ID, &a.Title, &a.Price, &a.TargetPrice, &a.CreatedAt, &a.Read); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, rows.Err()
+}
+
+// MarkAlertsRead marks every alert as seen
+func (d *Database) MarkAlertsRead() error {
+	_, err := d.db.Exec("UPDATE alerts SET read = 1 WHERE read = 0")
+	return err
+}
+
+// defaultCurrency is assumed for listings that don't specify one
+const defaultCurrency = "USD"
+
+// CacheListing saves a listing to the cache. A zero Timestamp records the
+// listing as cached now. Re-caching a listing updates it in place, keeping
+// its note.
 func (d *Database) CacheListing(listing Listing) error {
+	timestamp := listing.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
 	_, err := d.db.Exec(
-		"INSERT OR REPLACE INTO cached_listings (source, url, title, price, condition, metadata) VALUES (?, ?, ?, ?, ?, ?)",
-		listing.Source, listing.URL, listing.Title, listing.Price, listing.Condition, listing.Metadata,
+		`INSERT INTO cached_listings (source, url, title, price, currency, condition, timestamp, metadata) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET source = excluded.source, title = excluded.title, price = excluded.price,
+			currency = excluded.currency, condition = excluded.condition, timestamp = excluded.timestamp, metadata = excluded.metadata`,
+		listing.Source, listing.URL, listing.Title, listing.Price, valueOr(listing.Currency, defaultCurrency), listing.Condition, timestamp.UTC(), listing.Metadata,
 	)
 	return err
 }
 
+// errListingNotCached is returned by SetListingNote for a URL with no cached
+// listing to attach the note to
+var errListingNotCached = errors.New("listing is not cached")
+
+// SetListingNote attaches note to the cached listing with the given URL,
+// replacing any note it had. An empty note removes it.
+func (d *Database) SetListingNote(url, note string) error {
+	var value interface{}
+	if note = strings.TrimSpace(note); note != "" {
+		value = note
+	}
+
+	res, err := d.db.Exec("UPDATE cached_listings SET note = ? WHERE url = ?", value, url)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %s", errListingNotCached, url)
+	}
+	return nil
+}
+
+// GetListingNote returns the note on the cached listing with the given URL,
+// or "" if it has none or isn't cached
+func (d *Database) GetListingNote(url string) (string, error) {
+	var note sql.NullString
+	err := d.db.QueryRow("SELECT note FROM cached_listings WHERE url = ?", url).Scan(&note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return note.String, err
+}
+
+// GetListingNotes returns every note, keyed by listing URL
+func (d *Database) GetListingNotes() (map[string]string, error) {
+	rows, err := d.db.Query("SELECT url, note FROM cached_listings WHERE note IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := make(map[string]string)
+	for rows.Next() {
+		var url, note string
+		if err := rows.Scan(&url, &note); err != nil {
+			return nil, err
+		}
+		notes[url] = note
+	}
+	return notes, rows.Err()
+}
+
+// ListingQuery selects cached listings with the same controls the API offers
+// for live listings. Zero values leave a dimension unconstrained.
+type ListingQuery struct {
+	Query    string  // substring of the title
+	Source   string  // exact source name
+	MinPrice float64 // inclusive
+	MaxPrice float64 // inclusive
+	OrderBy  string  // "ts" (default), "price", or "title"; always descending like the API
+	Limit    int     // zero or less for no limit
+	Offset   int
+}
+
+// cachedListingOrder maps ListingQuery.OrderBy to an ORDER BY clause
+var cachedListingOrder = map[string]string{
+	"":      "datetime(timestamp) DESC, id DESC",
+	"ts":    "datetime(timestamp) DESC, id DESC",
+	"price": "price DESC, id DESC",
+	"title": "title DESC, id DESC",
+}
+
 // GetCachedListings retrieves cached listings
 func (d *Database) GetCachedListings(query string, limit int) ([]Listing, error) {
+	return d.QueryCachedListings(ListingQuery{Query: query, Limit: limit})
+}
+
+// QueryCachedListings retrieves a page of cached listings matching q
+func (d *Database) QueryCachedListings(q ListingQuery) ([]Listing, error) {
+	order, ok := cachedListingOrder[q.OrderBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported order %q", q.OrderBy)
+	}
+
+	where := []string{"title LIKE ?"}
+	args := []interface{}{"%" + q.Query + "%"}
+	if q.Source != "" {
+		where = append(where, "source = ?")
+		args = append(args, q.Source)
+	}
+	if q.MinPrice > 0 {
+		where = append(where, "price >= ?")
+		args = append(args, q.MinPrice)
+	}
+	if q.MaxPrice > 0 {
+		where = append(where, "price <= ?")
+		args = append(args, q.MaxPrice)
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit, q.Offset)
+
 	rows, err := d.db.Query(
-		"SELECT id, source, url, title, price, condition, timestamp, metadata FROM cached_listings WHERE title LIKE ? ORDER BY timestamp DESC LIMIT ?",
-		"%"+query+"%", limit,
+		"SELECT id, source, url, title, price, currency, condition, timestamp, metadata FROM cached_listings WHERE "+
+			strings.Join(where, " AND ")+" ORDER BY "+order+" LIMIT ? OFFSET ?",
+		args...,
 	)
 	if err != nil {
 		return nil, err
@@ -263,15 +809,122 @@ func (d *Database) GetCachedListings(query string, limit int) ([]Listing, error)
 	var listings []Listing
 	for rows.Next() {
 		var l Listing
-		if err := rows.Scan(&l.ID, &l.Source, &l.URL, &l.Title, &l.Price, &l.Condition, &l.Timestamp, &l.Metadata); err != nil {
+		if err := rows.Scan(&l.ID, &l.Source, &l.URL, &l.Title, &l.Price, &l.Currency, &l.Condition, &l.Timestamp, &l.Metadata); err != nil {
 			return nil, err
 		}
 		listings = append(listings, l)
 	}
 
+	return listings, rows.Err()
+}
+
+// FuzzyFindListings returns up to limit cached listings whose titles
+// loosely match query, best match first and newest first among equals
+func (d *Database) FuzzyFindListings(query string, limit int) ([]Listing, error) {
+	cached, err := d.QueryCachedListings(ListingQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		listing Listing
+		score   float64
+	}
+	var matches []scored
+	for _, l := range cached {
+		if score := fuzzyScore(query, l.Title); score >= fuzzyCutoff {
+			matches = append(matches, scored{l, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	listings := make([]Listing, len(matches))
+	for i, m := range matches {
+		listings[i] = m.listing
+	}
 	return listings, nil
 }
 
+// PruneCachedListings deletes cached listings cached more than olderThan ago
+// and returns how many were removed. Listings with a note are kept.
+func (d *Database) PruneCachedListings(olderThan time.Duration) (int, error) {
+	// Timestamps are stored in more than one text format, so compare through
+	// datetime() rather than as raw strings
+	cutoff := time.Now().UTC().Add(-olderThan).Format("2006-01-02 15:04:05")
+	res, err := d.db.Exec(
+		"DELETE FROM cached_listings WHERE datetime(timestamp) < datetime(?) AND note IS NULL",
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	pruned, err := res.RowsAffected()
+	return int(pruned), err
+}
+
+// ClearOptions picks what ClearData deletes besides the search history,
+// price history and cached listings
+type ClearOptions struct {
+	// KeepConfigs keeps the saved configurations and the last session
+	KeepConfigs bool
+}
+
+// ClearData deletes the locally collected data in a single transaction,
+// so either every table is emptied or none is. The watchlist, its alerts and
+// the favorites are kept.
+func (d *Database) ClearData(opts ClearOptions) error {
+	tables := []string{"search_history", "price_history", "cached_listings"}
+	if !opts.KeepConfigs {
+		tables = append(tables, "saved_configs")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range tables {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Size returns how many bytes the database takes up, including pages freed
+// by deletes that only Vacuum gives back
+func (d *Database) Size() (int64, error) {
+	var pages, pageSize int64
+	if err := d.db.QueryRow("PRAGMA page_count").Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := d.db.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// Vacuum rebuilds the database file without its free pages, shrinking it
+// after pruning or clearing. SQLite refuses to VACUUM inside a transaction,
+// so it runs straight on the pool. In WAL mode the log is then checkpointed
+// and truncated, or the rebuilt pages would only be written to it.
+func (d *Database) Vacuum() error {
+	if _, err := d.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	if _, err := d.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint database: %w", err)
+	}
+	return nil
+}
+
 // GetStats returns database statistics
 func (d *Database) GetStats() (map[string]int, error) {
 	stats := make(map[string]int)
@@ -284,9 +937,10 @@ func (d *Database) GetStats() (map[string]int, error) {
 	}
 	stats["total_searches"] = totalSearches
 
-	// Count saved configs
+	// Count saved configs, leaving out the hidden session row as
+	// GetAllConfigs does
 	var savedConfigs int
-	err = d.db.QueryRow("SELECT COUNT(*) FROM saved_configs").Scan(&savedConfigs)
+	err = d.db.QueryRow("SELECT COUNT(*) FROM saved_configs WHERE name != ?", sessionConfigName).Scan(&savedConfigs)
 	if err != nil {
 		return nil, err
 	}
@@ -311,7 +965,37 @@ func (d *Database) GetStats() (map[string]int, error) {
 	return stats, nil
 }
 
-// Close closes the database connection
+// GetStatsBySource returns the count and price range of cached listings for
+// each source
+func (d *Database) GetStatsBySource() (map[string]SourceStat, error) {
+	rows, err := d.db.Query(`
+		SELECT source, COUNT(*), AVG(price), MIN(price), MAX(price)
+		FROM cached_listings
+		GROUP BY source`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats by source: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]SourceStat)
+	for rows.Next() {
+		var source string
+		var s SourceStat
+		if err := rows.Scan(&source, &s.Count, &s.AvgPrice, &s.MinPrice, &s.MaxPrice); err != nil {
+			return nil, fmt.Errorf("failed to scan source stats: %w", err)
+		}
+		stats[source] = s
+	}
+
+	return stats, rows.Err()
+}
+
+// Close closes the database connection. Only the first call does anything,
+// so teardown paths can each close it; later calls return nil.
 func (d *Database) Close() error {
-	return d.db.Close()
+	var err error
+	d.closeOnce.Do(func() {
+		err = d.db.Close()
+	})
+	return err
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// exportVersion is bumped whenever the export envelope changes shape
+const exportVersion = 1
+
+// DatabaseExport is the versioned JSON envelope written by ExportJSON
+type DatabaseExport struct {
+	Version        int             `json:"version"`
+	ExportedAt     time.Time       `json:"exported_at"`
+	SearchHistory  []SearchHistory `json:"search_history"`
+	SavedConfigs   []SavedConfig   `json:"saved_configs"`
+	PriceHistory   []PriceHistory  `json:"price_history"`
+	CachedListings []Listing       `json:"cached_listings"`
+}
+
+// ExportJSON writes every table to w as a single JSON document
+func (d *Database) ExportJSON(w io.Writer) error {
+	export := DatabaseExport{
+		Version:    exportVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if export.SearchHistory, err = exportSearchHistory(tx); err != nil {
+		return fmt.Errorf("failed to export search history: %w", err)
+	}
+	if export.SavedConfigs, err = exportSavedConfigs(tx); err != nil {
+		return fmt.Errorf("failed to export saved configs: %w", err)
+	}
+	if export.PriceHistory, err = exportPriceHistory(tx); err != nil {
+		return fmt.Errorf("failed to export price history: %w", err)
+	}
+	if export.CachedListings, err = exportCachedListings(tx); err != nil {
+		return fmt.Errorf("failed to export cached listings: %w", err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+func exportSearchHistory(tx *sql.Tx) ([]SearchHistory, error) {
+	rows, err := tx.Query("SELECT id, query, timestamp, results, count FROM search_history ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSearchHistory(rows)
+}
+
+func exportSavedConfigs(tx *sql.Tx) ([]SavedConfig, error) {
+	rows, err := tx.Query("SELECT id, name, config, created_at FROM saved_configs ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var configs []SavedConfig
+	for rows.Next() {
+		var c SavedConfig
+		if err := rows.Scan(&c.ID, &c.Name, &c.Config, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
+	}
+
+	return configs, rows.Err()
+}
+
+func exportPriceHistory(tx *sql.Tx) ([]PriceHistory, error) {
+	rows, err := tx.Query(
+		"SELECT id, item_title, price, source, timestamp, COALESCE(metadata, '') FROM price_history ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []PriceHistory
+	for rows.Next() {
+		var h PriceHistory
+		if err := rows.Scan(&h.ID, &h.ItemTitle, &h.Price, &h.Source, &h.Timestamp, &h.Metadata); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
+
+func exportCachedListings(tx *sql.Tx) ([]Listing, error) {
+	rows, err := tx.Query(
+		"SELECT id, source, url, title, price, currency, COALESCE(condition, ''), timestamp, COALESCE(metadata, ''), COALESCE(note, '') FROM cached_listings ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var listings []Listing
+	for rows.Next() {
+		var l Listing
+		if err := rows.Scan(&l.ID, &l.Source, &l.URL, &l.Title, &l.Price, &l.Currency, &l.Condition, &l.Timestamp, &l.Metadata, &l.Note); err != nil {
+			return nil, err
+		}
+		listings = append(listings, l)
+	}
+
+	return listings, rows.Err()
+}
+
+// ImportJSON merges an export produced by ExportJSON into the database.
+// Row IDs are reassigned. Rows that collide on a unique key are merged:
+// searches keep the higher count and later timestamp, configs and cached
+// listings are overwritten (though a listing keeps its note when the backup
+// has none for it), and identical price points are skipped.
+func (d *Database) ImportJSON(r io.Reader) error {
+	var export DatabaseExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return fmt.Errorf("failed to decode export: %w", err)
+	}
+	if export.Version < 1 || export.Version > exportVersion {
+		return fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, h := range export.SearchHistory {
+		res, err := tx.Exec(
+			`UPDATE search_history SET
+				count = MAX(count, ?),
+				results = CASE WHEN timestamp < ? THEN ? ELSE results END,
+				timestamp = MAX(timestamp, ?)
+			WHERE lower(trim(query)) = lower(trim(?))`,
+			h.Count, h.Timestamp, h.Results, h.Timestamp, h.Query,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to import search history: %w", err)
+		}
+		if updated, err := res.RowsAffected(); err != nil {
+			return err
+		} else if updated == 0 {
+			if _, err := tx.Exec(
+				"INSERT INTO search_history (query, results, timestamp, count) VALUES (?, ?, ?, ?)",
+				h.Query, h.Results, h.Timestamp, h.Count,
+			); err != nil {
+				return fmt.Errorf("failed to import search history: %w", err)
+			}
+		}
+	}
+
+	for _, c := range export.SavedConfigs {
+		if _, err := tx.Exec(
+			"INSERT OR REPLACE INTO saved_configs (name, config, created_at) VALUES (?, ?, ?)",
+			c.Name, c.Config, c.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("failed to import saved config %s: %w", c.Name, err)
+		}
+	}
+
+	for _, h := range export.PriceHistory {
+		if _, err := tx.Exec(
+			`INSERT INTO price_history (item_title, price, source, timestamp, metadata)
+			SELECT ?, ?, ?, ?, ?
+			WHERE NOT EXISTS (
+				SELECT 1 FROM price_history
+				WHERE item_title = ? AND price = ? AND source = ? AND datetime(timestamp) = datetime(?)
+			)`,
+			h.ItemTitle, h.Price, h.Source, h.Timestamp, h.Metadata,
+			h.ItemTitle, h.Price, h.Source, h.Timestamp,
+		); err != nil {
+			return fmt.Errorf("failed to import price history: %w", err)
+		}
+	}
+
+	for _, l := range export.CachedListings {
+		var note interface{}
+		if l.Note != "" {
+			note = l.Note
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO cached_listings (source, url, title, price, currency, condition, timestamp, metadata, note) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(url) DO UPDATE SET source = excluded.source, title = excluded.title, price = excluded.price,
+				currency = excluded.currency, condition = excluded.condition, timestamp = excluded.timestamp, metadata = excluded.metadata,
+				note = COALESCE(excluded.note, cached_listings.note)`,
+			l.Source, l.URL, l.Title, l.Price, valueOr(l.Currency, defaultCurrency), l.Condition, l.Timestamp, l.Metadata, note,
+		); err != nil {
+			return fmt.Errorf("failed to import cached listing %s: %w", l.URL, err)
+		}
+	}
+
+	return tx.Commit()
+}
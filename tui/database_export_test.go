@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedExportData fills db with a row in every table
+func seedExportData(t *testing.T, db *Database) {
+	t.Helper()
+
+	for _, q := range []string{"gpu", "cpu", "gpu"} {
+		if err := db.SaveSearchHistory(q, 3); err != nil {
+			t.Fatalf("Failed to save search history: %v", err)
+		}
+	}
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if err := db.SavePriceHistory("RTX 3060", 299.99, "govdeals", map[string]interface{}{"seller": "test"}); err != nil {
+		t.Fatalf("Failed to save price history: %v", err)
+	}
+	if err := db.CacheListing(Listing{
+		Source:    "govdeals",
+		URL:       "https://example.com/1",
+		Title:     "RTX 3060",
+		Price:     299.99,
+		Condition: "used",
+		Timestamp: time.Now(),
+		Metadata:  `{"seller": "test"}`,
+	}); err != nil {
+		t.Fatalf("Failed to cache listing: %v", err)
+	}
+	if err := db.SetListingNote("https://example.com/1", "seller looks sketchy"); err != nil {
+		t.Fatalf("Failed to set listing note: %v", err)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer source.Close()
+	seedExportData(t, source)
+
+	var buf bytes.Buffer
+	if err := source.ExportJSON(&buf); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	target, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer target.Close()
+
+	// Importing twice must not duplicate anything
+	exported := buf.String()
+	for i := 0; i < 2; i++ {
+		if err := target.ImportJSON(strings.NewReader(exported)); err != nil {
+			t.Fatalf("Failed to import (pass %d): %v", i+1, err)
+		}
+	}
+
+	want, err := source.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get source stats: %v", err)
+	}
+	got, err := target.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get target stats: %v", err)
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("Expected %s to be %d, got %d", key, count, got[key])
+		}
+	}
+
+	history, err := target.GetFrequentQueries(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) == 0 || history[0].Query != "gpu" || history[0].Count != 2 {
+		t.Errorf("Expected 'gpu' with count 2 first, got %+v", history)
+	}
+
+	config, err := target.LoadConfig("prod")
+	if err != nil {
+		t.Fatalf("Failed to load imported config: %v", err)
+	}
+	if config["api_url"] != "https://api.example.com" {
+		t.Errorf("Expected api_url 'https://api.example.com', got '%v'", config["api_url"])
+	}
+
+	if note, _ := target.GetListingNote("https://example.com/1"); note != "seller looks sketchy" {
+		t.Errorf("Expected the listing note to be imported, got '%s'", note)
+	}
+}
+
+func TestImportKeepsExistingNotes(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	if err := db.CacheListing(Listing{Source: "ebay", URL: "https://example.com/2", Title: "GTX 1080", Price: 150}); err != nil {
+		t.Fatalf("Failed to cache listing: %v", err)
+	}
+
+	// A backup taken before the note was added
+	var buf bytes.Buffer
+	if err := db.ExportJSON(&buf); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+	if strings.Contains(buf.String(), `"note"`) {
+		t.Errorf("Expected no note in the export of an unnoted listing, got:\n%s", buf.String())
+	}
+
+	if err := db.SetListingNote("https://example.com/2", "fan rattles"); err != nil {
+		t.Fatalf("Failed to set listing note: %v", err)
+	}
+	if err := db.ImportJSON(&buf); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if note, _ := db.GetListingNote("https://example.com/2"); note != "fan rattles" {
+		t.Errorf("Expected the import to keep the note, got '%s'", note)
+	}
+}
+
+func TestImportRejectsUnknownVersion(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.ImportJSON(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("Expected an error for an unsupported export version")
+	}
+	if err := db.ImportJSON(strings.NewReader(`not json`)); err == nil {
+		t.Error("Expected an error for malformed JSON")
+	}
+}
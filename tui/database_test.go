@@ -1,22 +1,21 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestDatabaseCreation(t *testing.T) {
-	// Create a temporary database
-	tmpDB := "/tmp/test_arbfinder.db"
-	defer os.Remove(tmpDB)
-
-	// Override the database path for testing
-	os.Setenv("HOME", "/tmp")
-	
-	db := NewDatabase()
-	if db == nil {
-		t.Fatal("Failed to create database")
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
 	}
 	defer db.Close()
 
@@ -31,14 +30,186 @@ func TestDatabaseCreation(t *testing.T) {
 	}
 }
 
+func TestDatabaseCreationError(t *testing.T) {
+	db, err := NewDatabaseAt("/nonexistent/arbfinder/test.db")
+	if err == nil {
+		db.Close()
+		t.Fatal("Expected an error for an unwritable database path")
+	}
+}
+
+func TestDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+
+	// ARBFINDER_DB takes precedence over the home directory
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv(dbPathEnv, filepath.Join(dir, "custom.db"))
+
+	db, err := NewDatabase()
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.SaveSearchHistory("gpu", 1); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+	db.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "custom.db")); err != nil {
+		t.Fatalf("Expected database at ARBFINDER_DB path: %v", err)
+	}
+
+	// Reopening the file keeps its data
+	db, err = NewDatabaseAt(filepath.Join(dir, "custom.db"))
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	history, err := db.GetSearchHistory(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) != 1 || history[0].Query != "gpu" {
+		t.Errorf("Expected persisted 'gpu' search, got %+v", history)
+	}
+}
+
+func TestDatabaseInMemoryIsolated(t *testing.T) {
+	first, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer first.Close()
+
+	second, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer second.Close()
+
+	if err := first.SaveSearchHistory("gpu", 1); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+
+	history, err := second.GetSearchHistory(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected separate in-memory databases, got %d entries", len(history))
+	}
+}
+
+func TestDatabaseCloseTwice(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Failed to close database: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("Expected a second Close to return nil, got %v", err)
+	}
+
+	if err := db.SaveSearchHistory("gpu", 1); err == nil {
+		t.Error("Expected the database to stay closed")
+	}
+}
+
+func TestDatabaseJournalMode(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		opts     DatabaseOptions
+		expected string
+	}{
+		{"default", DefaultDatabaseOptions(), "wal"},
+		{"rollback", DatabaseOptions{}, "delete"},
+	}
+
+	for _, tt := range tests {
+		db, err := NewDatabaseWithOptions(filepath.Join(dir, tt.name+".db"), tt.opts)
+		if err != nil {
+			t.Fatalf("Failed to create %s database: %v", tt.name, err)
+		}
+
+		var mode string
+		if err := db.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+			t.Fatalf("Failed to read journal mode: %v", err)
+		}
+		if mode != tt.expected {
+			t.Errorf("Expected %s journal mode '%s', got '%s'", tt.name, tt.expected, mode)
+		}
+		db.Close()
+	}
+}
+
+func TestDatabaseConcurrentAccess(t *testing.T) {
+	db, err := NewDatabaseAt(filepath.Join(t.TempDir(), "concurrent.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	const workers, iterations = 4, 25
+	errs := make(chan error, workers*iterations*4)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				errs <- db.CacheListing(Listing{
+					Source:    "govdeals",
+					URL:       fmt.Sprintf("https://example.com/%d/%d", w, i),
+					Title:     "Dell Monitor",
+					Price:     float64(i),
+					Timestamp: time.Now(),
+				})
+				errs <- db.SaveSearchHistory(fmt.Sprintf("query %d", i), i)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				_, err := db.GetStats()
+				errs <- err
+				_, err = db.GetCachedListings("Dell", 10)
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent access failed: %v", err)
+		}
+	}
+
+	listings, err := db.GetCachedListings("Dell", workers*iterations)
+	if err != nil {
+		t.Fatalf("Failed to get cached listings: %v", err)
+	}
+	if len(listings) != workers*iterations {
+		t.Errorf("Expected %d cached listings, got %d", workers*iterations, len(listings))
+	}
+}
+
 func TestSearchHistory(t *testing.T) {
-	os.Setenv("HOME", "/tmp")
-	db := NewDatabase()
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
 	defer db.Close()
-	defer os.Remove("/tmp/.arbfinder_tui.db")
 
 	// Save a search
-	err := db.SaveSearchHistory("test query", 5)
+	err = db.SaveSearchHistory("test query", 5)
 	if err != nil {
 		t.Fatalf("Failed to save search history: %v", err)
 	}
@@ -63,10 +234,11 @@ func TestSearchHistory(t *testing.T) {
 }
 
 func TestConfigManagement(t *testing.T) {
-	os.Setenv("HOME", "/tmp")
-	db := NewDatabase()
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
 	defer db.Close()
-	defer os.Remove("/tmp/.arbfinder_tui.db")
 
 	// Save a config
 	config := map[string]interface{}{
@@ -75,7 +247,7 @@ func TestConfigManagement(t *testing.T) {
 		"providers":    []string{"shopgoodwill", "govdeals"},
 	}
 
-	err := db.SaveConfig("test_config", config)
+	err = db.SaveConfig("test_config", config)
 	if err != nil {
 		t.Fatalf("Failed to save config: %v", err)
 	}
@@ -106,10 +278,11 @@ func TestConfigManagement(t *testing.T) {
 }
 
 func TestPriceHistory(t *testing.T) {
-	os.Setenv("HOME", "/tmp")
-	db := NewDatabase()
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
 	defer db.Close()
-	defer os.Remove("/tmp/.arbfinder_tui.db")
 
 	// Save price history
 	metadata := map[string]interface{}{
@@ -117,7 +290,7 @@ func TestPriceHistory(t *testing.T) {
 		"seller":    "test_seller",
 	}
 
-	err := db.SavePriceHistory("RTX 3060", 299.99, "shopgoodwill", metadata)
+	err = db.SavePriceHistory("RTX 3060", 299.99, "shopgoodwill", metadata)
 	if err != nil {
 		t.Fatalf("Failed to save price history: %v", err)
 	}
@@ -142,10 +315,11 @@ func TestPriceHistory(t *testing.T) {
 }
 
 func TestCachedListings(t *testing.T) {
-	os.Setenv("HOME", "/tmp")
-	db := NewDatabase()
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
 	defer db.Close()
-	defer os.Remove("/tmp/.arbfinder_tui.db")
 
 	// Cache a listing
 	listing := Listing{
@@ -158,7 +332,7 @@ func TestCachedListings(t *testing.T) {
 		Metadata:  `{"seller": "test"}`,
 	}
 
-	err := db.CacheListing(listing)
+	err = db.CacheListing(listing)
 	if err != nil {
 		t.Fatalf("Failed to cache listing: %v", err)
 	}
@@ -181,3 +355,882 @@ func TestCachedListings(t *testing.T) {
 		t.Errorf("Expected price 299.99, got %f", listings[0].Price)
 	}
 }
+
+func TestSearchHistoryCount(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// First insert
+	if err := db.SaveSearchHistory("RTX 3060", 5); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+
+	history, err := db.GetSearchHistory(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) != 1 || history[0].Count != 1 {
+		t.Fatalf("Expected 1 entry with count 1, got %+v", history)
+	}
+
+	// Repeats (ignoring case and whitespace) increment instead of inserting
+	if err := db.SaveSearchHistory(" rtx 3060 ", 8); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+	if err := db.SaveSearchHistory("thinkpad", 2); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+	if err := db.SaveSearchHistory("RTX 3060", 9); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+
+	frequent, err := db.GetFrequentQueries(10)
+	if err != nil {
+		t.Fatalf("Failed to get frequent queries: %v", err)
+	}
+	if len(frequent) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(frequent))
+	}
+	if frequent[0].Query != "RTX 3060" || frequent[0].Count != 3 || frequent[0].Results != 9 {
+		t.Errorf("Expected 'RTX 3060' with count 3 and 9 results, got %+v", frequent[0])
+	}
+
+	stats, err := db.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["total_searches"] != 2 {
+		t.Errorf("Expected 2 distinct searches, got %d", stats["total_searches"])
+	}
+}
+
+func TestSearchHistoryCountMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	// Create a database with the original schema and duplicate rows
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	statements := []string{
+		`CREATE TABLE search_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			query TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			results INTEGER DEFAULT 0
+		)`,
+		`INSERT INTO search_history (query, results) VALUES ('gpu', 1), ('GPU', 2), ('cpu', 3), ('gpu ', 4)`,
+	}
+	for _, stmt := range statements {
+		if _, err := raw.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed old schema: %v", err)
+		}
+	}
+	raw.Close()
+
+	db, err := NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	history, err := db.GetFrequentQueries(10)
+	if err != nil {
+		t.Fatalf("Failed to get frequent queries: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected duplicates to collapse into 2 entries, got %d", len(history))
+	}
+	if history[0].Count != 3 || history[0].Results != 4 {
+		t.Errorf("Expected gpu with count 3 and latest results 4, got %+v", history[0])
+	}
+	if history[1].Query != "cpu" || history[1].Count != 1 {
+		t.Errorf("Expected cpu with count 1, got %+v", history[1])
+	}
+}
+
+func TestPruneCachedListings(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	rows := []struct {
+		url       string
+		timestamp interface{}
+	}{
+		// SQLite's CURRENT_TIMESTAMP format
+		{"https://example.com/old", now.Add(-40 * 24 * time.Hour).Format("2006-01-02 15:04:05")},
+		{"https://example.com/fresh", now.Add(-2 * 24 * time.Hour).Format("2006-01-02 15:04:05")},
+		// The driver's time.Time format, as written by imports
+		{"https://example.com/old-driver", now.Add(-31 * 24 * time.Hour)},
+		{"https://example.com/fresh-driver", now.Add(-time.Hour)},
+	}
+	for _, r := range rows {
+		if _, err := db.db.Exec(
+			"INSERT INTO cached_listings (source, url, title, price, condition, timestamp, metadata) VALUES ('govdeals', ?, 'Monitor', 10, 'used', ?, '{}')",
+			r.url, r.timestamp,
+		); err != nil {
+			t.Fatalf("Failed to seed cached listing: %v", err)
+		}
+	}
+
+	pruned, err := db.PruneCachedListings(30 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to prune cached listings: %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("Expected 2 listings pruned, got %d", pruned)
+	}
+
+	listings, err := db.GetCachedListings("", 10)
+	if err != nil {
+		t.Fatalf("Failed to get cached listings: %v", err)
+	}
+	for _, l := range listings {
+		if !strings.Contains(l.URL, "fresh") {
+			t.Errorf("Expected only fresh listings to remain, found %s", l.URL)
+		}
+	}
+	if len(listings) != 2 {
+		t.Errorf("Expected 2 listings to remain, got %d", len(listings))
+	}
+
+	// Nothing left to prune
+	if pruned, err := db.PruneCachedListings(30 * 24 * time.Hour); err != nil || pruned != 0 {
+		t.Errorf("Expected nothing pruned on second pass, got %d (%v)", pruned, err)
+	}
+}
+
+func TestGetPriceHistoryInRange(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	rows := []struct {
+		title     string
+		price     float64
+		timestamp interface{}
+	}{
+		// SQLite's CURRENT_TIMESTAMP format
+		{"RTX 3060", 300, now.Add(-40 * 24 * time.Hour).Format("2006-01-02 15:04:05")},
+		{"RTX 3060", 280, now.Add(-10 * 24 * time.Hour).Format("2006-01-02 15:04:05")},
+		{"RTX 3060", 260, now.Add(-3 * 24 * time.Hour).Format("2006-01-02 15:04:05")},
+		// The driver's time.Time format
+		{"RTX 3060", 250, now.Add(-2 * time.Hour)},
+		{"Monitor", 90, now.Add(-time.Hour)},
+	}
+	for _, r := range rows {
+		if _, err := db.db.Exec(
+			"INSERT INTO price_history (item_title, price, source, timestamp, metadata) VALUES (?, ?, 'ebay', ?, '{}')",
+			r.title, r.price, r.timestamp,
+		); err != nil {
+			t.Fatalf("Failed to seed price history: %v", err)
+		}
+	}
+
+	tests := []struct {
+		title string
+		since time.Time
+		want  int
+	}{
+		{"", now.Add(-24 * time.Hour), 2},
+		{"", now.Add(-7 * 24 * time.Hour), 3},
+		{"", now.Add(-30 * 24 * time.Hour), 4},
+		{"", time.Time{}, 5},
+		{"RTX", now.Add(-24 * time.Hour), 1},
+		{"RTX", now.Add(-30 * 24 * time.Hour), 3},
+		{"", now.Add(time.Hour), 0},
+	}
+	for _, tt := range tests {
+		history, err := db.GetPriceHistoryInRange(tt.title, tt.since, 100)
+		if err != nil {
+			t.Fatalf("Failed to get price history: %v", err)
+		}
+		if len(history) != tt.want {
+			t.Errorf("GetPriceHistoryInRange(%q, %s ago): expected %d entries, got %d", tt.title, now.Sub(tt.since).Round(time.Hour), tt.want, len(history))
+		}
+		for _, h := range history {
+			if !tt.since.IsZero() && h.Timestamp.Before(tt.since.Add(-time.Second)) {
+				t.Errorf("Expected entries since %s, got one from %s", tt.since, h.Timestamp)
+			}
+		}
+	}
+
+	// The limit still applies within the window
+	if history, err := db.GetPriceHistoryInRange("", now.Add(-30*24*time.Hour), 2); err != nil || len(history) != 2 {
+		t.Errorf("Expected the limit of 2 to apply, got %d entries (%v)", len(history), err)
+	}
+}
+
+func TestDetectPriceDrops(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	base := time.Now().UTC().Add(-24 * time.Hour)
+	history := []struct {
+		title string
+		price float64
+		age   time.Duration
+	}{
+		{"RTX 3060", 300, 3 * time.Hour},
+		{"RTX 3060", 240, time.Hour}, // 20% drop
+		{"ThinkPad", 100, 2 * time.Hour},
+		{"ThinkPad", 95, time.Hour}, // 5% drop, below threshold
+		{"Monitor", 50, 0},          // single data point
+		{"iPad", 200, 2 * time.Hour},
+		{"iPad", 250, time.Hour}, // price rise
+		{"Switch", 150, 3 * time.Hour},
+		{"Switch", 400, 2 * time.Hour},
+		{"Switch", 200, time.Hour}, // 50% drop from the prior price
+	}
+	for _, h := range history {
+		if _, err := db.db.Exec(
+			"INSERT INTO price_history (item_title, price, source, timestamp, metadata) VALUES (?, ?, 'govdeals', ?, '{}')",
+			h.title, h.price, base.Add(-h.age).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatalf("Failed to seed price history: %v", err)
+		}
+	}
+
+	drops, err := db.DetectPriceDrops(10)
+	if err != nil {
+		t.Fatalf("Failed to detect price drops: %v", err)
+	}
+	if len(drops) != 2 {
+		t.Fatalf("Expected 2 price drops, got %d: %+v", len(drops), drops)
+	}
+
+	if drops[0].ItemTitle != "Switch" || drops[0].PreviousPrice != 400 || drops[0].CurrentPrice != 200 {
+		t.Errorf("Expected Switch 400 → 200 first, got %+v", drops[0])
+	}
+	if drops[0].DropPct != 50 {
+		t.Errorf("Expected a 50%% drop, got %.2f", drops[0].DropPct)
+	}
+	if drops[1].ItemTitle != "RTX 3060" || drops[1].DropPct != 20 {
+		t.Errorf("Expected RTX 3060 with a 20%% drop second, got %+v", drops[1])
+	}
+
+	// A lower threshold picks up the smaller drop too
+	drops, err = db.DetectPriceDrops(5)
+	if err != nil {
+		t.Fatalf("Failed to detect price drops: %v", err)
+	}
+	if len(drops) != 3 {
+		t.Errorf("Expected 3 price drops at 5%%, got %d", len(drops))
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.AddToWatchlist("RTX 3060", 250); err != nil {
+		t.Fatalf("Failed to add to watchlist: %v", err)
+	}
+	if err := db.AddToWatchlist("ThinkPad X1", 400); err != nil {
+		t.Fatalf("Failed to add to watchlist: %v", err)
+	}
+	// Re-adding updates the target instead of duplicating
+	if err := db.AddToWatchlist("RTX 3060", 275); err != nil {
+		t.Fatalf("Failed to update watchlist: %v", err)
+	}
+	if err := db.AddToWatchlist("  ", 10); err == nil {
+		t.Error("Expected an error for an empty title")
+	}
+
+	items, err := db.GetWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to get watchlist: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 watchlist items, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.Title == "RTX 3060" && item.TargetPrice != 275 {
+			t.Errorf("Expected updated target 275, got %.2f", item.TargetPrice)
+		}
+		if item.HasPrice {
+			t.Errorf("Expected no cached price for '%s'", item.Title)
+		}
+	}
+
+	if err := db.RemoveFromWatchlist("ThinkPad X1"); err != nil {
+		t.Fatalf("Failed to remove from watchlist: %v", err)
+	}
+	items, err = db.GetWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to get watchlist: %v", err)
+	}
+	if len(items) != 1 || items[0].Title != "RTX 3060" {
+		t.Errorf("Expected only 'RTX 3060' to remain, got %+v", items)
+	}
+}
+
+func TestWatchlistUnderTarget(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	listings := []struct {
+		url   string
+		title string
+		price float64
+		age   time.Duration
+	}{
+		{"https://example.com/1", "EVGA RTX 3060 12GB", 320, 2 * time.Hour},
+		{"https://example.com/2", "MSI rtx 3060 Ventus", 240, time.Hour},
+		{"https://example.com/3", "ThinkPad X1 Carbon", 500, time.Hour},
+	}
+	for _, l := range listings {
+		if _, err := db.db.Exec(
+			"INSERT INTO cached_listings (source, url, title, price, condition, timestamp, metadata) VALUES ('govdeals', ?, ?, ?, 'used', ?, '{}')",
+			l.url, l.title, l.price, time.Now().UTC().Add(-l.age).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatalf("Failed to seed cached listing: %v", err)
+		}
+	}
+
+	targets := map[string]float64{"RTX 3060": 250, "ThinkPad X1": 400, "Steam Deck": 300}
+	for title, target := range targets {
+		if err := db.AddToWatchlist(title, target); err != nil {
+			t.Fatalf("Failed to add to watchlist: %v", err)
+		}
+	}
+
+	items, err := db.GetWatchlist()
+	if err != nil {
+		t.Fatalf("Failed to get watchlist: %v", err)
+	}
+
+	expected := map[string]struct {
+		latest float64
+		has    bool
+		under  bool
+	}{
+		"RTX 3060":    {240, true, true}, // latest match, case-insensitive
+		"ThinkPad X1": {500, true, false},
+		"Steam Deck":  {0, false, false},
+	}
+	for _, item := range items {
+		want := expected[item.Title]
+		if item.HasPrice != want.has || item.LatestPrice != want.latest {
+			t.Errorf("Expected '%s' latest price %.2f (found %v), got %.2f (found %v)",
+				item.Title, want.latest, want.has, item.LatestPrice, item.HasPrice)
+		}
+		if item.UnderTarget() != want.under {
+			t.Errorf("Expected '%s' under target %v, got %v", item.Title, want.under, item.UnderTarget())
+		}
+	}
+
+	// Exactly on target counts as under
+	if !(WatchlistItem{TargetPrice: 100, LatestPrice: 100, HasPrice: true}).UnderTarget() {
+		t.Error("Expected a price equal to the target to be under target")
+	}
+}
+
+func TestCachedListingCurrency(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	listings := []Listing{
+		{Source: "ebay", URL: "https://example.com/eur", Title: "Lenovo ThinkPad", Price: 249.5, Currency: "EUR", Condition: "used", Metadata: "{}"},
+		{Source: "govdeals", URL: "https://example.com/usd", Title: "Lenovo ThinkCentre", Price: 99, Condition: "used", Metadata: "{}"},
+	}
+	for _, l := range listings {
+		if err := db.CacheListing(l); err != nil {
+			t.Fatalf("Failed to cache listing: %v", err)
+		}
+	}
+
+	cached, err := db.GetCachedListings("Lenovo", 10)
+	if err != nil {
+		t.Fatalf("Failed to get cached listings: %v", err)
+	}
+
+	currencies := make(map[string]string)
+	for _, l := range cached {
+		currencies[l.URL] = l.Currency
+	}
+	if currencies["https://example.com/eur"] != "EUR" {
+		t.Errorf("Expected currency 'EUR', got '%s'", currencies["https://example.com/eur"])
+	}
+	if currencies["https://example.com/usd"] != "USD" {
+		t.Errorf("Expected missing currency to default to 'USD', got '%s'", currencies["https://example.com/usd"])
+	}
+}
+
+func TestQueryCachedListings(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// Seeded oldest first, so "ts" order is the reverse of this list
+	seed := []struct {
+		source string
+		title  string
+		price  float64
+	}{
+		{"govdeals", "Dell Monitor", 40},
+		{"ebay", "RTX 3060", 280},
+		{"govdeals", "RTX 3070", 390},
+		{"shopgoodwill", "Apple iPad", 150},
+		{"ebay", "RTX 3080", 520},
+	}
+	base := time.Now().UTC().Add(-time.Hour)
+	for i, l := range seed {
+		if _, err := db.db.Exec(
+			"INSERT INTO cached_listings (source, url, title, price, condition, timestamp, metadata) VALUES (?, ?, ?, ?, 'used', ?, '{}')",
+			l.source, fmt.Sprintf("https://example.com/%d", i), l.title, l.price,
+			base.Add(time.Duration(i)*time.Minute).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatalf("Failed to seed cached listing: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    ListingQuery
+		expected []string
+	}{
+		{"everything newest first", ListingQuery{}, []string{"RTX 3080", "Apple iPad", "RTX 3070", "RTX 3060", "Dell Monitor"}},
+		{"title", ListingQuery{Query: "rtx"}, []string{"RTX 3080", "RTX 3070", "RTX 3060"}},
+		{"source", ListingQuery{Source: "govdeals"}, []string{"RTX 3070", "Dell Monitor"}},
+		{"min price", ListingQuery{MinPrice: 390}, []string{"RTX 3080", "RTX 3070"}},
+		{"max price", ListingQuery{MaxPrice: 150}, []string{"Apple iPad", "Dell Monitor"}},
+		{"price range", ListingQuery{MinPrice: 100, MaxPrice: 400, OrderBy: "price"}, []string{"RTX 3070", "RTX 3060", "Apple iPad"}},
+		{"combined", ListingQuery{Query: "RTX", Source: "ebay", MaxPrice: 300}, []string{"RTX 3060"}},
+		{"order by title", ListingQuery{OrderBy: "title", Limit: 2}, []string{"RTX 3080", "RTX 3070"}},
+		{"first page", ListingQuery{Limit: 2}, []string{"RTX 3080", "Apple iPad"}},
+		{"middle page", ListingQuery{Limit: 2, Offset: 2}, []string{"RTX 3070", "RTX 3060"}},
+		{"partial last page", ListingQuery{Limit: 2, Offset: 4}, []string{"Dell Monitor"}},
+		{"past the end", ListingQuery{Limit: 2, Offset: 5}, nil},
+		{"offset without limit", ListingQuery{Offset: 3}, []string{"RTX 3060", "Dell Monitor"}},
+	}
+
+	for _, tt := range tests {
+		listings, err := db.QueryCachedListings(tt.query)
+		if err != nil {
+			t.Fatalf("%s: failed to query cached listings: %v", tt.name, err)
+		}
+
+		var titles []string
+		for _, l := range listings {
+			titles = append(titles, l.Title)
+		}
+		if strings.Join(titles, ", ") != strings.Join(tt.expected, ", ") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, titles)
+		}
+	}
+
+	if _, err := db.QueryCachedListings(ListingQuery{OrderBy: "price; DROP TABLE cached_listings"}); err == nil {
+		t.Error("Expected an error for an unsupported order")
+	}
+}
+
+func TestGetStatsBySource(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	listings := []Listing{
+		{Source: "ebay", URL: "https://example.com/1", Title: "GPU", Price: 100},
+		{Source: "ebay", URL: "https://example.com/2", Title: "GPU", Price: 300},
+		{Source: "ebay", URL: "https://example.com/3", Title: "CPU", Price: 200},
+		{Source: "govdeals", URL: "https://example.com/4", Title: "Monitor", Price: 45.5},
+	}
+	for _, l := range listings {
+		if err := db.CacheListing(l); err != nil {
+			t.Fatalf("Failed to cache listing: %v", err)
+		}
+	}
+
+	stats, err := db.GetStatsBySource()
+	if err != nil {
+		t.Fatalf("Failed to get stats by source: %v", err)
+	}
+
+	if len(stats) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(stats))
+	}
+	want := map[string]SourceStat{
+		"ebay":     {Count: 3, AvgPrice: 200, MinPrice: 100, MaxPrice: 300},
+		"govdeals": {Count: 1, AvgPrice: 45.5, MinPrice: 45.5, MaxPrice: 45.5},
+	}
+	for source, w := range want {
+		if stats[source] != w {
+			t.Errorf("Expected %s stats %+v, got %+v", source, w, stats[source])
+		}
+	}
+}
+
+func TestGetTopMovers(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	seed := func(title string, price float64, age time.Duration) {
+		if _, err := db.db.Exec(
+			"INSERT INTO price_history (item_title, price, source, timestamp, metadata) VALUES (?, ?, 'ebay', ?, '{}')",
+			title, price, now.Add(-age).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatalf("Failed to seed price history: %v", err)
+		}
+	}
+
+	day := 24 * time.Hour
+	// GPU halves over the last week but doubled before it
+	seed("GPU", 100, 20*day)
+	seed("GPU", 400, 5*day)
+	seed("GPU", 200, time.Hour)
+	// Monitor rises 25%, with a bigger dollar move than Keyboard's 25%
+	seed("Monitor", 200, 3*day)
+	seed("Monitor", 250, 2*time.Hour)
+	seed("Keyboard", 40, 3*day)
+	seed("Keyboard", 50, 2*time.Hour)
+	// A single data point, and a flat price, are not movers
+	seed("Mouse", 20, time.Hour)
+	seed("Cable", 10, 3*day)
+	seed("Cable", 10, time.Hour)
+
+	tests := []struct {
+		window time.Duration
+		limit  int
+		want   string
+	}{
+		{7 * day, 10, "GPU -200.00 -50.0%,Monitor +50.00 +25.0%,Keyboard +10.00 +25.0%"},
+		{30 * day, 10, "GPU +100.00 +100.0%,Monitor +50.00 +25.0%,Keyboard +10.00 +25.0%"},
+		{0, 10, "GPU +100.00 +100.0%,Monitor +50.00 +25.0%,Keyboard +10.00 +25.0%"},
+		{7 * day, 2, "GPU -200.00 -50.0%,Monitor +50.00 +25.0%"},
+		{day, 10, ""},
+	}
+	for _, tt := range tests {
+		movers, err := db.GetTopMovers(tt.window, tt.limit)
+		if err != nil {
+			t.Fatalf("Failed to get top movers: %v", err)
+		}
+		got := make([]string, len(movers))
+		for i, m := range movers {
+			got[i] = fmt.Sprintf("%s %+.2f %+.1f%%", m.ItemTitle, m.Delta, m.Pct)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("GetTopMovers(%s, %d): expected %q, got %q", tt.window, tt.limit, tt.want, strings.Join(got, ","))
+		}
+	}
+
+	movers, err := db.GetTopMovers(7*day, 1)
+	if err != nil || len(movers) != 1 || movers[0].OldPrice != 400 || movers[0].NewPrice != 200 {
+		t.Errorf("Expected GPU to move from $400 to $200 in the last week, got %+v (%v)", movers, err)
+	}
+}
+
+func TestClearData(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	seed := func() {
+		if err := db.SaveSearchHistory("rtx 3060", 4); err != nil {
+			t.Fatalf("Failed to save search history: %v", err)
+		}
+		if err := db.SavePriceHistory("RTX 3060", 250, "ebay", nil); err != nil {
+			t.Fatalf("Failed to save price history: %v", err)
+		}
+		if err := db.CacheListing(Listing{Source: "ebay", URL: "https://ebay.com/1", Title: "RTX 3060", Price: 250}); err != nil {
+			t.Fatalf("Failed to cache listing: %v", err)
+		}
+		if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+			t.Fatalf("Failed to save config: %v", err)
+		}
+	}
+	count := func(table string) int {
+		var n int
+		if err := db.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		return n
+	}
+
+	seed()
+	if err := db.AddToWatchlist("RTX 3060", 200); err != nil {
+		t.Fatalf("Failed to add to watchlist: %v", err)
+	}
+	if err := db.RecordAlert("RTX 3060", 190, 200); err != nil {
+		t.Fatalf("Failed to record alert: %v", err)
+	}
+
+	if err := db.ClearData(ClearOptions{KeepConfigs: true}); err != nil {
+		t.Fatalf("Failed to clear data: %v", err)
+	}
+	for table, want := range map[string]int{
+		"search_history":  0,
+		"price_history":   0,
+		"cached_listings": 0,
+		"saved_configs":   1,
+		"watchlist":       1,
+		"alerts":          1,
+	} {
+		if got := count(table); got != want {
+			t.Errorf("Expected %d rows in %s after clearing, got %d", want, table, got)
+		}
+	}
+
+	seed()
+	if err := db.ClearData(ClearOptions{}); err != nil {
+		t.Fatalf("Failed to clear data: %v", err)
+	}
+	for table, want := range map[string]int{
+		"search_history":  0,
+		"price_history":   0,
+		"cached_listings": 0,
+		"saved_configs":   0,
+		"watchlist":       1,
+		"alerts":          1,
+	} {
+		if got := count(table); got != want {
+			t.Errorf("Expected %d rows in %s after clearing configs too, got %d", want, table, got)
+		}
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arbfinder.db")
+	db, err := NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for i := 0; i < 500; i++ {
+		listing := Listing{Source: "ebay", URL: fmt.Sprintf("https://ebay.com/%d", i), Title: strings.Repeat("RTX 3060 ", 20), Price: float64(i)}
+		if err := db.CacheListing(listing); err != nil {
+			t.Fatalf("Failed to cache listing: %v", err)
+		}
+	}
+	if err := db.Vacuum(); err != nil {
+		t.Fatalf("Failed to vacuum a populated database: %v", err)
+	}
+	if n, _ := db.GetCachedListings("", 1000); len(n) != 500 {
+		t.Fatalf("Expected vacuuming to keep all 500 listings, got %d", len(n))
+	}
+
+	if err := db.ClearData(ClearOptions{KeepConfigs: true}); err != nil {
+		t.Fatalf("Failed to clear data: %v", err)
+	}
+	before, err := db.Size()
+	if err != nil {
+		t.Fatalf("Failed to get size: %v", err)
+	}
+	if err := db.Vacuum(); err != nil {
+		t.Fatalf("Failed to vacuum: %v", err)
+	}
+	after, err := db.Size()
+	if err != nil {
+		t.Fatalf("Failed to get size: %v", err)
+	}
+	if after >= before {
+		t.Errorf("Expected vacuuming to shrink the database from %d bytes, got %d", before, after)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat database: %v", err)
+	}
+	if info.Size() != after {
+		t.Errorf("Expected the file to shrink to %d bytes, got %d", after, info.Size())
+	}
+}
+
+func TestFavorites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.db")
+	db, err := NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	rtx := APIListing{ID: 1, Source: "govdeals", URL: "https://example.com/1", Title: "RTX 3060", Price: 249.99, Currency: "EUR", Metadata: map[string]interface{}{"bids": 3.0}}
+	thinkpad := APIListing{ID: 2, Source: "ebay", URL: "https://example.com/2", Title: "ThinkPad X1", Price: 400}
+	if err := db.AddFavorite(rtx); err != nil {
+		t.Fatalf("Failed to add favorite: %v", err)
+	}
+	if err := db.AddFavorite(thinkpad); err != nil {
+		t.Fatalf("Failed to add favorite: %v", err)
+	}
+	// Adding again refreshes the snapshot instead of duplicating it
+	rtx.Price = 199.99
+	if err := db.AddFavorite(rtx); err != nil {
+		t.Fatalf("Failed to update favorite: %v", err)
+	}
+	if err := db.AddFavorite(APIListing{Title: "No URL"}); err == nil {
+		t.Error("Expected an error for a listing without a URL")
+	}
+
+	favorites, err := db.GetFavorites()
+	if err != nil {
+		t.Fatalf("Failed to get favorites: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Fatalf("Expected 2 favorites, got %d", len(favorites))
+	}
+	for _, f := range favorites {
+		if f.CreatedAt.IsZero() {
+			t.Errorf("Expected '%s' to have an added time", f.Listing.Title)
+		}
+		if f.Listing.URL == rtx.URL && (f.Listing.Price != 199.99 || f.Listing.Currency != "EUR" || f.Listing.Metadata["bids"] != 3.0) {
+			t.Errorf("Expected the refreshed snapshot, got %+v", f.Listing)
+		}
+	}
+
+	// Toggling removes an existing favorite and adds a new one
+	added, err := db.ToggleFavorite(thinkpad)
+	if err != nil || added {
+		t.Fatalf("Expected toggling a favorite to remove it, got added=%v err=%v", added, err)
+	}
+	added, err = db.ToggleFavorite(thinkpad)
+	if err != nil || !added {
+		t.Fatalf("Expected toggling again to add it back, got added=%v err=%v", added, err)
+	}
+
+	if err := db.RemoveFavorite(rtx.URL); err != nil {
+		t.Fatalf("Failed to remove favorite: %v", err)
+	}
+	// Removing something that isn't a favorite is not an error
+	if err := db.RemoveFavorite("https://example.com/missing"); err != nil {
+		t.Errorf("Expected removing a missing favorite to succeed, got %v", err)
+	}
+
+	// Favorites survive a restart and clearing the local data
+	if err := db.ClearData(ClearOptions{}); err != nil {
+		t.Fatalf("Failed to clear data: %v", err)
+	}
+	db.Close()
+	db, err = NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+	favorites, err = db.GetFavorites()
+	if err != nil {
+		t.Fatalf("Failed to get favorites: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].Listing.Title != "ThinkPad X1" {
+		t.Errorf("Expected only 'ThinkPad X1' after reopening, got %+v", favorites)
+	}
+}
+
+func TestListingNotes(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	url := "https://example.com/1"
+	listing := Listing{Source: "ebay", URL: url, Title: "RTX 3060", Price: 250, Timestamp: time.Now().Add(-60 * 24 * time.Hour)}
+	if err := db.CacheListing(listing); err != nil {
+		t.Fatalf("Failed to cache listing: %v", err)
+	}
+
+	if note, err := db.GetListingNote(url); err != nil || note != "" {
+		t.Errorf("Expected no note yet, got %q (%v)", note, err)
+	}
+	if err := db.SetListingNote(url, "  seller looks sketchy \n"); err != nil {
+		t.Fatalf("Failed to set note: %v", err)
+	}
+	if note, err := db.GetListingNote(url); err != nil || note != "seller looks sketchy" {
+		t.Errorf("Expected the trimmed note, got %q (%v)", note, err)
+	}
+
+	// Caching the listing again keeps its note
+	listing.Price = 230
+	if err := db.CacheListing(listing); err != nil {
+		t.Fatalf("Failed to re-cache listing: %v", err)
+	}
+	if note, _ := db.GetListingNote(url); note != "seller looks sketchy" {
+		t.Errorf("Expected the note to survive re-caching, got %q", note)
+	}
+	if cached, _ := db.GetCachedListings("RTX", 10); len(cached) != 1 || cached[0].Price != 230 {
+		t.Errorf("Expected one cached listing at the new price, got %+v", cached)
+	}
+
+	// Pruning keeps noted listings however old
+	if pruned, err := db.PruneCachedListings(30 * 24 * time.Hour); err != nil || pruned != 0 {
+		t.Errorf("Expected the noted listing to be kept, pruned %d (%v)", pruned, err)
+	}
+
+	notes, err := db.GetListingNotes()
+	if err != nil {
+		t.Fatalf("Failed to get notes: %v", err)
+	}
+	if len(notes) != 1 || notes[url] != "seller looks sketchy" {
+		t.Errorf("Expected one note keyed by URL, got %v", notes)
+	}
+
+	if err := db.SetListingNote("https://example.com/missing", "hi"); !errors.Is(err, errListingNotCached) {
+		t.Errorf("Expected errListingNotCached for an uncached URL, got %v", err)
+	}
+
+	// An empty note removes it
+	if err := db.SetListingNote(url, " "); err != nil {
+		t.Fatalf("Failed to clear note: %v", err)
+	}
+	if notes, _ := db.GetListingNotes(); len(notes) != 0 {
+		t.Errorf("Expected no notes after clearing, got %v", notes)
+	}
+	if pruned, _ := db.PruneCachedListings(30 * 24 * time.Hour); pruned != 1 {
+		t.Errorf("Expected the listing to be pruned once its note is gone, pruned %d", pruned)
+	}
+}
+
+func TestStatsLeaveOutSessionConfig(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SaveSession(map[string]interface{}{"provider": "govdeals"}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	stats, err := db.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["saved_configs"] != 0 {
+		t.Errorf("Expected the session row left out of saved_configs, got %d", stats["saved_configs"])
+	}
+
+	if err := db.SaveConfig("prod", map[string]interface{}{"api_url": "https://api.example.com"}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if stats, _ := db.GetStats(); stats["saved_configs"] != 1 {
+		t.Errorf("Expected 1 saved config, got %d", stats["saved_configs"])
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DedupeListings collapses listings that are the same item: those whose URLs
+// match once normalized (see normalizeListingURL), and those with the same
+// source, title and price. Of each set of duplicates the freshest is kept,
+// in the position the first of them had.
+func DedupeListings(in []APIListing) []APIListing {
+	out := make([]APIListing, 0, len(in))
+	index := make(map[string]int) // duplicate keys to positions in out
+
+	for _, l := range in {
+		keys := dedupeKeys(l)
+
+		pos, dup := -1, false
+		for _, k := range keys {
+			if pos, dup = index[k]; dup {
+				break
+			}
+		}
+		if !dup {
+			pos = len(out)
+			out = append(out, l)
+		} else if newer(l, out[pos]) {
+			out[pos] = l
+		}
+
+		// Keys of every copy point at the survivor, so a third copy matching
+		// only the dropped one still collapses
+		for _, k := range keys {
+			index[k] = pos
+		}
+	}
+
+	return out
+}
+
+// dedupeKeys returns the keys under which a listing counts as a duplicate.
+// Title and source are compared ignoring case; a listing without a title or
+// URL has nothing to match on.
+func dedupeKeys(l APIListing) []string {
+	var keys []string
+	if title := strings.ToLower(strings.TrimSpace(l.Title)); title != "" {
+		keys = append(keys, fmt.Sprintf("item:%s\x00%s\x00%g", strings.ToLower(l.Source), title, l.Price))
+	}
+	if u := normalizeListingURL(l.URL); u != "" {
+		keys = append(keys, "url:"+u)
+	}
+	return keys
+}
+
+// newer reports whether a was listed after b; a listing without a timestamp
+// is never newer
+func newer(a, b APIListing) bool {
+	at, ok := a.Time()
+	if !ok {
+		return false
+	}
+	bt, ok := b.Time()
+	return !ok || at.After(bt)
+}
+
+// normalizeListingURL reduces a listing URL to what identifies the item:
+// the query string, fragment and trailing slashes are dropped and the scheme
+// and host lowercased
+func normalizeListingURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil {
+		if i := strings.IndexAny(raw, "?#"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimRight(raw, "/")
+	}
+
+	u.RawQuery, u.ForceQuery, u.Fragment, u.RawFragment = "", false, "", ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	return strings.TrimRight(u.String(), "/")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeListingURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://ebay.com/itm/123", "https://ebay.com/itm/123"},
+		{"https://ebay.com/itm/123/", "https://ebay.com/itm/123"},
+		{"https://EBAY.com/itm/123?hash=abc&_trksid=p1", "https://ebay.com/itm/123"},
+		{"https://ebay.com/itm/123#photos", "https://ebay.com/itm/123"},
+		{" https://ebay.com/itm/123/?ref=x ", "https://ebay.com/itm/123"},
+		{"https://ebay.com/itm/ABC", "https://ebay.com/itm/ABC"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeListingURL(tt.raw); got != tt.want {
+			t.Errorf("normalizeListingURL(%q): expected '%s', got '%s'", tt.raw, tt.want, got)
+		}
+	}
+}
+
+func TestDedupeListings(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []APIListing
+		want string // titles kept, in order
+	}{
+		{
+			"exact duplicates",
+			[]APIListing{
+				{Title: "A", Source: "ebay", URL: "https://ebay.com/1", Price: 10},
+				{Title: "A", Source: "ebay", URL: "https://ebay.com/1", Price: 10},
+			},
+			"A",
+		},
+		{
+			"same URL but for query string and slash",
+			[]APIListing{
+				{Title: "A", Source: "ebay", URL: "https://ebay.com/1?ref=search", Price: 10},
+				{Title: "B", Source: "govdeals", URL: "https://ebay.com/1/", Price: 12},
+			},
+			"A",
+		},
+		{
+			"same source, title and price without URLs",
+			[]APIListing{
+				{Title: "RTX 3060", Source: "ebay", Price: 250},
+				{Title: "rtx 3060 ", Source: "EBAY", Price: 250},
+			},
+			"RTX 3060",
+		},
+		{
+			"same source, title and price under different URLs",
+			[]APIListing{
+				{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1", Price: 250},
+				{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/2", Price: 250},
+			},
+			"RTX 3060",
+		},
+		{
+			"different price",
+			[]APIListing{
+				{Title: "RTX 3060", Source: "ebay", Price: 250},
+				{Title: "RTX 3060", Source: "ebay", Price: 260},
+			},
+			"RTX 3060,RTX 3060",
+		},
+		{
+			"different source",
+			[]APIListing{
+				{Title: "RTX 3060", Source: "ebay", Price: 250},
+				{Title: "RTX 3060", Source: "govdeals", Price: 250},
+			},
+			"RTX 3060,RTX 3060",
+		},
+		{
+			"different path",
+			[]APIListing{
+				{Title: "A", URL: "https://ebay.com/1", Price: 10},
+				{Title: "B", URL: "https://ebay.com/12", Price: 10},
+			},
+			"A,B",
+		},
+		{
+			"untitled listings without URLs",
+			[]APIListing{{Price: 10}, {Price: 10}},
+			",",
+		},
+		{
+			"chained through the dropped copy",
+			[]APIListing{
+				{Title: "A", Source: "ebay", URL: "https://ebay.com/1", Price: 10},
+				{Title: "A", Source: "ebay", URL: "https://ebay.com/2", Price: 10},
+				{Title: "C", Source: "govdeals", URL: "https://ebay.com/2?x=1", Price: 11},
+			},
+			"A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var titles []string
+			for _, l := range DedupeListings(tt.in) {
+				titles = append(titles, l.Title)
+			}
+			if got := strings.Join(titles, ","); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDedupeListingsKeepsFreshest(t *testing.T) {
+	in := []APIListing{
+		{ID: 1, Title: "A", URL: "https://ebay.com/1", Timestamp: 1700000000},
+		{ID: 2, Title: "B", URL: "https://ebay.com/2", Timestamp: 1700000000},
+		{ID: 3, Title: "A", URL: "https://ebay.com/1?ref=x", Timestamp: 1700003600},
+		{ID: 4, Title: "A", URL: "https://ebay.com/1/"},
+		{ID: 5, Title: "A", URL: "https://ebay.com/1", Timestamp: 1600000000},
+	}
+
+	out := DedupeListings(in)
+	if len(out) != 2 {
+		t.Fatalf("Expected 2 listings, got %+v", out)
+	}
+	if out[0].ID != 3 {
+		t.Errorf("Expected the newest copy in the first copy's place, got ID %d", out[0].ID)
+	}
+	if out[1].ID != 2 {
+		t.Errorf("Expected the unrelated listing to follow, got ID %d", out[1].ID)
+	}
+}
+
+func TestSearchResultsAreDeduped(t *testing.T) {
+	m := newTestModel("")
+	m.searchID = 1
+	m.Update(SearchResultMsg{ID: 1, Query: "rtx", Results: []APIListing{
+		{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1?ref=a"},
+		{Title: "RTX 3060", Source: "ebay", URL: "https://ebay.com/1?ref=b"},
+	}})
+	if got := len(m.results.all); got != 1 {
+		t.Errorf("Expected the duplicate to be dropped, got %d listings", got)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DetailView renders every field of a single listing as a full-screen overlay.
+// It opens on the row from the results list and shows the API's full record
+// once that has loaded.
+type DetailView struct {
+	listing APIListing
+	loading bool   // set while the full record is being fetched
+	notice  string // why the list row is shown instead of the full record
+	raw     string // when set, shown verbatim in place of the fields
+	note    string // the user's note on the listing
+	// noteInput is non-nil while the note is being edited
+	noteInput *textinput.Model
+}
+
+func NewDetailView(listing APIListing) *DetailView {
+	return &DetailView{listing: listing}
+}
+
+func (v *DetailView) View(width, height int) string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true).
+		Width(12)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		Padding(1, 2)
+
+	// Leave room for the border and padding
+	innerWidth := width - 8
+	if innerWidth < 20 {
+		innerWidth = 20
+	}
+	valueWidth := innerWidth - 12
+
+	l := v.listing
+	field := func(label, value string) {
+		b.WriteString(lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			labelStyle.Render(label),
+			valueStyle.Width(valueWidth).Render(value),
+		))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(titleStyle.Render("📄 Listing Details"))
+	b.WriteString("\n\n")
+	if v.loading {
+		b.WriteString(infoStyle.Render("Loading full details..."))
+		b.WriteString("\n\n")
+	} else if v.notice != "" {
+		b.WriteString(infoStyle.Render(v.notice))
+		b.WriteString("\n\n")
+	}
+
+	if v.raw != "" {
+		b.WriteString(valueStyle.Render(v.raw))
+		b.WriteString("\n\n")
+		b.WriteString(infoStyle.Render("Esc/q: Close"))
+		return boxStyle.Width(innerWidth + 4).Render(b.String())
+	}
+
+	field("Source:", l.Source)
+	field("Title:", l.Title)
+	field("Price:", formatPrice(l.Price, l.Currency))
+	field("Condition:", valueOr(l.Condition, "unknown"))
+	field("Listed:", formatTimestamp(l.Timestamp))
+	field("URL:", valueOr(l.URL, "none"))
+	if v.noteInput != nil {
+		field("Note:", v.noteInput.View())
+	} else if v.note != "" {
+		field("Note:", "📝 "+v.note)
+	}
+
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("Metadata:"))
+	b.WriteString("\n")
+	if len(l.Metadata) > 0 {
+		metadata, err := json.MarshalIndent(l.Metadata, "", "  ")
+		if err != nil {
+			metadata = []byte(err.Error())
+		}
+		b.WriteString(valueStyle.Render(string(metadata)))
+	} else {
+		b.WriteString(infoStyle.Render("No metadata"))
+	}
+
+	b.WriteString("\n\n")
+	if v.noteInput != nil {
+		b.WriteString(infoStyle.Render("Enter: Save note (empty removes it) • Esc: Cancel"))
+	} else {
+		b.WriteString(infoStyle.Render("o: Open in browser • n: Note • Esc/q: Close"))
+	}
+
+	return boxStyle.Width(innerWidth + 4).Render(b.String())
+}
+
+// currencySymbols maps ISO currency codes to the symbol shown before prices
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// formatPrice renders a price with its currency symbol, defaulting to
+// dollars. Currencies without a known symbol are shown by code.
+func formatPrice(price float64, currency string) string {
+	code := strings.ToUpper(valueOr(currency, defaultCurrency))
+	if symbol, ok := currencySymbols[code]; ok {
+		return fmt.Sprintf("%s%.2f", symbol, price)
+	}
+	return fmt.Sprintf("%.2f %s", price, code)
+}
+
+// formatTimestamp renders an epoch timestamp as a date plus relative age
+func formatTimestamp(timestamp float64) string {
+	t, ok := parseEpoch(timestamp)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (%s)", t.Format("2006-01-02 15:04:05"), formatAge(timestamp))
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
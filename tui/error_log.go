@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// errorLogCapacity is how many errors the log keeps; the oldest make way for
+// new ones
+const errorLogCapacity = 100
+
+// errorEntry is one error shown during the session
+type errorEntry struct {
+	at     time.Time
+	source string // the pane that showed it
+	text   string
+}
+
+// errorLog is a bounded ring buffer of the errors shown this session, so
+// they can be reviewed after they have left the screen. The zero value keeps
+// errorLogCapacity entries.
+type errorLog struct {
+	entries  []errorEntry
+	next     int // where the next entry goes once the buffer is full
+	capacity int // 0 means errorLogCapacity
+	// offset is how many entries the overlay is scrolled past the newest
+	offset int
+}
+
+// push records an error, dropping the oldest one when the log is full
+func (l *errorLog) push(e errorEntry) {
+	limit := l.capacity
+	if limit <= 0 {
+		limit = errorLogCapacity
+	}
+	if len(l.entries) < limit {
+		l.entries = append(l.entries, e)
+		return
+	}
+	l.entries[l.next] = e
+	l.next = (l.next + 1) % limit
+}
+
+// recent returns the logged errors, newest first
+func (l errorLog) recent() []errorEntry {
+	recent := make([]errorEntry, 0, len(l.entries))
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		recent = append(recent, l.entries[(l.next+i)%len(l.entries)])
+	}
+	return recent
+}
+
+// logPaneErrors records each pane error the moment it appears. Panes keep
+// their last error until the next attempt clears it, so an error is only
+// logged when the text shown changes.
+func (m *model) logPaneErrors() {
+	shown := []string{
+		m.search.lastError,
+		m.results.lastError,
+		m.stats.lastError,
+		m.config.lastError,
+		m.comps.lastError,
+		m.watchlist.lastError,
+		m.favorites.lastError,
+	}
+	if m.paneErrors == nil {
+		m.paneErrors = make([]string, len(shown))
+	}
+	for i, text := range shown {
+		if text != "" && text != m.paneErrors[i] {
+			m.errorLog.push(errorEntry{at: time.Now(), source: paneNames[i], text: text})
+		}
+		m.paneErrors[i] = text
+	}
+}
+
+// updateErrorLog handles input while the error log overlay is open
+func (m *model) updateErrorLog(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+c":
+		return tea.Quit
+	case key.Matches(msg, keys.Global.Errors, keys.Global.Cancel):
+		m.showingErrors = false
+	case key.Matches(msg, keys.ErrorLog.Up):
+		m.errorLog.offset = max(m.errorLog.offset-1, 0)
+	case key.Matches(msg, keys.ErrorLog.Down):
+		m.errorLog.offset = min(m.errorLog.offset+1, max(len(m.errorLog.entries)-1, 0))
+	}
+	return nil
+}
+
+// View renders the logged errors, newest first, from the scroll offset
+func (l errorLog) View(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	timeStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+
+	sourceStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(theme.Error)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("⚠ Recent Errors (%d)", len(l.entries))))
+	b.WriteString("\n\n")
+
+	recent := l.recent()
+	if len(recent) == 0 {
+		b.WriteString(infoStyle.Render("No errors this session"))
+	}
+
+	// Title, its margin, the blank line below it and the footer
+	rows := max(height-5, 1)
+	offset := min(l.offset, max(len(recent)-1, 0))
+	end := min(offset+rows, len(recent))
+	for _, e := range recent[offset:end] {
+		line := fmt.Sprintf("%s %s %s",
+			timeStyle.Render(e.at.Format("15:04:05")),
+			sourceStyle.Render(fmt.Sprintf("%-10s", e.source)),
+			errorStyle.Render(strings.Join(strings.Fields(e.text), " ")))
+		b.WriteString(ansi.Truncate(line, width, "…"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	footer := fmt.Sprintf("↑/↓: Scroll • %s/esc: Close", keys.Global.Errors.Help().Key)
+	if len(recent) > rows {
+		footer = fmt.Sprintf("Showing %d-%d of %d • %s", offset+1, end, len(recent), footer)
+	}
+	b.WriteString(infoStyle.Render(footer))
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestErrorLogDropsOldest(t *testing.T) {
+	l := errorLog{capacity: 3}
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	for i := 1; i <= 5; i++ {
+		l.push(errorEntry{at: start.Add(time.Duration(i) * time.Second), source: "Results", text: fmt.Sprintf("error %d", i)})
+	}
+
+	var texts []string
+	for _, e := range l.recent() {
+		texts = append(texts, e.text)
+	}
+	if got := strings.Join(texts, ", "); got != "error 5, error 4, error 3" {
+		t.Errorf("Expected the 3 newest errors, newest first, got %s", got)
+	}
+
+	var unbounded errorLog
+	for i := 0; i < errorLogCapacity+10; i++ {
+		unbounded.push(errorEntry{text: fmt.Sprintf("error %d", i)})
+	}
+	if recent := unbounded.recent(); len(recent) != errorLogCapacity || recent[len(recent)-1].text != "error 10" {
+		t.Errorf("Expected the default capacity of %d ending at error 10, got %d entries", errorLogCapacity, len(recent))
+	}
+}
+
+func TestErrorLogCapturesPaneErrors(t *testing.T) {
+	var m tea.Model = newTestModel("")
+
+	m, _ = m.Update(SearchResultMsg{Query: "rtx", Error: errors.New("connection refused"), Refresh: true})
+	// The error stays on the pane through later updates but is logged once
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(SearchSavedMsg{Error: errors.New("name already taken")})
+
+	recent := m.(model).errorLog.recent()
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 logged errors, got %+v", recent)
+	}
+	if recent[0].text != "name already taken" || recent[1].source != "Results" || !strings.Contains(recent[1].text, "connection refused") {
+		t.Errorf("Expected the save error after the search error, got %+v", recent)
+	}
+
+	m, _ = m.Update(keyMsg("ctrl+e"))
+	view := m.View()
+	if !strings.Contains(view, "Recent Errors (2)") || !strings.Contains(view, "name already taken") {
+		t.Errorf("Expected the error log overlay:\n%s", view)
+	}
+	// Keys go to the overlay rather than the pane underneath
+	m, _ = m.Update(keyMsg("j"))
+	if m.(model).errorLog.offset != 1 {
+		t.Errorf("Expected the log to scroll, got offset %d", m.(model).errorLog.offset)
+	}
+	m, _ = m.Update(keyMsg("esc"))
+	if m.(model).showingErrors || strings.Contains(m.View(), "Recent Errors") {
+		t.Error("Expected esc to close the error log")
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FavoritesPane lists the starred listings from their stored snapshots, so
+// it works offline
+type FavoritesPane struct {
+	favorites   []Favorite
+	selectedIdx int
+	offset      int
+	pageSize    int
+	lastError   string
+	lastSuccess string
+	db          *Database
+}
+
+func NewFavoritesPane() *FavoritesPane {
+	return &FavoritesPane{
+		favorites: []Favorite{},
+		pageSize:  10,
+	}
+}
+
+func (p *FavoritesPane) Update(msg tea.Msg) (FavoritesPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Favorites.Up):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx-1, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.Down):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx+1, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.Top):
+			p.selectedIdx, p.offset = scrollTo(0, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.Bottom):
+			p.selectedIdx, p.offset = scrollTo(len(p.favorites)-1, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.HalfPageUp):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx-p.pageSize/2, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.HalfPageDn):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx+p.pageSize/2, p.offset, len(p.favorites), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.Open):
+			p.openSelected()
+			return *p, nil
+
+		case key.Matches(msg, keys.Favorites.Remove):
+			p.removeSelected()
+			return *p, nil
+		}
+	}
+
+	return *p, nil
+}
+
+// openSelected opens the highlighted favorite in the system browser
+func (p *FavoritesPane) openSelected() {
+	if len(p.favorites) == 0 || p.selectedIdx >= len(p.favorites) {
+		return
+	}
+
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	url := p.favorites[p.selectedIdx].Listing.URL
+	if err := browserOpener(url); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	p.lastSuccess = fmt.Sprintf("Opened %s", url)
+}
+
+// removeSelected deletes the highlighted favorite
+func (p *FavoritesPane) removeSelected() {
+	if len(p.favorites) == 0 || p.selectedIdx >= len(p.favorites) {
+		return
+	}
+
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	listing := p.favorites[p.selectedIdx].Listing
+	if err := p.db.RemoveFavorite(listing.URL); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.Load()
+	p.lastSuccess = fmt.Sprintf("Removed '%s' from favorites", listing.Title)
+}
+
+// Load reads the favorites from the database
+func (p *FavoritesPane) Load() {
+	if p.db == nil {
+		return
+	}
+
+	favorites, err := p.db.GetFavorites()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.favorites = favorites
+	p.selectedIdx, p.offset = scrollTo(p.selectedIdx, p.offset, len(p.favorites), p.pageSize)
+}
+
+func (p *FavoritesPane) View(width, height int) string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Text).
+		Background(theme.HeaderBg).
+		Padding(0, 1)
+
+	itemStyle := lipgloss.NewStyle().
+		Padding(0, 1)
+
+	selectedItemStyle := itemStyle.Copy().
+		Background(theme.SelectedBg).
+		Bold(true)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	// Title
+	b.WriteString(titleStyle.Render(fmt.Sprintf("★ Favorites (%d listings)", len(p.favorites))))
+	b.WriteString("\n\n")
+
+	if len(p.favorites) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(theme.Subtle).
+			Italic(true)
+		b.WriteString(emptyStyle.Render("No favorites yet. Press '*' on a result to add it."))
+		b.WriteString("\n")
+	} else {
+		// Header
+		header := fmt.Sprintf("%-40s %-14s %12s  %s", "Title", "Source", "Price", "Added")
+		b.WriteString(headerStyle.Render(header))
+		b.WriteString("\n")
+
+		end := min(p.offset+p.pageSize, len(p.favorites))
+		for i := p.offset; i < end; i++ {
+			f := p.favorites[i]
+			line := fmt.Sprintf("%s %s %12s  %s",
+				padRight(truncate(f.Listing.Title, 40), 40),
+				padRight(truncate(f.Listing.Source, 14), 14),
+				formatPrice(f.Listing.Price, f.Listing.Currency),
+				f.CreatedAt.Local().Format("2006-01-02"),
+			)
+
+			if i == p.selectedIdx {
+				b.WriteString(selectedItemStyle.Render("▸ " + line))
+			} else {
+				b.WriteString(itemStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+		}
+
+		// Pagination info
+		b.WriteString("\n")
+		pageInfo := fmt.Sprintf("Showing %d-%d of %d • prices as of when each was added", p.offset+1, end, len(p.favorites))
+		b.WriteString(infoStyle.Render(pageInfo))
+	}
+
+	// Instructions
+	b.WriteString("\n\n")
+	b.WriteString(infoStyle.Render("↑/↓ or j/k: Navigate • Enter/o: Open • d: Remove • Tab: Switch pane"))
+
+	// Status messages
+	if p.lastSuccess != "" {
+		successStyle := lipgloss.NewStyle().
+			Foreground(theme.Success).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(successStyle.Render("✓ " + p.lastSuccess))
+	}
+
+	if p.lastError != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(theme.Error).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Error: %s", p.lastError)))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyCutoff is the lowest score a listing needs to count as a match
+const fuzzyCutoff = 0.5
+
+// minFuzzyPartial is the shortest query word matched inside a longer word
+const minFuzzyPartial = 3
+
+// fuzzyScore rates how well title matches query, from 0 (nothing in common)
+// to 1 (every query word found). Case, punctuation and spacing are ignored,
+// so "rtx3060" matches "RTX 3060", and longer words tolerate one typo.
+func fuzzyScore(query, title string) float64 {
+	queryTokens := fuzzyTokens(query)
+	if len(queryTokens) == 0 {
+		return 0
+	}
+	titleTokens := fuzzyTokens(title)
+
+	compact := strings.Join(queryTokens, "")
+	if len(compact) >= minFuzzyPartial && strings.Contains(strings.Join(titleTokens, ""), compact) {
+		return 1
+	}
+
+	matched := 0
+	for _, q := range queryTokens {
+		if tokenMatches(q, titleTokens) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(queryTokens))
+}
+
+// fuzzyTokens splits s into lowercase runs of letters and digits
+func fuzzyTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenMatches reports whether query word q appears among the title words:
+// exactly, inside a longer word, or one edit away. Typos are only forgiven in
+// words without digits, where 3060 and 3070 are different products.
+func tokenMatches(q string, titleTokens []string) bool {
+	typoTolerant := len(q) >= 4 && !strings.ContainsFunc(q, unicode.IsDigit)
+	for _, t := range titleTokens {
+		switch {
+		case q == t:
+			return true
+		case len(q) >= minFuzzyPartial && strings.Contains(t, q):
+			return true
+		case typoTolerant && levenshtein(q, t) <= 1:
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the number of single-rune edits between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,85 @@
+package main
+
+import "testing"
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query, title string
+		match        bool
+	}{
+		{"rtx3060", "RTX 3060 Graphics Card.", true},
+		{"rtx 3060 ti", "NVIDIA RTX 3060 Founders Edition", true},
+		{"RTX-3060", "rtx 3060", true},
+		{"graphcs card", "RTX 3060 Graphics Card", true},
+		{"thinkpad", "Lenovo ThinkPad T480", true},
+		{"rtx 3060", "Dell Monitor 24in", false},
+		{"rtx3060", "RTX 3070 Graphics Card", false},
+		{"ti", "Limited Edition Print", false},
+		{"", "RTX 3060", false},
+	}
+
+	for _, tt := range tests {
+		score := fuzzyScore(tt.query, tt.title)
+		if (score >= fuzzyCutoff) != tt.match {
+			t.Errorf("fuzzyScore(%q, %q) = %.2f, expected match %v", tt.query, tt.title, score, tt.match)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"graphics", "graphcs", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q): expected %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestFuzzyFindListings(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for i, title := range []string{"RTX 3060 Graphics Card.", "Dell Monitor", "RTX 3070 Graphics Card", "EVGA RTX 3060 Ti"} {
+		if err := db.CacheListing(Listing{Source: "shopgoodwill", URL: "https://example.com/" + title, Title: title, Price: float64(100 + i)}); err != nil {
+			t.Fatalf("Failed to cache listing: %v", err)
+		}
+	}
+
+	listings, err := db.FuzzyFindListings("rtx3060", 10)
+	if err != nil {
+		t.Fatalf("Failed to find listings: %v", err)
+	}
+	if len(listings) != 2 {
+		t.Fatalf("Expected 2 matches for 'rtx3060', got %d", len(listings))
+	}
+	for _, l := range listings {
+		if l.Title != "RTX 3060 Graphics Card." && l.Title != "EVGA RTX 3060 Ti" {
+			t.Errorf("Unexpected match '%s'", l.Title)
+		}
+	}
+
+	// Full matches rank above partial ones
+	listings, err = db.FuzzyFindListings("rtx 3060 ti", 10)
+	if err != nil {
+		t.Fatalf("Failed to find listings: %v", err)
+	}
+	if len(listings) != 2 || listings[0].Title != "EVGA RTX 3060 Ti" {
+		t.Errorf("Expected 2 matches led by 'EVGA RTX 3060 Ti', got %v", listings)
+	}
+
+	if listings, _ := db.FuzzyFindListings("rtx", 1); len(listings) != 1 {
+		t.Errorf("Expected the limit to cap matches at 1, got %d", len(listings))
+	}
+}
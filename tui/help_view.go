@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpColumnWidth is the width of one column of the help overlay
+const helpColumnWidth = 50
+
+// HelpView is the full-screen overlay listing every key binding
+type HelpView struct {
+	keys keyMap
+}
+
+func NewHelpView(keys keyMap) *HelpView {
+	return &HelpView{keys: keys}
+}
+
+func (h *HelpView) View(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	sectionStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Text)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	columnStyle := lipgloss.NewStyle().
+		Width(helpColumnWidth).
+		MarginBottom(1)
+
+	// One block per section, laid out in as many columns as fit
+	var blocks []string
+	for _, section := range h.keys.sections() {
+		var b strings.Builder
+		b.WriteString(sectionStyle.Render(section.title))
+		for _, binding := range section.bindings {
+			help := binding.Help()
+			b.WriteString("\n")
+			b.WriteString(keyStyle.Render(fmt.Sprintf("%-12s", help.Key)))
+			b.WriteString(" " + help.Desc)
+		}
+		blocks = append(blocks, columnStyle.Render(b.String()))
+	}
+
+	perRow := width / helpColumnWidth
+	if perRow < 1 {
+		perRow = 1
+	}
+
+	var rows []string
+	for start := 0; start < len(blocks); start += perRow {
+		end := start + perRow
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, blocks[start:end]...))
+	}
+
+	return titleStyle.Render("⌨ Key Bindings") + "\n\n" +
+		lipgloss.JoinVertical(lipgloss.Left, rows...) + "\n" +
+		infoStyle.Render("?/esc: Close help")
+}
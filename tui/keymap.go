@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keymapSection is the config file section remapping keys. Each setting in
+// it names an action as "group.action", like "results.export-csv", and
+// gives the keys that replace the default ones, separated by commas.
+const keymapSection = "keymap"
+
+// keymapPrefix marks remapped keys among the flattened config settings
+const keymapPrefix = keymapSection + "."
+
+// keyOverrides maps action names to the keys replacing their defaults
+var keyOverrides map[string][]string
+
+// vimNavigation is whether the key map includes the vim navigation keys
+var vimNavigation = true
+
+// overlayGroups are the key groups of dialogs and overlays, which take every
+// key while open, so their keys can't clash with the global ones
+var overlayGroups = map[string]bool{
+	"detail":      true,
+	"note":        true,
+	"filter":      true,
+	"add-listing": true,
+	"confirm":     true,
+	"startup":     true,
+	"error-log":   true,
+}
+
+// setKeyOverrides remaps keys for the rest of the session. nil restores the
+// defaults.
+func setKeyOverrides(overrides map[string][]string) {
+	keyOverrides = overrides
+	keys = buildKeyMap(vimNavigation, overrides)
+}
+
+// buildKeyMap is the default key map with the overridden keys remapped
+func buildKeyMap(vim bool, overrides map[string][]string) keyMap {
+	k := defaultKeyMap(vim)
+	for _, a := range k.actions() {
+		if remapped, ok := overrides[a.name]; ok && !a.fixed {
+			*a.binding = key.NewBinding(key.WithKeys(remapped...), key.WithHelp(keyHelp(remapped), a.binding.Help().Desc))
+		}
+	}
+	return k
+}
+
+// keyAction is one binding of a key map
+type keyAction struct {
+	name    string // "group.action"
+	group   string
+	binding *key.Binding
+	// fixed bindings, tagged keymap:"-", depend on the keys they're bound
+	// to and can't be remapped
+	fixed bool
+}
+
+// actions lists the bindings of k, in declaration order
+func (k *keyMap) actions() []keyAction {
+	v := reflect.ValueOf(k).Elem()
+	var actions []keyAction
+	for i := 0; i < v.NumField(); i++ {
+		group := kebabCase(v.Type().Field(i).Name)
+		g := v.Field(i)
+		for j := 0; j < g.NumField(); j++ {
+			field := g.Type().Field(j)
+			b, ok := g.Field(j).Addr().Interface().(*key.Binding)
+			if !ok {
+				continue
+			}
+			actions = append(actions, keyAction{
+				name:    group + "." + kebabCase(field.Name),
+				group:   group,
+				binding: b,
+				fixed:   field.Tag.Get("keymap") == "-",
+			})
+		}
+	}
+	return actions
+}
+
+// kebabCase turns a Go field name into an action name: NextPane becomes
+// next-pane and ExportCSV export-csv
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// parseKeymap validates the keymap section, keyed by action name, and
+// returns the keys of each remapped action. Remapped keys must not already
+// be bound to another action that is active at the same time: one in the
+// same group, or a global one.
+func parseKeymap(settings map[string]string) (map[string][]string, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	// fixed holds every action name, true for those that can't be remapped
+	fixed := make(map[string]bool)
+	defaults := defaultKeyMap(true)
+	for _, a := range defaults.actions() {
+		fixed[a.name] = a.fixed
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	overrides := make(map[string][]string, len(settings))
+	for _, name := range names {
+		if isFixed, ok := fixed[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown key action %q", keymapPrefix+name))
+			continue
+		} else if isFixed {
+			errs = append(errs, fmt.Errorf("%s%s can't be remapped", keymapPrefix, name))
+			continue
+		}
+		remapped, err := parseKeyList(settings[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", keymapPrefix, name, err))
+			continue
+		}
+		overrides[name] = remapped
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := keyConflicts(overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// parseKeyList splits a comma-separated list of keys. "space" stands for the
+// space bar, which can't be written on its own.
+func parseKeyList(value string) ([]string, error) {
+	var list []string
+	for _, k := range strings.Split(value, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			return nil, fmt.Errorf("empty key in %q", value)
+		}
+		if k == "space" {
+			k = " "
+		}
+		list = append(list, k)
+	}
+	return list, nil
+}
+
+// keyConflicts reports every remapped key that clashes with another binding.
+// Clashes among the defaults are left alone: the panes tell those apart by
+// what has focus.
+func keyConflicts(overrides map[string][]string) error {
+	k := buildKeyMap(true, overrides)
+	actions := k.actions()
+	var errs []error
+	for _, a := range actions {
+		remapped, ok := overrides[a.name]
+		if !ok {
+			continue
+		}
+		for _, other := range actions {
+			if other.name == a.name || !activeTogether(a.group, other.group) {
+				continue
+			}
+			// A clash between two remapped actions is reported once
+			if _, ok := overrides[other.name]; ok && other.name < a.name {
+				continue
+			}
+			for _, bound := range remapped {
+				if bindingHasKey(*other.binding, bound) {
+					errs = append(errs, fmt.Errorf("%s%s: %q is already bound to %s", keymapPrefix, a.name, keyHelp([]string{bound}), other.name))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// activeTogether reports whether keys of the two groups can be pressed at
+// the same time
+func activeTogether(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if a == "global" {
+		return !overlayGroups[b]
+	}
+	if b == "global" {
+		return !overlayGroups[a]
+	}
+	return false
+}
+
+// bindingHasKey reports whether b is bound to k
+func bindingHasKey(b key.Binding, k string) bool {
+	for _, bound := range b.Keys() {
+		if bound == k {
+			return true
+		}
+	}
+	return false
+}
+
+// keyHelp is how a list of keys is shown in help
+func keyHelp(list []string) string {
+	shown := make([]string, len(list))
+	for i, k := range list {
+		switch {
+		case k == " ":
+			shown[i] = "space"
+		case arrowHelp[k] != "":
+			shown[i] = arrowHelp[k]
+		default:
+			shown[i] = k
+		}
+	}
+	return strings.Join(shown, "/")
+}
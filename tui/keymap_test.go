@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKebabCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"NextPane":   "next-pane",
+		"ExportCSV":  "export-csv",
+		"CopyJSON":   "copy-json",
+		"HalfPageDn": "half-page-dn",
+		"AddListing": "add-listing",
+		"Up":         "up",
+	} {
+		if got := kebabCase(name); got != want {
+			t.Errorf("kebabCase(%q): expected '%s', got '%s'", name, want, got)
+		}
+	}
+}
+
+func TestRemappedKeysTriggerActions(t *testing.T) {
+	t.Cleanup(func() { setKeyOverrides(nil) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`theme: light
+keymap:
+  global.next-pane: ctrl+n
+  results.sort-price: "P, $" # capital P
+  results.select: space
+`), 0o644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv(configFileEnv, path)
+
+	opts, err := parseFlags(nil, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	cfg, err := loadAppConfig(opts, func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	m := newTestModel("")
+	m.applyAppConfig(cfg)
+	m.results.SetResults([]APIListing{
+		{Title: "banana", Price: 30},
+		{Title: "Apple", Price: 10},
+	})
+
+	// The old key no longer sorts; the new ones do
+	m.results.Update(keyMsg("p"))
+	if m.results.sortKey != sortNone {
+		t.Errorf("Expected 'p' to be unbound, got sort key %v", m.results.sortKey)
+	}
+	m.results.Update(keyMsg("P"))
+	if got := resultTitles(m.results); got != "Apple,banana" {
+		t.Errorf("Expected 'P' to sort by price, got '%s'", got)
+	}
+	m.results.Update(keyMsg("$"))
+	if got := resultTitles(m.results); got != "banana,Apple" {
+		t.Errorf("Expected '$' to flip the price sort, got '%s'", got)
+	}
+
+	tm, _ := m.Update(keyMsg("ctrl+n"))
+	m = tm.(model)
+	if m.currentPane != 2 {
+		t.Errorf("Expected ctrl+n to move to the next pane, got pane %d", m.currentPane)
+	}
+	tm, _ = m.Update(keyMsg("tab"))
+	m = tm.(model)
+	if m.currentPane != 2 {
+		t.Errorf("Expected tab to be unbound, got pane %d", m.currentPane)
+	}
+
+	// Help shows the remapped keys, and they survive toggling vim keys
+	if got := keys.Results.SortPrice.Help().Key; got != "P/$" {
+		t.Errorf("Expected help for 'P/$', got '%s'", got)
+	}
+	setVimNavigation(false)
+	defer setVimNavigation(true)
+	if got := keys.Results.Select.Keys(); len(got) != 1 || got[0] != " " {
+		t.Errorf("Expected space to stay remapped, got %q", got)
+	}
+}
+
+func TestKeymapValidation(t *testing.T) {
+	tests := []struct {
+		keymap map[string]string
+		want   string
+	}{
+		{map[string]string{"results.export": "x"}, `unknown key action "keymap.results.export"`},
+		{map[string]string{"global.go-to-pane": "F1"}, "keymap.global.go-to-pane can't be remapped"},
+		{map[string]string{"stats.range": "r, "}, "keymap.stats.range: empty key"},
+		// Within a group, and against the global keys
+		{map[string]string{"results.export-csv": "x"}, `keymap.results.export-csv: "x" is already bound to results.clear-filter`},
+		{map[string]string{"stats.refresh": "q"}, `keymap.stats.refresh: "q" is already bound to global.quit`},
+		{map[string]string{"global.help": "w"}, `keymap.global.help: "w" is already bound to results.watch`},
+		{map[string]string{"results.refresh": "4"}, `keymap.results.refresh: "4" is already bound to global.go-to-pane`},
+		// Two remapped actions clash once
+		{map[string]string{"comps.fetch": "F", "comps.up": "F"}, `keymap.comps.fetch: "F" is already bound to comps.up`},
+	}
+	for _, tt := range tests {
+		_, err := parseKeymap(tt.keymap)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseKeymap(%v): expected %q, got %v", tt.keymap, tt.want, err)
+			continue
+		}
+		if n := strings.Count(err.Error(), "\n"); n != 0 {
+			t.Errorf("parseKeymap(%v): expected one error, got %v", tt.keymap, err)
+		}
+	}
+
+	// Moving a key away frees it; overlays may reuse global keys
+	overrides, err := parseKeymap(map[string]string{
+		"results.export-csv":   "x",
+		"results.clear-filter": "X",
+		"detail.open":          "R",
+	})
+	if err != nil {
+		t.Fatalf("Expected a valid keymap, got %v", err)
+	}
+	if len(overrides) != 3 || overrides["results.clear-filter"][0] != "X" {
+		t.Errorf("Expected the three overrides, got %v", overrides)
+	}
+
+	if _, err := parseJSONSettings(strings.NewReader(`{"keymap": {"stats.range": 5}}`)); err == nil {
+		t.Error("Expected a non-string key to be rejected")
+	}
+	settings, err := parseJSONSettings(strings.NewReader(`{"keymap": {"stats.range": "T"}}`))
+	if err != nil || settings["keymap.stats.range"] != "T" {
+		t.Errorf("Expected the JSON keymap to be flattened, got %v (%v)", settings, err)
+	}
+}
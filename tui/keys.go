@@ -0,0 +1,403 @@
+package main
+
+import (
+	"reflect"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyMap holds every key binding, grouped by where it applies. The panes
+// match keys against these bindings and the help overlay is rendered from
+// them, so the two can't drift apart. The help tag titles each group.
+type keyMap struct {
+	Global     globalKeys     `help:"Global"`
+	Search     searchKeys     `help:"Search"`
+	Results    resultsKeys    `help:"Results"`
+	Detail     detailKeys     `help:"Listing Details"`
+	Filter     filterKeys     `help:"Results Filter"`
+	AddListing addListingKeys `help:"Add Listing"`
+	Note       noteKeys       `help:"Listing Note"`
+	Confirm    confirmKeys    `help:"Confirmation"`
+	Startup    startupKeys    `help:"Startup Error"`
+	ErrorLog   errorLogKeys   `help:"Recent Errors"`
+	Stats      statsKeys      `help:"Stats"`
+	Config     configKeys     `help:"Config"`
+	Comps      compsKeys      `help:"Comps"`
+	Watchlist  watchlistKeys  `help:"Watchlist"`
+	Favorites  favoritesKeys  `help:"Favorites"`
+}
+
+type globalKeys struct {
+	NextPane    key.Binding
+	PrevPane    key.Binding
+	GoToPane    key.Binding `keymap:"-"` // the digit pressed picks the pane
+	Cancel      key.Binding
+	AutoRefresh key.Binding
+	RefreshAll  key.Binding
+	Errors      key.Binding
+	Help        key.Binding
+	Quit        key.Binding
+}
+
+type searchKeys struct {
+	Up     key.Binding
+	Down   key.Binding
+	Left   key.Binding
+	Right  key.Binding
+	Submit key.Binding
+	Save   key.Binding
+	Toggle key.Binding
+}
+
+type resultsKeys struct {
+	Up          key.Binding
+	Down        key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	HalfPageUp  key.Binding
+	HalfPageDn  key.Binding
+	PageUp      key.Binding
+	PageDown    key.Binding
+	Details     key.Binding
+	Open        key.Binding
+	OpenAll     key.Binding
+	Copy        key.Binding
+	CopyJSON    key.Binding
+	Markdown    key.Binding
+	Watch       key.Binding
+	Favorite    key.Binding
+	SortPrice   key.Binding
+	SortTitle   key.Binding
+	SortAge     key.Binding
+	SortSource  key.Binding
+	SortMargin  key.Binding
+	AgeFormat   key.Binding
+	Select      key.Binding
+	SelectAll   key.Binding
+	ExportCSV   key.Binding
+	ExportJSON  key.Binding
+	Filter      key.Binding
+	ClearFilter key.Binding
+	Source      key.Binding
+	Group       key.Binding
+	Collapse    key.Binding
+	ExpandAll   key.Binding
+	NextGroup   key.Binding
+	PrevGroup   key.Binding
+	Add         key.Binding
+	Refresh     key.Binding
+}
+
+type detailKeys struct {
+	Close key.Binding
+	Open  key.Binding
+	Note  key.Binding
+}
+
+type noteKeys struct {
+	Save   key.Binding
+	Cancel key.Binding
+}
+
+type filterKeys struct {
+	PrevField key.Binding
+	NextField key.Binding
+	Apply     key.Binding
+	Close     key.Binding
+}
+
+type addListingKeys struct {
+	PrevField key.Binding
+	NextField key.Binding
+	Submit    key.Binding
+	Close     key.Binding
+}
+
+type confirmKeys struct {
+	Yes key.Binding
+	No  key.Binding
+}
+
+type startupKeys struct {
+	Retry key.Binding
+}
+
+type errorLogKeys struct {
+	Up   key.Binding
+	Down key.Binding
+}
+
+type statsKeys struct {
+	PrevItem  key.Binding
+	NextItem  key.Binding
+	Export    key.Binding
+	ExportAll key.Binding
+	Range     key.Binding
+	Refresh   key.Binding
+}
+
+type configKeys struct {
+	Up         key.Binding
+	Down       key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	HalfPageUp key.Binding
+	HalfPageDn key.Binding
+	PrevTheme  key.Binding
+	NextTheme  key.Binding
+	Save       key.Binding
+	Load       key.Binding
+	Apply      key.Binding
+	Test       key.Binding
+	Prune      key.Binding
+	Export     key.Binding
+	Delete     key.Binding
+	Clear      key.Binding
+	ClearAll   key.Binding
+	Compact    key.Binding
+	Refresh    key.Binding
+	VimToggle  key.Binding
+	Notify     key.Binding
+}
+
+type compsKeys struct {
+	Up         key.Binding
+	Down       key.Binding
+	HalfPageUp key.Binding
+	HalfPageDn key.Binding
+	Fetch      key.Binding
+}
+
+type watchlistKeys struct {
+	Up         key.Binding
+	Down       key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	HalfPageUp key.Binding
+	HalfPageDn key.Binding
+	Remove     key.Binding
+	Refresh    key.Binding
+}
+
+type favoritesKeys struct {
+	Up         key.Binding
+	Down       key.Binding
+	Top        key.Binding
+	Bottom     key.Binding
+	HalfPageUp key.Binding
+	HalfPageDn key.Binding
+	Open       key.Binding
+	Remove     key.Binding
+}
+
+// keys is the key map used by the whole application
+var keys = defaultKeyMap(true)
+
+// setVimNavigation rebuilds the key map with or without the vim navigation
+// keys, for users who prefer arrow keys only. Remapped keys are kept.
+func setVimNavigation(on bool) {
+	vimNavigation = on
+	keys = buildKeyMap(on, keyOverrides)
+}
+
+// arrowHelp is how arrow and jump keys are shown in help
+var arrowHelp = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→", "home": "home", "end": "end"}
+
+// defaultKeyMap builds the key map. vim adds h/j/k/l next to the arrow keys
+// and g/G next to Home/End wherever they navigate.
+func defaultKeyMap(vim bool) keyMap {
+	// nav binds an arrow or jump key and, with vim navigation on, its vim
+	// equivalent
+	nav := func(arrow, vimKey, desc string) key.Binding {
+		if !vim {
+			return key.NewBinding(key.WithKeys(arrow), key.WithHelp(arrowHelp[arrow], desc))
+		}
+		return key.NewBinding(key.WithKeys(arrow, vimKey), key.WithHelp(arrowHelp[arrow]+"/"+vimKey, desc))
+	}
+	halfPageUp := key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "half a page up"))
+	halfPageDown := key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "half a page down"))
+
+	return keyMap{
+		Global: globalKeys{
+			NextPane:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pane")),
+			PrevPane:    key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous pane")),
+			GoToPane:    key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7"), key.WithHelp("1-7", "jump to pane, outside text fields")),
+			Cancel:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel a running search")),
+			AutoRefresh: key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "toggle auto-refresh")),
+			RefreshAll:  key.NewBinding(key.WithKeys("f5", "ctrl+r"), key.WithHelp("f5/ctrl+r", "refresh pane, stats and connection")),
+			Errors:      key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "toggle recent errors")),
+			Help:        key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle this help")),
+			Quit:        key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q/ctrl+c", "quit")),
+		},
+		Search: searchKeys{
+			Up:     nav("up", "k", "previous field or recent search"),
+			Down:   nav("down", "j", "next field or recent searches"),
+			Left:   nav("left", "h", "previous provider"),
+			Right:  nav("right", "l", "next provider"),
+			Submit: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "search or use recent search")),
+			Save:   key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save search as config")),
+			Toggle: key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle condition")),
+		},
+		Results: resultsKeys{
+			Up:          nav("up", "k", "move up"),
+			Down:        nav("down", "j", "move down, past the end loads more"),
+			Top:         nav("home", "g", "first row"),
+			Bottom:      nav("end", "G", "last row"),
+			HalfPageUp:  halfPageUp,
+			HalfPageDn:  halfPageDown,
+			PageUp:      key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+			PageDown:    key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+			Details:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "view details")),
+			Open:        key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open in browser")),
+			OpenAll:     key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "open selected in browser")),
+			Copy:        key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy URL")),
+			CopyJSON:    key.NewBinding(key.WithKeys("J"), key.WithHelp("J", "copy listing as JSON")),
+			Markdown:    key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "copy as Markdown table")),
+			Watch:       key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "watch title")),
+			Favorite:    key.NewBinding(key.WithKeys("*"), key.WithHelp("*", "add/remove favorite")),
+			SortPrice:   key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "sort by price")),
+			SortTitle:   key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "sort by title")),
+			SortAge:     key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "sort by age")),
+			SortSource:  key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by source")),
+			SortMargin:  key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "sort by margin")),
+			AgeFormat:   key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "toggle relative/absolute age")),
+			Select:      key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select listing")),
+			SelectAll:   key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "select all / clear selection")),
+			ExportCSV:   key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export selection (or all) as CSV")),
+			ExportJSON:  key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "export selection (or all) as JSON")),
+			Filter:      key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+			ClearFilter: key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "clear filter")),
+			Source:      key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "cycle source filter")),
+			Group:       key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "group by source")),
+			Collapse:    key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "collapse the selected group")),
+			ExpandAll:   key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "expand all groups")),
+			NextGroup:   key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next group")),
+			PrevGroup:   key.NewBinding(key.WithKeys("["), key.WithHelp("[", "previous group")),
+			Add:         key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "add a listing by hand")),
+			Refresh:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		},
+		Detail: detailKeys{
+			Close: key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "close")),
+			Open:  key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open in browser")),
+			Note:  key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "add or edit note")),
+		},
+		Note: noteKeys{
+			Save:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save note, empty removes it")),
+			Cancel: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		},
+		Filter: filterKeys{
+			PrevField: key.NewBinding(key.WithKeys("up", "shift+tab"), key.WithHelp("↑/shift+tab", "previous field")),
+			NextField: key.NewBinding(key.WithKeys("down", "tab"), key.WithHelp("↓/tab", "next field")),
+			Apply:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "apply")),
+			Close:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+		},
+		AddListing: addListingKeys{
+			PrevField: key.NewBinding(key.WithKeys("up", "shift+tab"), key.WithHelp("↑/shift+tab", "previous field")),
+			NextField: key.NewBinding(key.WithKeys("down", "tab"), key.WithHelp("↓/tab", "next field")),
+			Submit:    key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "submit")),
+			Close:     key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		},
+		Confirm: confirmKeys{
+			Yes: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "confirm")),
+			No:  key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "cancel")),
+		},
+		Startup: startupKeys{
+			Retry: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "retry")),
+		},
+		ErrorLog: errorLogKeys{
+			Up:   nav("up", "k", "scroll to newer errors"),
+			Down: nav("down", "j", "scroll to older errors"),
+		},
+		Stats: statsKeys{
+			PrevItem:  nav("left", "h", "chart previous item"),
+			NextItem:  nav("right", "l", "chart next item"),
+			Export:    key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export charted item's price history")),
+			ExportAll: key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "export all price history")),
+			Range:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "cycle time range")),
+			Refresh:   key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		},
+		Config: configKeys{
+			Up:         nav("up", "k", "previous field or config"),
+			Down:       nav("down", "j", "next field or config"),
+			Top:        nav("home", "g", "first config"),
+			Bottom:     nav("end", "G", "last config"),
+			HalfPageUp: halfPageUp,
+			HalfPageDn: halfPageDown,
+			PrevTheme:  nav("left", "h", "previous theme"),
+			NextTheme:  nav("right", "l", "next theme"),
+			Save:       key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "save config")),
+			Load:       key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "load config")),
+			Apply:      key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "apply API settings")),
+			Test:       key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "test API URL")),
+			Prune:      key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "prune cache")),
+			Export:     key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export database")),
+			Delete:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete config")),
+			Clear:      key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "clear local data, keeping configs")),
+			ClearAll:   key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "clear local data and configs")),
+			Compact:    key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "compact database")),
+			Refresh:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+			VimToggle:  key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "toggle vim navigation")),
+			Notify:     key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "toggle desktop notifications")),
+		},
+		Comps: compsKeys{
+			Up:         key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "move up")),
+			Down:       key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "move down")),
+			HalfPageUp: halfPageUp,
+			HalfPageDn: halfPageDown,
+			Fetch:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "fetch comps")),
+		},
+		Watchlist: watchlistKeys{
+			Up:         nav("up", "k", "move up"),
+			Down:       nav("down", "j", "move down"),
+			Top:        nav("home", "g", "first item"),
+			Bottom:     nav("end", "G", "last item"),
+			HalfPageUp: halfPageUp,
+			HalfPageDn: halfPageDown,
+			Remove:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "stop watching")),
+			Refresh:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reload prices")),
+		},
+		Favorites: favoritesKeys{
+			Up:         nav("up", "k", "move up"),
+			Down:       nav("down", "j", "move down"),
+			Top:        nav("home", "g", "first favorite"),
+			Bottom:     nav("end", "G", "last favorite"),
+			HalfPageUp: halfPageUp,
+			HalfPageDn: halfPageDown,
+			Open:       key.NewBinding(key.WithKeys("enter", "o"), key.WithHelp("enter/o", "open in browser")),
+			Remove:     key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "remove favorite")),
+		},
+	}
+}
+
+// navMatches is key.Matches for navigation in panes with text inputs: while
+// typing, letters go to the input instead of navigating
+func navMatches(msg tea.KeyMsg, typing bool, b key.Binding) bool {
+	if typing && msg.Type == tea.KeyRunes {
+		return false
+	}
+	return key.Matches(msg, b)
+}
+
+// keySection is one titled group of bindings in the help overlay
+type keySection struct {
+	title    string
+	bindings []key.Binding
+}
+
+// sections lists the enabled bindings of every group, in declaration order
+func (k keyMap) sections() []keySection {
+	v := reflect.ValueOf(k)
+	sections := make([]keySection, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		section := keySection{title: v.Type().Field(i).Tag.Get("help")}
+		group := v.Field(i)
+		for j := 0; j < group.NumField(); j++ {
+			if b, ok := group.Field(j).Interface().(key.Binding); ok && b.Enabled() {
+				section.bindings = append(section.bindings, b)
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
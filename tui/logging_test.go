@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureLog sends logger to a file in a temporary directory for the rest of
+// the test and returns a function reading what was logged
+func captureLog(t *testing.T, verbose bool) func() string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), logFileName)
+	orig := logger
+	f, err := setupLogging(path, verbose)
+	if err != nil {
+		t.Fatalf("Failed to set up logging: %v", err)
+	}
+	t.Cleanup(func() {
+		logger = orig
+		f.Close()
+	})
+
+	return func() string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read log: %v", err)
+		}
+		return string(data)
+	}
+}
+
+func TestAPIFailuresAreLogged(t *testing.T) {
+	read := captureLog(t, false)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/sources" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAPIClient(server.URL)
+	if _, err := client.GetSources(); err == nil {
+		t.Fatal("Expected the request to fail")
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("Failed to ping: %v", err)
+	}
+
+	log := read()
+	for _, want := range []string{"level=WARN", `msg="API request failed"`, "path=/api/sources", `status="500 Internal Server Error"`, "request_id="} {
+		if !strings.Contains(log, want) {
+			t.Errorf("Expected %q in the log:\n%s", want, log)
+		}
+	}
+	// Successful requests are debug records, left out without --verbose
+	if strings.Count(log, "\n") != 1 {
+		t.Errorf("Expected only the failure to be logged:\n%s", log)
+	}
+}
+
+func TestVerboseLogsActions(t *testing.T) {
+	read := captureLog(t, true)
+
+	m := newTestModel("http://localhost:8080")
+	m.Update(keyMsg("tab"))
+
+	if log := read(); !strings.Contains(log, `msg="switch pane" pane=Stats`) {
+		t.Errorf("Expected the pane switch to be logged:\n%s", log)
+	}
+}
+
+func TestLogFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), logFileName)
+	f, err := openRotatingFile(path, 64)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer f.Close()
+
+	first := strings.Repeat("a", 40) + "\n"
+	second := strings.Repeat("b", 40) + "\n"
+	for _, line := range []string{first, second} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Failed to write log: %v", err)
+		}
+	}
+
+	if data, _ := os.ReadFile(path + ".1"); string(data) != first {
+		t.Errorf("Expected the first line to be rotated out, got %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != second {
+		t.Errorf("Expected the second line in a fresh file, got %q", data)
+	}
+}
@@ -1,13 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// version identifies the build; release builds set it with
+// -ldflags "-X main.version=..."
+var version = "dev"
+
+// paneNames are the tab labels, in currentPane order
+var paneNames = []string{"Search", "Results", "Stats", "Config", "Comps", "Watchlist", "Favorites"}
+
 // Main model for the application
 type model struct {
 	currentPane int
@@ -17,96 +32,504 @@ type model struct {
 	results     *ResultsPane
 	stats       *StatsPane
 	config      *ConfigPane
+	comps       *CompsPane
+	watchlist   *WatchlistPane
+	favorites   *FavoritesPane
 	db          *Database
+	apiClient   *APIClient
+
+	// offline is set while the API is unreachable; searches and refreshes
+	// are then served from the local cache
+	offline bool
+	// retrying is set while a connectivity re-check is scheduled
+	retrying bool
+	// pinged is set once the API has been pinged at least once
+	pinged bool
+	// lastRefresh is when results or statistics were last loaded
+	lastRefresh time.Time
+	// autoRefresh is set while the active pane re-fetches on a timer;
+	// autoRefreshGen tells current ticks from ones scheduled before a toggle
+	autoRefresh    bool
+	autoRefreshGen int
+	// cachedPrices maps listing URLs cached this run to their cached price
+	cachedPrices map[string]float64
+
+	// showingHelp is set while the key binding overlay is open
+	showingHelp bool
+
+	// errorLog keeps the errors shown this session, reviewed in an overlay
+	// while showingErrors is set; paneErrors is the error each pane showed
+	// after the last update, in paneNames order
+	errorLog      errorLog
+	showingErrors bool
+	paneErrors    []string
+
+	// toasts are the notifications on screen, oldest first; toastSeq
+	// numbers them so each expiry removes the right one
+	toasts   []toast
+	toastSeq int
+
+	// startupErr is set while a fatal startup failure is shown in place of
+	// the panes
+	startupErr *startupError
+
+	// cancelSearch aborts the in-flight search request, if any
+	cancelSearch context.CancelFunc
+	// searchID is the ID of the last search issued; results of earlier
+	// searches that complete late are dropped
+	searchID int
+
+	// unreadAlerts is the number of price alerts not yet seen in the
+	// Watchlist pane
+	unreadAlerts int
 }
 
 // Initialize the model
-func initialModel() model {
-	db := NewDatabase()
+func initialModel(db *Database) model {
+	apiClient := NewAPIClient("")
 	search := NewSearchPane()
-	results := NewResultsPane()
-	stats := NewStatsPane()
+	results := NewResultsPane(apiClient)
+	stats := NewStatsPane(apiClient)
 	config := NewConfigPane()
-	
-	// Set database references
-	stats.db = db
-	config.db = db
-	
-	return model{
-		currentPane: 0,
-		search:      search,
-		results:     results,
-		stats:       stats,
-		config:      config,
-		db:          db,
+	comps := NewCompsPane(apiClient)
+	watchlist := NewWatchlistPane()
+	favorites := NewFavoritesPane()
+
+	config.apiClient = apiClient
+
+	m := model{
+		currentPane:  0,
+		search:       search,
+		results:      results,
+		stats:        stats,
+		config:       config,
+		comps:        comps,
+		watchlist:    watchlist,
+		favorites:    favorites,
+		apiClient:    apiClient,
+		cachedPrices: make(map[string]float64),
 	}
+	m.attachDatabase(db)
+	return m
+}
+
+// attachDatabase hands db to the model and the panes that use it, and loads
+// what they show from it
+func (m *model) attachDatabase(db *Database) {
+	m.db = db
+	m.search.db = db
+	m.search.loadSuggestions()
+	m.search.loadSession()
+	m.results.db = db
+	m.results.loadSession()
+	m.stats.db = db
+	m.config.db = db
+	m.watchlist.db = db
+	m.watchlist.Load()
+	m.favorites.db = db
+	m.favorites.Load()
+	if db == nil {
+		return
+	}
+	if alerts, err := db.GetUnreadAlerts(); err == nil {
+		m.unreadAlerts = len(alerts)
+	}
+}
+
+// Close closes the model's database, if it has one
+func (m model) Close() error {
+	if m.db == nil {
+		return nil
+	}
+	return m.db.Close()
 }
 
 // Init implements tea.Model
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
-		loadInitialStats(m.stats, m.db),
+		loadInitialStats(m.db, m.apiClient, m.stats.window()),
 		loadInitialConfigs(m.config, m.db),
+		checkConnectivity(m.apiClient),
+		loadSources(m.apiClient),
+		scheduleStatusPing(),
 	)
 }
 
-// Commands for async operations
-func loadInitialStats(pane *StatsPane, db *Database) tea.Cmd {
+// loadSources fetches the providers the API can search, for the search pane
+func loadSources(client *APIClient) tea.Cmd {
 	return func() tea.Msg {
-		pane.LoadStats(db)
-		return nil
+		sources, err := client.GetSources()
+		return SourcesLoadedMsg{Sources: sources, Error: err}
 	}
 }
 
+// loadInitialStats collects the statistics of window off the UI goroutine,
+// reporting them in a StatsLoadedMsg
+func loadInitialStats(db *Database, client *APIClient, window time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		return collectStats(db, client, false, window)
+	}
+}
+
+// loadInitialConfigs loads the saved configs. The database has just been
+// opened, so failing to read it is reported as a startup error.
 func loadInitialConfigs(pane *ConfigPane, db *Database) tea.Cmd {
 	return func() tea.Msg {
-		pane.LoadConfigs(db)
+		if err := pane.LoadConfigs(db); err != nil {
+			return StartupErrorMsg{Action: "load saved configs", Error: err, Retry: loadInitialConfigs(pane, db)}
+		}
 		return nil
 	}
 }
 
-// Update implements tea.Model
+// Update implements tea.Model. Errors the update leaves on a pane are
+// recorded in the error log.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	m.logPaneErrors()
+	return m, cmd
+}
+
+func (m model) update(msg tea.Msg) (model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		return m, nil
 
+	case StartupErrorMsg:
+		logger.Error("startup failed", "action", msg.Action, "error", msg.Error)
+		m.startupErr = &startupError{action: msg.Action, err: msg.Error, retry: msg.Retry}
+		m.errorLog.push(errorEntry{at: time.Now(), source: "Startup", text: fmt.Sprintf("Failed to %s: %v", msg.Action, msg.Error)})
+		return m, nil
+
+	case DatabaseOpenedMsg:
+		m.attachDatabase(msg.DB)
+		return m, tea.Batch(loadInitialStats(m.db, m.apiClient, m.stats.window()), loadInitialConfigs(m.config, m.db))
+	}
+
+	// The startup error modal swallows input until it is retried or the
+	// user quits
+	if m.startupErr != nil {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, keys.Startup.Retry) && m.startupErr.retry != nil:
+				retry := m.startupErr.retry
+				m.startupErr = nil
+				return m, retry
+			case key.Matches(msg, keys.Global.Quit):
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if _, ok := msg.(tea.MouseMsg); ok {
+			return m, nil
+		}
+	}
+
+	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		return m, m.handleMouse(msg)
+
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// The help overlay opens from any pane and swallows keys until closed,
+		// leaving the pane underneath as it was
+		if m.showingHelp {
+			switch {
+			case msg.String() == "ctrl+c":
+				return m, tea.Quit
+			case key.Matches(msg, keys.Global.Help, keys.Global.Cancel):
+				m.showingHelp = false
+			}
+			return m, nil
+		}
+		if key.Matches(msg, keys.Global.Help) && !m.typing() {
+			m.showingHelp = true
+			return m, nil
+		}
+
+		// So does the error log
+		if m.showingErrors {
+			return m, m.updateErrorLog(msg)
+		}
+		if key.Matches(msg, keys.Global.Errors) {
+			m.showingErrors = true
+			m.errorLog.offset = 0
+			return m, nil
+		}
+
+		// Overlays and the filter bar capture all keys until dismissed
+		if m.results.capturingInput() && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			*m.results, cmd = m.results.Update(msg)
+			return m, cmd
+		}
+		if m.config.capturingInput() && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			*m.config, cmd = m.config.Update(msg)
+			return m, tea.Batch(cmd, m.toastPaneSuccess())
+		}
+
+		switch {
+		case key.Matches(msg, keys.Global.Quit) && (!m.typing() || msg.String() == "ctrl+c"):
+			// The database is closed once the program has exited
+			m.abortSearch()
 			return m, tea.Quit
 
-		case "tab":
-			m.currentPane = (m.currentPane + 1) % 4
+		case key.Matches(msg, keys.Global.NextPane):
+			m.switchPane((m.currentPane + 1) % len(paneNames))
 			return m, nil
 
-		case "shift+tab":
-			m.currentPane = (m.currentPane - 1 + 4) % 4
+		case key.Matches(msg, keys.Global.GoToPane) && !m.typing():
+			m.switchPane(int(msg.Runes[0] - '1'))
 			return m, nil
+
+		case key.Matches(msg, keys.Global.AutoRefresh) && !m.typing():
+			return m, m.toggleAutoRefresh()
+
+		case key.Matches(msg, keys.Global.RefreshAll):
+			return m, m.refreshAll()
+
+		case key.Matches(msg, keys.Global.PrevPane):
+			m.switchPane((m.currentPane - 1 + len(paneNames)) % len(paneNames))
+			return m, nil
+
+		case key.Matches(msg, keys.Global.Cancel):
+			if m.cancelSearch != nil {
+				m.abortSearch()
+				return m, nil
+			}
 		}
 	}
 
 	// Handle custom messages
 	switch msg := msg.(type) {
 	case SearchMsg:
-		// Trigger search in API
-		return m, performSearch(msg, m.results)
-	
+		// Trigger search in API; later refreshes stay scoped to the provider
+		m.results.source = msg.Provider
+		m.results.threshold = msg.Threshold
+		m.results.searchFilter = msg.Filter
+		// The long-term trend follows the provider too
+		var trend tea.Cmd
+		if source := trendSource(msg.Provider); source != m.stats.source {
+			m.stats.source = source
+			trend = m.stats.loadTrend()
+		}
+		if m.cancelSearch != nil {
+			m.cancelSearch()
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelSearch = cancel
+		m.searchID++
+		msg.ID = m.searchID
+		logger.Debug("search", "query", msg.Query, "provider", msg.Provider, "threshold", msg.Threshold)
+		return m, tea.Batch(performSearch(ctx, msg, m.apiClient, m.db, m.offline), trend)
+
 	case SearchResultMsg:
+		// Aborted or superseded searches have nothing to report
+		if errors.Is(msg.Error, context.Canceled) {
+			return m, nil
+		}
+		if !msg.Refresh && msg.ID != m.searchID {
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		if msg.Offline {
+			cmd = m.setOffline(true)
+		}
+
 		// Update results pane
 		if msg.Error == nil {
-			m.results.SetResults(msg.Results)
+			if !msg.Refresh {
+				m.results.SetComps(msg.Comps)
+			}
+			m.results.maxRows = m.config.maxRows
+			if !msg.Refresh {
+				_, m.results.filter.excludes = parseQuery(m.search.lastQuery)
+			}
+			// Only a new search starts again from the top
+			if msg.Refresh {
+				m.results.RefreshResults(DedupeListings(msg.Results))
+			} else {
+				m.results.SetResults(DedupeListings(msg.Results))
+			}
+			m.results.query, m.results.total = msg.Query, msg.Total
+			m.results.notice = ""
+			if msg.Partial != nil {
+				logger.Warn("some sources failed", "query", msg.Query, "error", msg.Partial)
+				m.results.notice = "Some sources failed, showing the rest: " + describeError(msg.Partial)
+			}
+			if msg.Offline {
+				m.results.notice = "API unreachable - showing cached listings"
+			} else if !msg.Local {
+				m.lastRefresh = time.Now()
+				// As-you-type results are a preview of a query still being
+				// typed, so only a submitted search is recorded
+				if !msg.Live {
+					cmd = tea.Batch(cmd, cacheListings(m.db, msg.Results, m.cachedPrices))
+				}
+			}
 			// Save to database
-			if m.db != nil {
+			if m.db != nil && !msg.Refresh && !msg.Live {
 				_ = m.db.SaveSearchHistory(m.search.lastQuery, len(msg.Results))
+				m.search.loadSuggestions()
 			}
 		} else {
-			m.results.lastError = msg.Error.Error()
+			m.results.lastError = describeError(msg.Error)
+			m.results.loading = false
+		}
+		if !msg.Refresh {
+			m.search.searching = false
+			if m.cancelSearch != nil {
+				m.cancelSearch()
+				m.cancelSearch = nil
+			}
+		}
+		return m, cmd
+
+	case DataClearedMsg:
+		// Listings seen before the clear are cached again when next seen
+		m.cachedPrices = make(map[string]float64)
+		m.search.loadSuggestions()
+		return m, m.refreshStats()
+
+	case StatsLoadedMsg:
+		m.stats.ApplyStats(msg)
+		m.lastRefresh = time.Now()
+		return m, m.stats.loadTrend()
+
+	case PriceTrendMsg:
+		m.stats.ApplyTrend(msg)
+		return m, nil
+
+	case MoreResultsMsg:
+		// A new search replaced the one this page belongs to
+		if msg.Query != m.results.query {
+			return m, nil
+		}
+		m.results.loadingMore = false
+		if msg.Error != nil {
+			m.results.lastError = describeError(msg.Error)
+			return m, nil
+		}
+		m.results.AppendResults(msg.Results, msg.Total)
+		return m, cacheListings(m.db, msg.Results, m.cachedPrices)
+
+	case ListingCreatedMsg:
+		cmd := m.results.ApplyCreated(msg)
+		if msg.Error == nil {
+			cmd = tea.Batch(cmd, m.pushToast(fmt.Sprintf("Added '%s'", msg.Listing.Title), severitySuccess))
+		}
+		return m, cmd
+
+	case SourcesLoadedMsg:
+		// Older servers have no provider list, so the defaults stay
+		if msg.Error == nil && len(msg.Sources) > 0 {
+			m.search.SetProviders(msg.Sources)
+		}
+		return m, nil
+
+	case ListingDetailMsg:
+		m.results.ApplyDetail(msg)
+		return m, nil
+
+	case NoteSavedMsg:
+		m.results.ApplyNote(msg)
+		return m, nil
+
+	case ConnectionTestMsg:
+		m.config.ApplyConnectionTest(msg)
+		return m, nil
+
+	case ConnectivityMsg:
+		m.retrying = false
+		m.pinged = true
+		return m, m.setOffline(!msg.Online)
+
+	case toastMsg:
+		return m, m.pushToast(msg.text, msg.severity)
+
+	case toastExpiredMsg:
+		m.expireToast(msg.id)
+		return m, nil
+
+	case noticeExpiredMsg:
+		if m.results.notice == msg.notice {
+			m.results.notice = ""
 		}
-		m.search.searching = false
 		return m, nil
+
+	case autoRefreshMsg:
+		return m, m.handleAutoRefresh(msg)
+
+	case spinner.TickMsg:
+		// Each spinner only accepts its own ticks, so every pane sees them all
+		return m, tea.Batch(
+			tickSpinner(&m.search.spinner, m.search.searching, msg),
+			tickSpinner(&m.results.spinner, m.results.loading, msg),
+			tickSpinner(&m.stats.spinner, m.stats.loading, msg),
+			tickSpinner(&m.config.spinner, m.config.loading, msg),
+			tickSpinner(&m.comps.spinner, m.comps.loading, msg),
+		)
+
+	case statusPingMsg:
+		return m, tea.Batch(checkConnectivity(m.apiClient), scheduleStatusPing())
+
+	case ConfigLoadedMsg:
+		m.config.ApplyConfigs(msg)
+		return m, nil
+
+	case CompsLoadedMsg:
+		if msg.Error == nil {
+			m.comps.SetComps(msg.Comps)
+		} else {
+			m.comps.lastError = describeError(msg.Error)
+			m.comps.loading = false
+		}
+		return m, nil
+
+	case SearchSavedMsg:
+		if msg.Error != nil {
+			return m, m.pushToast(msg.Error.Error(), severityError)
+		}
+		m.config.LoadConfigs(m.db)
+		return m, m.pushToast(fmt.Sprintf("Saved '%s'", msg.Name), severitySuccess)
+
+	case FavoriteToggledMsg:
+		if msg.Error != nil {
+			return m, m.pushToast(msg.Error.Error(), severityError)
+		}
+		m.favorites.Load()
+		if !msg.Added {
+			return m, m.pushToast(fmt.Sprintf("Removed '%s' from favorites", msg.Title), severitySuccess)
+		}
+		return m, m.pushToast(fmt.Sprintf("Added '%s' to favorites", msg.Title), severitySuccess)
+
+	case RunSavedSearchMsg:
+		m.switchPane(0)
+		return m, m.search.RunSavedSearch(msg)
+
+	case WatchlistChangedMsg:
+		if msg.Error != nil {
+			return m, m.pushToast(msg.Error.Error(), severityError)
+		}
+		m.watchlist.Load()
+		return m, m.pushToast(fmt.Sprintf("Watching '%s'", msg.Title), severitySuccess)
+
+	case AlertsMsg:
+		m.watchlist.Load()
+		if alerts, err := m.db.GetUnreadAlerts(); err == nil {
+			m.unreadAlerts = len(alerts)
+		}
+		var cmds []tea.Cmd
+		if m.config.notify {
+			cmds = append(cmds, notifyAlerts(msg.Items))
+		}
+		for _, item := range msg.Items {
+			cmds = append(cmds, m.pushToast("🔔 "+alertText(item), severitySuccess))
+		}
+		return m, tea.Batch(cmds...)
 	}
 
 	// Update the current pane
@@ -114,38 +537,194 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.currentPane {
 	case 0:
 		*m.search, cmd = m.search.Update(msg)
-		// Check if search was triggered
-		if m.search.lastQuery != "" && m.search.searching {
-			// Send search message
-			return m, func() tea.Msg {
-				return SearchMsg{
-					Query:     m.search.lastQuery,
-					Provider:  m.search.providers[m.search.providerSelect],
-					Threshold: 20.0, // Default threshold
-				}
-			}
-		}
 	case 1:
 		*m.results, cmd = m.results.Update(msg)
 	case 2:
 		*m.stats, cmd = m.stats.Update(msg)
 	case 3:
 		*m.config, cmd = m.config.Update(msg)
+		m.results.maxRows = m.config.maxRows
+	case 4:
+		*m.comps, cmd = m.comps.Update(msg)
+	case 5:
+		*m.watchlist, cmd = m.watchlist.Update(msg)
+	case 6:
+		*m.favorites, cmd = m.favorites.Update(msg)
 	}
 
-	return m, cmd
+	return m, tea.Batch(cmd, m.toastPaneSuccess())
+}
+
+// describeError turns an API error into a message suitable for the UI
+func describeError(err error) string {
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return fmt.Sprintf("%s - check your credentials in the Config pane", authErr.Status)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusNotFound:
+			return fmt.Sprintf("not found (%s) - check the API URL in the Config pane", apiErr.Status)
+		case apiErr.StatusCode == http.StatusForbidden:
+			return fmt.Sprintf("access denied (%s)", apiErr.Status)
+		case apiErr.StatusCode >= 500:
+			return fmt.Sprintf("server error (%s) - try again later", apiErr.Status)
+		}
+	}
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		if errors.Is(decodeErr, io.ErrUnexpectedEOF) {
+			return fmt.Sprintf("%s - the connection may have dropped, try again", decodeErr)
+		}
+		return fmt.Sprintf("%s - check the API URL in the Config pane points at an ArbFinder API", decodeErr)
+	}
+
+	return err.Error()
+}
+
+// setOffline switches between the API and the local cache. While offline it
+// schedules a re-check that flips the session back online once the API
+// answers again.
+func (m *model) setOffline(offline bool) tea.Cmd {
+	m.offline = offline
+	m.results.offline = offline
+	if !offline || m.retrying {
+		return nil
+	}
+	m.retrying = true
+	return retryConnectivity(m.apiClient)
+}
+
+// switchPane makes pane the current pane, abandoning any search in flight.
+// Opening the Watchlist marks its price alerts read.
+func (m *model) switchPane(pane int) {
+	m.abortSearch()
+	m.currentPane = pane
+	logger.Debug("switch pane", "pane", paneNames[pane])
+	if pane == 5 && m.unreadAlerts > 0 {
+		if err := m.db.MarkAlertsRead(); err == nil {
+			m.unreadAlerts = 0
+		}
+	}
+}
+
+// typing reports whether the current pane has a text field focused, in which
+// case plain keys such as digits belong to the field
+func (m *model) typing() bool {
+	switch m.currentPane {
+	case 0:
+		return m.search.typing()
+	case 3:
+		return m.config.typing()
+	case 4:
+		return m.comps.queryInput.Focused()
+	}
+	return false
+}
+
+// abortSearch cancels the in-flight search request, if any
+func (m *model) abortSearch() {
+	if m.cancelSearch == nil {
+		return
+	}
+	m.cancelSearch()
+	m.cancelSearch = nil
+	m.search.searching = false
 }
 
-// performSearch executes a search query via the API
-func performSearch(msg SearchMsg, results *ResultsPane) tea.Cmd {
+// searchPageSize is the number of listings requested per page of a search
+const searchPageSize = 50
+
+// manualProvider holds manually imported listings, which only exist in the
+// local cache
+const manualProvider = "manual"
+
+// allProvider searches every other provider at once, one request each
+const allProvider = "all"
+
+// performSearch executes a search query via the API, scoped to the chosen
+// provider, falling back to the local cache when offline or when the API
+// turns out to be unreachable. Manual imports are always searched locally.
+func performSearch(ctx context.Context, msg SearchMsg, client *APIClient, db *Database, offline bool) tea.Cmd {
 	return func() tea.Msg {
-		// Perform API search
-		listings, err := results.apiClient.SearchListings(msg.Query)
+		result := runSearch(ctx, msg, client, db, offline)
+		result.ID, result.Live = msg.ID, msg.Live
+		return result
+	}
+}
+
+// runSearch carries out a search for performSearch
+func runSearch(ctx context.Context, msg SearchMsg, client *APIClient, db *Database, offline bool) SearchResultMsg {
+	// "-term" exclusions are filtered out of the results by the results pane
+	query := serverQuery(msg.Query)
+	if msg.Provider == manualProvider {
+		if db == nil {
+			return SearchResultMsg{Error: fmt.Errorf("manual listings need the local database")}
+		}
+		listings, err := searchCache(db, ListingQuery{Query: query, Source: manualProvider, Limit: refreshLimit})
+		return SearchResultMsg{
+			Results: listings,
+			Error:   err,
+			Local:   true,
+		}
+	}
+
+	var page APIResponse
+	var err, partial error
+	var comps <-chan []APIComp
+	if !offline {
+		// Comps are fetched alongside the listings rather than after them,
+		// and abandoned if the search itself fails
+		compsCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		comps = fetchComps(compsCtx, client, query)
+
+		if msg.Provider == allProvider && len(msg.Sources) > 0 {
+			var listings []APIListing
+			listings, err = client.SearchAllSourcesCtx(ctx, query, msg.Sources, msg.Filter)
+			page = APIResponse{Items: listings, Total: len(listings)}
+			if err != nil && len(listings) > 0 {
+				// Some sources answered, so the search stands
+				partial, err = err, nil
+			}
+		} else {
+			page, err = client.SearchListingsPageCtx(ctx, query, msg.Provider, msg.Filter, searchPageSize, 0)
+		}
+	}
+	if db != nil && (offline || isUnreachable(err)) {
+		listings, err := fuzzySearchCache(db, query, refreshLimit)
 		return SearchResultMsg{
 			Results: listings,
 			Error:   err,
+			Offline: true,
 		}
 	}
+	if err != nil {
+		return SearchResultMsg{Error: err}
+	}
+
+	return SearchResultMsg{
+		Query:   query,
+		Results: page.Items,
+		Total:   page.Total,
+		Comps:   <-comps,
+		Partial: partial,
+	}
+}
+
+// fetchComps looks up comps for query in the background and delivers them on
+// the returned channel. Comps only enrich the results with margins and a
+// price summary, so a failure delivers nil rather than an error.
+func fetchComps(ctx context.Context, client *APIClient, query string) <-chan []APIComp {
+	ch := make(chan []APIComp, 1)
+	go func() {
+		comps, _ := client.GetCompsCtx(ctx, query)
+		ch <- comps
+	}()
+	return ch
 }
 
 // View implements tea.Model
@@ -157,61 +736,87 @@ func (m model) View() string {
 	// Define styles
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
-		Background(lipgloss.Color("#1a1a1a")).
+		Foreground(theme.Primary).
+		Background(theme.TitleBg).
 		Padding(0, 1)
 
 	activeTabStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(lipgloss.Color("#7D56F4")).
-		Padding(0, 2)
+		Foreground(theme.Text).
+		Background(theme.SelectedBg).
+		Padding(0, tabPadding)
 
 	inactiveTabStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		Background(lipgloss.Color("#1a1a1a")).
-		Padding(0, 2)
+		Foreground(theme.Subtle).
+		Background(theme.TitleBg).
+		Padding(0, tabPadding)
+
+	if m.startupErr != nil {
+		return m.startupErr.View(m.width, m.height)
+	}
+
+	// The detail overlay takes over the whole screen
+	if m.results.showingDetail {
+		return m.results.View(m.width, m.height)
+	}
 
 	// Build title
 	title := titleStyle.Render("🔍 ArbFinder Suite - Interactive TUI")
+	if m.offline {
+		offlineStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Text).
+			Background(theme.Error).
+			Padding(0, 1)
+		title += " " + offlineStyle.Render("OFFLINE")
+	}
 
 	// Build tabs
-	tabs := []string{"Search", "Results", "Stats", "Config"}
 	tabsStr := ""
-	for i, tab := range tabs {
+	for i, tab := range paneNames {
 		if i == m.currentPane {
 			tabsStr += activeTabStyle.Render(tab)
 		} else {
 			tabsStr += inactiveTabStyle.Render(tab)
 		}
-		if i < len(tabs)-1 {
+		if i < len(paneNames)-1 {
 			tabsStr += " "
 		}
 	}
 
 	// Build content based on current pane
 	var content string
-	contentHeight := m.height - 6 // Reserve space for title, tabs, and help
+	contentHeight := m.height - 7 // Reserve space for title, tabs, help and status bar
 
-	switch m.currentPane {
-	case 0:
+	switch {
+	case m.showingHelp:
+		content = NewHelpView(keys).View(m.width, contentHeight)
+	case m.showingErrors:
+		content = m.errorLog.View(m.width, contentHeight)
+	case m.currentPane == 0:
 		content = m.search.View(m.width, contentHeight)
-	case 1:
+	case m.currentPane == 1:
 		content = m.results.View(m.width, contentHeight)
-	case 2:
+	case m.currentPane == 2:
 		content = m.stats.View(m.width, contentHeight)
-	case 3:
+	case m.currentPane == 3:
 		content = m.config.View(m.width, contentHeight)
+	case m.currentPane == 4:
+		content = m.comps.View(m.width, contentHeight)
+	case m.currentPane == 5:
+		content = m.watchlist.View(m.width, contentHeight)
+	case m.currentPane == 6:
+		content = m.favorites.View(m.width, contentHeight)
 	}
 
 	// Help text
 	helpStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Muted).
 		Padding(0, 1)
-	help := helpStyle.Render("Tab: Switch Pane • Ctrl+C/Q: Quit • Enter: Execute • ↑/↓: Navigate")
+	help := helpStyle.Render("Tab/1-7: Switch Pane • Ctrl+C/Q: Quit • Enter: Execute • ↑/↓: Navigate • ?: Help • Ctrl+E: Errors")
 
 	// Combine all elements
-	return lipgloss.JoinVertical(
+	view := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
 		tabsStr,
@@ -219,13 +824,81 @@ func (m model) View() string {
 		content,
 		"",
 		help,
+		m.statusBar(),
 	)
+
+	// Notifications float over the top right corner of the pane
+	if len(m.toasts) > 0 {
+		view = overlayRight(view, m.toastsView(min(toastWidth, m.width/2)), contentTop, m.width)
+	}
+	return view
 }
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	opts, err := parseFlags(os.Args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	cfg, err := loadAppConfig(opts, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Logging must never reach the terminal once the UI is up, so a log
+	// file that can't be opened is reported now and logging left off
+	if path, err := logPath(); err != nil {
+		fmt.Fprintf(os.Stderr, "Logging disabled: %v\n", err)
+	} else if logFile, err := setupLogging(path, opts.verbose); err != nil {
+		fmt.Fprintf(os.Stderr, "Logging disabled: %v\n", err)
+	} else {
+		defer logFile.Close()
+	}
+
+	if opts.query != "" {
+		if err := searchOnce(NewAPIClient(cfg.APIURL), opts, cfg, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// A database that can't be opened is shown in the startup error modal,
+	// which can retry it
+	db, err := NewDatabase()
+	m := initialModel(db)
+	m.applyAppConfig(cfg)
+	if err != nil {
+		m.startupErr = &startupError{action: "open the database", err: err, retry: openDatabase}
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if err := run(p, os.Stderr); err != nil {
+		logger.Error("program failed", "error", err)
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// programRunner is the part of tea.Program that run needs
+type programRunner interface {
+	Run() (tea.Model, error)
+}
+
+// run runs the program and then closes the database of the model it ended
+// with, however the program ended, so SQLite can checkpoint its WAL. The
+// database may have been opened by a retry after startup, so it is taken
+// from the final model. A close error is reported on stderr rather than
+// hiding the program's own error.
+func run(p programRunner, stderr io.Writer) error {
+	final, err := p.Run()
+	if db, ok := final.(io.Closer); ok {
+		if closeErr := db.Close(); closeErr != nil {
+			fmt.Fprintf(stderr, "Error closing database: %v\n", closeErr)
+		}
+	}
+	return err
+}
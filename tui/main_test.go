@@ -0,0 +1,530 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel builds a model without a database whose panes share a client
+// talking to the given API base URL.
+func newTestModel(baseURL string) model {
+	apiClient := NewAPIClient(baseURL)
+	config := NewConfigPane()
+	config.apiClient = apiClient
+
+	return model{
+		currentPane:  1,
+		width:        120,
+		height:       40,
+		search:       NewSearchPane(),
+		results:      NewResultsPane(apiClient),
+		stats:        NewStatsPane(apiClient),
+		config:       config,
+		comps:        NewCompsPane(apiClient),
+		watchlist:    NewWatchlistPane(),
+		favorites:    NewFavoritesPane(),
+		apiClient:    apiClient,
+		cachedPrices: make(map[string]float64),
+	}
+}
+
+// namedKeys maps key names to the key types the terminal reports for them
+var namedKeys = map[string]tea.KeyType{
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+d":    tea.KeyCtrlD,
+	"ctrl+n":    tea.KeyCtrlN,
+	"ctrl+u":    tea.KeyCtrlU,
+	"ctrl+x":    tea.KeyCtrlX,
+	"ctrl+e":    tea.KeyCtrlE,
+	"ctrl+r":    tea.KeyCtrlR,
+	"ctrl+s":    tea.KeyCtrlS,
+	"f5":        tea.KeyF5,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+}
+
+// keyMsg builds the message for a key press: a named key such as "down", or
+// typed text
+func keyMsg(key string) tea.KeyMsg {
+	if t, ok := namedKeys[key]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// runCmd runs cmd and returns its message. A batch that also starts a
+// loading spinner yields the message of the work it was batched with.
+func runCmd(cmd tea.Cmd) tea.Msg {
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return msg
+	}
+	for _, c := range batch {
+		if c == nil {
+			continue
+		}
+		if msg := c(); !isSpinnerTick(msg) {
+			return msg
+		}
+	}
+	return nil
+}
+
+func isSpinnerTick(msg tea.Msg) bool {
+	_, ok := msg.(spinner.TickMsg)
+	return ok
+}
+
+func TestEscAbortsSearch(t *testing.T) {
+	m := newTestModel("")
+	m.currentPane = 0
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(SearchMsg{Query: "rtx 3060", Provider: "shopgoodwill"})
+	if cmd == nil {
+		t.Fatal("Expected search command, got nil")
+	}
+
+	m = tm.(model)
+	if m.cancelSearch == nil {
+		t.Fatal("Expected search to be cancellable")
+	}
+	m.search.searching = true
+
+	tm, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = tm.(model)
+	if m.cancelSearch != nil {
+		t.Error("Expected cancel func to be cleared")
+	}
+	if m.search.searching {
+		t.Error("Expected searching to be cleared")
+	}
+
+	// The aborted request reports context.Canceled, which is not an error
+	tm, _ = m.Update(SearchResultMsg{Error: context.Canceled})
+	if tm.(model).results.lastError != "" {
+		t.Errorf("Expected no error for cancelled search, got '%s'", tm.(model).results.lastError)
+	}
+}
+
+func TestStaleSearchResultsAreDropped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings/search" {
+			json.NewEncoder(w).Encode([]APIComp{})
+			return
+		}
+		q := r.URL.Query().Get("q")
+		json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{{Title: q + " listing"}}})
+	}))
+	defer server.Close()
+
+	var tm tea.Model = newTestModel(server.URL)
+	tm, _ = tm.Update(SearchMsg{Query: "gpu"})
+	tm, cmd := tm.Update(SearchMsg{Query: "cpu"})
+
+	latest, ok := runCmd(cmd).(SearchResultMsg)
+	if !ok {
+		t.Fatal("Expected SearchResultMsg")
+	}
+	if latest.ID != 2 {
+		t.Fatalf("Expected the second search to have ID 2, got %d", latest.ID)
+	}
+	tm, _ = tm.Update(latest)
+
+	// The first search completes last, as if its cancellation came too late
+	tm, _ = tm.Update(SearchResultMsg{ID: 1, Query: "gpu", Results: []APIListing{{Title: "gpu listing"}}})
+	results := tm.(model).results
+	if len(results.results) != 1 || results.results[0].Title != "cpu listing" {
+		t.Errorf("Expected the cpu results to stay, got %+v", results.results)
+	}
+	if results.query != "cpu" {
+		t.Errorf("Expected query 'cpu', got '%s'", results.query)
+	}
+}
+
+func TestSearchFetchesCompsAlongside(t *testing.T) {
+	compsRequested := make(chan struct{})
+	var concurrent atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/comps/search":
+			close(compsRequested)
+			json.NewEncoder(w).Encode([]APIComp{
+				{KeyTitle: "rtx 3060", AvgPrice: 310, MedianPrice: 300, Count: 42},
+				{KeyTitle: "rtx 3060 ti", AvgPrice: 360, MedianPrice: 350, Count: 17},
+			})
+		case "/api/listings/search":
+			// Answer only once the comps request is in flight too
+			select {
+			case <-compsRequested:
+				concurrent.Store(true)
+			case <-time.After(2 * time.Second):
+			}
+			json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{{ID: 1, Source: "govdeals", Title: "RTX 3060 Founders Edition", Price: 249.99}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var tm tea.Model = newTestModel(server.URL)
+	tm, cmd := tm.Update(SearchMsg{Query: "RTX 3060"})
+	msg, ok := runCmd(cmd).(SearchResultMsg)
+	if !ok {
+		t.Fatal("Expected SearchResultMsg")
+	}
+	if !concurrent.Load() {
+		t.Error("Expected the comps to be requested while the search was in flight")
+	}
+	if len(msg.Results) != 1 || len(msg.Comps) != 2 {
+		t.Fatalf("Expected both listings and comps in the result, got %+v", msg)
+	}
+
+	tm, _ = tm.Update(msg)
+	view := tm.(model).results.View(120, 40)
+	if !strings.Contains(view, "Comps for 'rtx 3060': median $300.00 • avg $310.00 • 42 sales") {
+		t.Errorf("Expected the matching comp above the listings:\n%s", view)
+	}
+	if strings.Index(view, "Comps for") > strings.Index(view, "RTX 3060 Founders Edition") {
+		t.Error("Expected the comp summary before the listing table")
+	}
+}
+
+func TestApplyConfigRedirectsRequests(t *testing.T) {
+	var oldHits, newHits int
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		oldHits++
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer oldServer.Close()
+	newServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" {
+			json.NewEncoder(w).Encode(HealthStatus{Status: "ok"})
+			return
+		}
+		newHits++
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer newServer.Close()
+
+	var tm tea.Model = newTestModel(oldServer.URL)
+
+	// Apply the new URL from the Config pane
+	m := tm.(model)
+	m.currentPane = 3
+	m.config.apiURL.SetValue(newServer.URL + "/")
+	m.config.focusIndex = themeFocus
+	tm, cmd := m.Update(keyMsg("t"))
+	tm, _ = tm.Update(runCmd(cmd))
+	tm, _ = tm.Update(keyMsg("a"))
+	if errMsg := tm.(model).config.lastError; errMsg != "" {
+		t.Fatalf("Failed to apply config: %s", errMsg)
+	}
+
+	// Refresh from the Results pane
+	m = tm.(model)
+	m.currentPane = 1
+	_, cmd = m.Update(keyMsg("r"))
+	runCmd(cmd)
+
+	if oldHits != 0 || newHits != 1 {
+		t.Errorf("Expected 0 old and 1 new request, got %d and %d", oldHits, newHits)
+	}
+}
+
+func TestApplyConfigRejectsMalformedURL(t *testing.T) {
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 3
+	m.config.focusIndex = themeFocus
+
+	for _, raw := range []string{"://localhost:9000", "not a url", "ftp://example.com"} {
+		m.config.apiURL.SetValue(raw)
+		m.Update(keyMsg("a"))
+
+		if m.config.lastError == "" {
+			t.Errorf("Expected error for '%s'", raw)
+		}
+		if got := m.apiClient.BaseURL(); got != "http://localhost:8080" {
+			t.Errorf("Expected base URL to stay 'http://localhost:8080', got '%s'", got)
+		}
+	}
+}
+
+func TestTabCyclesThroughCompsPane(t *testing.T) {
+	var tm tea.Model = newTestModel("")
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if got := tm.(model).currentPane; got != 4 {
+		t.Fatalf("Expected Comps pane (4), got %d", got)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := tm.(model).currentPane; got != 5 {
+		t.Fatalf("Expected Watchlist pane (5), got %d", got)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := tm.(model).currentPane; got != 6 {
+		t.Fatalf("Expected Favorites pane (6), got %d", got)
+	}
+
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if got := tm.(model).currentPane; got != 0 {
+		t.Errorf("Expected to wrap to Search pane (0), got %d", got)
+	}
+}
+
+func TestNumberKeysJumpToPane(t *testing.T) {
+	var tm tea.Model = newTestModel("")
+	for _, tt := range []struct {
+		key  string
+		pane int
+	}{{"3", 2}, {"6", 5}, {"7", 6}, {"2", 1}, {"1", 0}} {
+		tm, _ = tm.Update(keyMsg(tt.key))
+		if got := tm.(model).currentPane; got != tt.pane {
+			t.Fatalf("Expected '%s' to show pane %d, got %d", tt.key, tt.pane, got)
+		}
+	}
+
+	// The Search pane's query field is focused, so digits are typed into it
+	tm, _ = tm.Update(keyMsg("3"))
+	tm, _ = tm.Update(keyMsg("0"))
+	m := tm.(model)
+	if m.currentPane != 0 {
+		t.Fatalf("Expected digits to stay in the query field, switched to pane %d", m.currentPane)
+	}
+	if got := m.search.queryInput.Value(); got != "30" {
+		t.Errorf("Expected query '30', got '%s'", got)
+	}
+
+	// Outside the text fields, on the provider selector, they switch again
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(keyMsg("4"))
+	if got := tm.(model).currentPane; got != 3 {
+		t.Fatalf("Expected '4' to show the Config pane, got %d", got)
+	}
+
+	// The Config pane opens on its name field
+	tm, _ = tm.Update(keyMsg("5"))
+	if got := tm.(model).currentPane; got != 3 {
+		t.Fatalf("Expected to stay on the Config pane while typing, got %d", got)
+	}
+	if got := tm.(model).config.newConfigName.Value(); got != "5" {
+		t.Errorf("Expected config name '5', got '%s'", got)
+	}
+
+	// The Comps pane's filter always has focus
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	tm, _ = tm.Update(keyMsg("2"))
+	if got := tm.(model).currentPane; got != 4 {
+		t.Errorf("Expected to stay on the Comps pane while typing, got %d", got)
+	}
+	if got := tm.(model).comps.queryInput.Value(); got != "2" {
+		t.Errorf("Expected comps filter '2', got '%s'", got)
+	}
+}
+
+func TestLetterKeysTypeIntoFields(t *testing.T) {
+	m := newTestModel("")
+	m.currentPane = 0
+
+	// ? would open the help, R toggle auto-refresh and q quit
+	var tm tea.Model = m
+	for _, r := range "RTX?quad" {
+		tm, _ = tm.Update(keyMsg(string(r)))
+	}
+	m = tm.(model)
+	if got := m.search.queryInput.Value(); got != "RTX?quad" {
+		t.Errorf("Expected query 'RTX?quad', got '%s'", got)
+	}
+	if m.showingHelp || m.autoRefresh {
+		t.Errorf("Expected no global action while typing, got help %v and auto-refresh %v", m.showingHelp, m.autoRefresh)
+	}
+
+	// The Config pane's letter actions wait too
+	m.currentPane = 3
+	tm = m
+	for _, r := range "saved-ld" {
+		tm, _ = tm.Update(keyMsg(string(r)))
+	}
+	m = tm.(model)
+	if got := m.config.newConfigName.Value(); got != "saved-ld" {
+		t.Errorf("Expected config name 'saved-ld', got '%s'", got)
+	}
+	if m.config.lastError != "" || m.config.lastSuccess != "" || m.config.loading {
+		t.Errorf("Expected no config action while typing, got '%s' (error '%s')", m.config.lastSuccess, m.config.lastError)
+	}
+}
+
+func TestCompsPaneFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/comps/search" || r.URL.Query().Get("q") != "rtx" {
+			t.Errorf("Unexpected request '%s'", r.URL)
+		}
+		json.NewEncoder(w).Encode([]APIComp{
+			{KeyTitle: "rtx 3060", AvgPrice: 310, MedianPrice: 300, Count: 12},
+		})
+	}))
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.currentPane = 4
+	m.comps.queryInput.SetValue("rtx")
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected fetch command, got nil")
+	}
+
+	msg, ok := runCmd(cmd).(CompsLoadedMsg)
+	if !ok {
+		t.Fatalf("Expected CompsLoadedMsg")
+	}
+	tm, _ = tm.Update(msg)
+
+	comps := tm.(model).comps
+	if comps.loading {
+		t.Error("Expected loading to be cleared")
+	}
+	if len(comps.comps) != 1 || comps.comps[0].MedianPrice != 300 {
+		t.Errorf("Expected one comp with median 300, got %+v", comps.comps)
+	}
+	if !strings.Contains(tm.View(), "rtx 3060") {
+		t.Error("Expected comp title in view")
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{&AuthError{Status: "401 Unauthorized"}, "check your credentials"},
+		{fmt.Errorf("failed to search listings: %w", &APIError{StatusCode: 404, Status: "404 Not Found"}), "not found"},
+		{&APIError{StatusCode: 502, Status: "502 Bad Gateway"}, "server error"},
+		{&APIError{StatusCode: 400, Status: "400 Bad Request", Body: "bad query"}, "bad query"},
+		{fmt.Errorf("failed to get comps: %w", &DecodeError{Endpoint: "/api/comps", Err: errEmptyBody}), "empty response from /api/comps - check the API URL"},
+		{&DecodeError{Endpoint: "/api/sources", Err: io.ErrUnexpectedEOF}, "truncated response from /api/sources - the connection may have dropped"},
+	}
+
+	for _, tt := range tests {
+		if got := describeError(tt.err); !strings.Contains(got, tt.expected) {
+			t.Errorf("describeError(%v): expected '%s' in '%s'", tt.err, tt.expected, got)
+		}
+	}
+}
+
+func TestHelpOverlayToggles(t *testing.T) {
+	var m tea.Model = newTestModel("")
+	m.(model).results.SetResults([]APIListing{{Title: "RTX 3060"}, {Title: "RTX 3070"}})
+
+	m, _ = m.Update(keyMsg("?"))
+	if !m.(model).showingHelp {
+		t.Fatal("Expected '?' to open the help overlay")
+	}
+	view := m.View()
+	for _, section := range keys.sections() {
+		if !strings.Contains(view, section.title) {
+			t.Errorf("Expected help to list the %s section", section.title)
+		}
+		for _, b := range section.bindings {
+			if !strings.Contains(view, b.Help().Desc) {
+				t.Errorf("Expected help to describe %q", b.Help().Desc)
+			}
+		}
+	}
+
+	// Keys meant for the pane underneath are swallowed while help is open
+	m, _ = m.Update(keyMsg("down"))
+	if m.(model).results.selectedIdx != 0 {
+		t.Errorf("Expected keys to be ignored while help is open, selection moved to %d", m.(model).results.selectedIdx)
+	}
+
+	m, _ = m.Update(keyMsg("?"))
+	if m.(model).showingHelp {
+		t.Error("Expected '?' to close the help overlay")
+	}
+	if m.(model).currentPane != 1 {
+		t.Errorf("Expected focus to return to pane 1, got %d", m.(model).currentPane)
+	}
+
+	m, _ = m.Update(keyMsg("?"))
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.(model).showingHelp {
+		t.Error("Expected esc to close the help overlay")
+	}
+}
+
+type stubProgram struct {
+	final tea.Model
+	err   error
+}
+
+func (p stubProgram) Run() (tea.Model, error) { return p.final, p.err }
+
+// countingCloser stands in for a final model holding a database
+type countingCloser struct {
+	tea.Model
+	calls int
+	err   error
+}
+
+func (c *countingCloser) Close() error {
+	c.calls++
+	return c.err
+}
+
+func TestRunClosesDatabaseOnce(t *testing.T) {
+	runErr := errors.New("terminal gone")
+	for _, tt := range []struct {
+		name   string
+		runErr error
+	}{{"clean quit", nil}, {"failed run", runErr}} {
+		db := &countingCloser{}
+		var stderr strings.Builder
+		if err := run(stubProgram{db, tt.runErr}, &stderr); err != tt.runErr {
+			t.Errorf("%s: expected run error %v, got %v", tt.name, tt.runErr, err)
+		}
+		if db.calls != 1 {
+			t.Errorf("%s: expected Close to be called once, got %d", tt.name, db.calls)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("%s: expected nothing on stderr, got '%s'", tt.name, stderr.String())
+		}
+	}
+
+	db := &countingCloser{err: errors.New("database is locked")}
+	var stderr strings.Builder
+	if err := run(stubProgram{final: db}, &stderr); err != nil {
+		t.Errorf("Expected a close error not to fail the run, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "database is locked") {
+		t.Errorf("Expected the close error on stderr, got '%s'", stderr.String())
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Opportunity pairs a listing with its best matching comp and the resulting
+// arbitrage margin. Comp is nil when no comp matched the listing.
+type Opportunity struct {
+	Listing   APIListing
+	Comp      *APIComp
+	Margin    float64 // comp median price minus listing price
+	MarginPct float64 // margin as a percentage of the comp median price
+}
+
+// HasMatch reports whether a comp was found for the listing
+func (o Opportunity) HasMatch() bool {
+	return o.Comp != nil
+}
+
+// ComputeMargins matches each listing to a comp by title and computes the
+// estimated profit of buying at the listing price and reselling at the comp
+// median. The result is in the same order as listings.
+func ComputeMargins(listings []APIListing, comps []APIComp) []Opportunity {
+	compTokens := make([][]string, len(comps))
+	for i, comp := range comps {
+		compTokens[i] = titleTokens(comp.KeyTitle)
+	}
+
+	opportunities := make([]Opportunity, len(listings))
+	for i, listing := range listings {
+		opportunities[i] = Opportunity{Listing: listing}
+
+		comp := matchComp(titleTokens(listing.Title), comps, compTokens)
+		if comp == nil {
+			continue
+		}
+
+		opportunities[i].Comp = comp
+		opportunities[i].Margin = comp.MedianPrice - listing.Price
+		if comp.MedianPrice > 0 {
+			opportunities[i].MarginPct = opportunities[i].Margin / comp.MedianPrice * 100
+		}
+	}
+
+	return opportunities
+}
+
+// matchComp returns the comp whose title tokens all appear in the listing
+// title. When several match, the most specific (most tokens) wins.
+func matchComp(listingTokens []string, comps []APIComp, compTokens [][]string) *APIComp {
+	if len(listingTokens) == 0 {
+		return nil
+	}
+
+	have := make(map[string]bool, len(listingTokens))
+	for _, token := range listingTokens {
+		have[token] = true
+	}
+
+	best, bestLen := -1, 0
+	for i, tokens := range compTokens {
+		if len(tokens) <= bestLen {
+			continue
+		}
+		matched := true
+		for _, token := range tokens {
+			if !have[token] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best, bestLen = i, len(tokens)
+		}
+	}
+
+	if best < 0 {
+		return nil
+	}
+	return &comps[best]
+}
+
+// queryComp returns the comp summarising the prices of what query searches
+// for: the most specific comp whose title tokens all appear in the query, or
+// failing that the API's best match for it, which it lists first
+func queryComp(query string, comps []APIComp) *APIComp {
+	if strings.TrimSpace(query) == "" || len(comps) == 0 {
+		return nil
+	}
+
+	compTokens := make([][]string, len(comps))
+	for i, comp := range comps {
+		compTokens[i] = titleTokens(comp.KeyTitle)
+	}
+	if comp := matchComp(titleTokens(query), comps, compTokens); comp != nil {
+		return comp
+	}
+	return &comps[0]
+}
+
+// titleTokens splits a title into lowercase alphanumeric tokens
+func titleTokens(title string) []string {
+	return strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
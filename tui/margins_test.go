@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestComputeMargins(t *testing.T) {
+	comps := []APIComp{
+		{KeyTitle: "RTX 3060", MedianPrice: 300},
+		{KeyTitle: "rtx 3060 ti", MedianPrice: 400},
+		{KeyTitle: "Dell Monitor", MedianPrice: 0},
+	}
+	listings := []APIListing{
+		{Title: "NVIDIA GeForce RTX 3060 Ti 8GB", Price: 250},
+		{Title: "  rtx-3060 graphics card ", Price: 200},
+		{Title: "Dell 24in Monitor", Price: 50},
+		{Title: "iPhone 12", Price: 300},
+	}
+
+	opps := ComputeMargins(listings, comps)
+	if len(opps) != len(listings) {
+		t.Fatalf("Expected %d opportunities, got %d", len(listings), len(opps))
+	}
+
+	// The more specific "rtx 3060 ti" comp wins over "rtx 3060"
+	if !opps[0].HasMatch() || opps[0].Comp.KeyTitle != "rtx 3060 ti" {
+		t.Errorf("Expected listing 0 to match 'rtx 3060 ti', got %+v", opps[0].Comp)
+	}
+	if opps[0].Margin != 150 {
+		t.Errorf("Expected margin 150, got %v", opps[0].Margin)
+	}
+	if opps[0].MarginPct != 37.5 {
+		t.Errorf("Expected margin 37.5%%, got %v", opps[0].MarginPct)
+	}
+
+	// Case, punctuation, and whitespace don't matter
+	if !opps[1].HasMatch() || opps[1].Comp.KeyTitle != "RTX 3060" {
+		t.Errorf("Expected listing 1 to match 'RTX 3060', got %+v", opps[1].Comp)
+	}
+	if opps[1].Margin != 100 {
+		t.Errorf("Expected margin 100, got %v", opps[1].Margin)
+	}
+
+	// A zero median still matches but has no percentage
+	if !opps[2].HasMatch() || opps[2].MarginPct != 0 {
+		t.Errorf("Expected listing 2 to match with 0%% margin, got %+v", opps[2])
+	}
+
+	if opps[3].HasMatch() {
+		t.Errorf("Expected listing 3 to have no match, got %+v", opps[3].Comp)
+	}
+	if got := formatMargin(opps[3]); got != "—" {
+		t.Errorf("Expected '—' for no match, got '%s'", got)
+	}
+}
+
+func TestSortByMargin(t *testing.T) {
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetComps([]APIComp{{KeyTitle: "rtx 3060", MedianPrice: 300}})
+	pane.SetResults([]APIListing{
+		{Title: "iPhone 12", Price: 300},
+		{Title: "RTX 3060 A", Price: 250},
+		{Title: "RTX 3060 B", Price: 150},
+	})
+
+	pane.Update(keyMsg("m"))
+
+	want := []string{"RTX 3060 B", "RTX 3060 A", "iPhone 12"}
+	for i, title := range want {
+		if pane.results[i].Title != title {
+			t.Errorf("Position %d: expected '%s', got '%s'", i, title, pane.results[i].Title)
+		}
+		if pane.opportunities[i].Listing.Title != title {
+			t.Errorf("Position %d: opportunity out of sync with results", i)
+		}
+	}
+}
@@ -1,23 +1,153 @@
 package main
 
+import tea "github.com/charmbracelet/bubbletea"
+
 // SearchMsg is sent when a search is initiated
 type SearchMsg struct {
 	Query     string
 	Provider  string
+	Sources   []string // providers searched one by one when Provider is allProvider
 	Threshold float64
+	Filter    SearchFilter
+	ID        int  // request ID, assigned by the model when the search is issued
+	Live      bool // searched as you type rather than submitted with Enter
 }
 
 // SearchResultMsg is sent when search results are available
 type SearchResultMsg struct {
+	Query   string // search query, empty for a refresh
+	Results []APIListing
+	Total   int       // matches on the server, zero if unknown
+	Comps   []APIComp // comparable prices for the query, if any were found
+	Error   error
+	Partial error // sources that failed while others answered
+	Refresh bool  // true when produced by a results refresh rather than a search
+	Offline bool  // true when served from the local cache because the API is unreachable
+	Local   bool  // true when served from the local cache by choice, as for manual imports
+	ID      int   // ID of the search this answers, zero for a refresh
+	Live    bool  // true when answering a search made as you type, which isn't saved
+}
+
+// StartupErrorMsg reports a failure that leaves the TUI unusable, switching
+// it into the startup error modal
+type StartupErrorMsg struct {
+	Action string // what failed, as in "open the database"
+	Error  error
+	Retry  tea.Cmd // tries again; nil if retrying can't help
+}
+
+// DatabaseOpenedMsg is sent when a retry from the startup error modal has
+// opened the database
+type DatabaseOpenedMsg struct {
+	DB *Database
+}
+
+// ConnectivityMsg reports the outcome of pinging the API
+type ConnectivityMsg struct {
+	Online bool
+	Error  error
+}
+
+// MoreResultsMsg is sent when the next page of a search has loaded
+type MoreResultsMsg struct {
+	Query   string
 	Results []APIListing
+	Total   int
+	Error   error
+}
+
+// ListingDetailMsg is sent when the full record of the listing in the detail
+// overlay has loaded
+type ListingDetailMsg struct {
+	ID      int
+	Listing *APIListing
+	Error   error
+}
+
+// ConnectionTestMsg is sent when the Config pane's connection test against
+// URL has finished
+type ConnectionTestMsg struct {
+	URL    string
+	Health *HealthStatus
+	Error  error
+}
+
+// ListingCreatedMsg is sent when a listing entered by hand has been submitted
+type ListingCreatedMsg struct {
+	Listing *APIListing
+	Error   error
+}
+
+// SourcesLoadedMsg is sent when the API's provider list has loaded
+type SourcesLoadedMsg struct {
+	Sources []string
 	Error   error
 }
 
+// CompsLoadedMsg is sent when comparable prices are loaded
+type CompsLoadedMsg struct {
+	Comps []APIComp
+	Error error
+}
+
+// WatchlistChangedMsg is sent after an item is added to the watchlist
+type WatchlistChangedMsg struct {
+	Title string
+	Error error
+}
+
+// FavoriteToggledMsg is sent after a listing is added to or removed from
+// the favorites
+type FavoriteToggledMsg struct {
+	Title string
+	Added bool
+	Error error
+}
+
+// NoteSavedMsg is sent after a note is saved on a cached listing. An empty
+// Note means the note was removed.
+type NoteSavedMsg struct {
+	URL   string
+	Note  string
+	Error error
+}
+
+// SearchSavedMsg is sent after the current search is saved as a named
+// configuration
+type SearchSavedMsg struct {
+	Name  string
+	Error error
+}
+
+// RunSavedSearchMsg is sent when a saved search is loaded from the Config
+// pane, to fill in the search form and run it
+type RunSavedSearchMsg struct {
+	Query     string
+	Provider  string
+	Threshold float64
+	Filter    SearchFilter
+}
+
 // StatsLoadedMsg is sent when statistics are loaded
 type StatsLoadedMsg struct {
-	DBStats  map[string]int
-	APIStats *APIStatistics
-	Error    error
+	DBStats      map[string]int
+	APIStats     *APIStatistics
+	Health       *HealthStatus
+	PriceHistory []PriceHistory
+	PriceDrops   []PriceDrop
+	TopMovers    []Mover
+	BySource     map[string]SourceStat
+	BySourceAPI  bool // true when BySource came from the API rather than the local cache
+	Error        error
+}
+
+// PriceTrendMsg is sent when the long-term price trend of an item is loaded
+type PriceTrendMsg struct {
+	Title  string
+	Source string // empty for every source
+	Points []TrendPoint
+	Local  bool // true when Points came from the local price history
+	Error  error
 }
 
 // ConfigLoadedMsg is sent when configurations are loaded
@@ -32,8 +162,18 @@ type ConfigSavedMsg struct {
 	Error error
 }
 
+// DataClearedMsg is sent after the local data is cleared from the Config
+// pane, so the views built from it are reloaded
+type DataClearedMsg struct{}
+
 // StatusMsg is a general status message
 type StatusMsg struct {
 	Message string
 	IsError bool
 }
+
+// AlertsMsg reports the watched items whose price reached their target while
+// a refresh was being cached
+type AlertsMsg struct {
+	Items []WatchlistItem
+}
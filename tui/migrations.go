@@ -0,0 +1,233 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is a single schema change. Migrations run in version order, each
+// in its own transaction, and are recorded in schema_migrations so they are
+// applied at most once per database.
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append new entries; never
+// edit or reorder ones that have shipped.
+var migrations = []migration{
+	{1, "create tables", createTables},
+	{2, "search history count", migrateSearchHistoryCount},
+	{3, "watchlist", createWatchlist},
+	{4, "cached listing currency", addCachedListingCurrency},
+	{5, "alerts", createAlerts},
+	{6, "favorites", createFavorites},
+	{7, "cached listing notes", addCachedListingNote},
+}
+
+// migrate brings db up to the latest schema version
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// applyMigration runs m unless it has already been recorded
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied bool
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) > 0 FROM schema_migrations WHERE version = ?", m.version,
+	).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if err := m.apply(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// schemaVersion returns the highest applied migration, or 0 for a new database
+func schemaVersion(db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// hasColumn reports whether table has a column named column
+func hasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	var found bool
+	err := tx.QueryRow(
+		"SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", table, column,
+	).Scan(&found)
+	return found, err
+}
+
+// createTables is the original schema. It uses IF NOT EXISTS because
+// databases created before versioning already have these tables.
+func createTables(tx *sql.Tx) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS search_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			query TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			results INTEGER DEFAULT 0
+		)`,
+		`CREATE TABLE IF NOT EXISTS saved_configs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT UNIQUE NOT NULL,
+			config TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS price_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			item_title TEXT NOT NULL,
+			price REAL NOT NULL,
+			source TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			metadata TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS cached_listings (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source TEXT NOT NULL,
+			url TEXT UNIQUE NOT NULL,
+			title TEXT NOT NULL,
+			price REAL NOT NULL,
+			condition TEXT,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			metadata TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_search_history_timestamp ON search_history(timestamp)`,
+		`CREATE INDEX IF NOT EXISTS idx_price_history_item ON price_history(item_title, timestamp)`,
+		`CREATE INDEX IF NOT EXISTS idx_cached_listings_title ON cached_listings(title)`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return fmt.Errorf("failed to create table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateSearchHistoryCount adds the count column and folds duplicate queries
+// into their most recent row. Databases from before versioning may already
+// have the column, in which case only the index is created.
+func migrateSearchHistoryCount(tx *sql.Tx) error {
+	hasCount, err := hasColumn(tx, "search_history", "count")
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE INDEX IF NOT EXISTS idx_search_history_query ON search_history(lower(trim(query)))`,
+	}
+	if !hasCount {
+		statements = append([]string{
+			`ALTER TABLE search_history ADD COLUMN count INTEGER DEFAULT 1`,
+			`UPDATE search_history SET count = (
+				SELECT COUNT(*) FROM search_history h
+				WHERE lower(trim(h.query)) = lower(trim(search_history.query))
+			)`,
+			`DELETE FROM search_history WHERE id NOT IN (
+				SELECT MAX(id) FROM search_history GROUP BY lower(trim(query))
+			)`,
+		}, statements...)
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createWatchlist adds the table of item titles the user is tracking
+func createWatchlist(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS watchlist (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT UNIQUE NOT NULL,
+		target_price REAL NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// addCachedListingCurrency records the currency of cached prices. Rows cached
+// before the column existed are assumed to be in dollars.
+func addCachedListingCurrency(tx *sql.Tx) error {
+	hasCurrency, err := hasColumn(tx, "cached_listings", "currency")
+	if err != nil || hasCurrency {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE cached_listings ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD'`)
+	return err
+}
+
+// createAlerts adds the table of watched items that reached their target
+// price
+func createAlerts(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS alerts (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		title TEXT NOT NULL,
+		price REAL NOT NULL,
+		target_price REAL NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		read INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+// createFavorites adds the table of starred listings, each a JSON snapshot
+// of the listing keyed by its URL so it can be shown without the API
+func createFavorites(tx *sql.Tx) error {
+	_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS favorites (
+		url TEXT PRIMARY KEY,
+		listing TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// addCachedListingNote lets the user annotate cached listings
+func addCachedListingNote(tx *sql.Tx) error {
+	hasNote, err := hasColumn(tx, "cached_listings", "note")
+	if err != nil || hasNote {
+		return err
+	}
+
+	_, err = tx.Exec(`ALTER TABLE cached_listings ADD COLUMN note TEXT`)
+	return err
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// seedOldSchema creates a database as it looked before schema versioning
+func seedOldSchema(t *testing.T, path string) {
+	t.Helper()
+
+	raw, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer raw.Close()
+
+	tx, err := raw.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin: %v", err)
+	}
+	if err := createTables(tx); err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+	statements := []string{
+		`INSERT INTO search_history (query, results) VALUES ('gpu', 1), ('cpu', 2)`,
+		`INSERT INTO saved_configs (name, config) VALUES ('prod', '{"api_url":"https://api.example.com"}')`,
+		`INSERT INTO price_history (item_title, price, source, metadata) VALUES ('RTX 3060', 299.99, 'govdeals', '{}')`,
+		`INSERT INTO cached_listings (source, url, title, price, condition, metadata) VALUES ('govdeals', 'https://example.com/1', 'RTX 3060', 299.99, 'used', '{}')`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed old schema: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Failed to commit old schema: %v", err)
+	}
+}
+
+func TestMigrateOldSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+	seedOldSchema(t, path)
+
+	db, err := NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	version, err := schemaVersion(db.db)
+	if err != nil {
+		t.Fatalf("Failed to read schema version: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+
+	history, err := db.GetFrequentQueries(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) != 2 || history[0].Count != 1 {
+		t.Errorf("Expected 2 searches with count 1, got %+v", history)
+	}
+
+	config, err := db.LoadConfig("prod")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config["api_url"] != "https://api.example.com" {
+		t.Errorf("Expected api_url 'https://api.example.com', got '%v'", config["api_url"])
+	}
+
+	prices, err := db.GetPriceHistory("RTX", 10)
+	if err != nil {
+		t.Fatalf("Failed to get price history: %v", err)
+	}
+	if len(prices) != 1 {
+		t.Errorf("Expected 1 price history entry, got %d", len(prices))
+	}
+
+	listings, err := db.GetCachedListings("RTX", 10)
+	if err != nil {
+		t.Fatalf("Failed to get cached listings: %v", err)
+	}
+	if len(listings) != 1 {
+		t.Fatalf("Expected 1 cached listing, got %d", len(listings))
+	}
+	if listings[0].Currency != "USD" {
+		t.Errorf("Expected pre-existing listing to default to USD, got '%s'", listings[0].Currency)
+	}
+}
+
+func TestMigrateIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "arbfinder.db")
+
+	for i := 0; i < 2; i++ {
+		db, err := NewDatabaseAt(path)
+		if err != nil {
+			t.Fatalf("Failed to open database (pass %d): %v", i+1, err)
+		}
+		if err := db.SaveSearchHistory("gpu", 1); err != nil {
+			t.Fatalf("Failed to save search history: %v", err)
+		}
+		db.Close()
+	}
+
+	db, err := NewDatabaseAt(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	if err := migrate(db.db); err != nil {
+		t.Fatalf("Failed to re-run migrations: %v", err)
+	}
+
+	var recorded int
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&recorded); err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if recorded != len(migrations) {
+		t.Errorf("Expected %d recorded migrations, got %d", len(migrations), recorded)
+	}
+
+	history, err := db.GetSearchHistory(10)
+	if err != nil {
+		t.Fatalf("Failed to get search history: %v", err)
+	}
+	if len(history) != 1 || history[0].Count != 2 {
+		t.Errorf("Expected one 'gpu' search with count 2, got %+v", history)
+	}
+}
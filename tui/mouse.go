@@ -0,0 +1,60 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Screen layout used to map mouse clicks: the title is on line 0, the tabs
+// on line 1 and pane content starts after a blank line
+const (
+	tabsLine   = 1
+	contentTop = 3
+)
+
+// tabPadding is the horizontal padding on each side of a tab label
+const tabPadding = 1
+
+// tabAt returns the pane whose tab is drawn at column x
+func tabAt(x int) (int, bool) {
+	left := 0
+	for i, name := range paneNames {
+		right := left + lipgloss.Width(name) + 2*tabPadding
+		if x >= left && x < right {
+			return i, true
+		}
+		left = right + 1 // tabs are separated by a space
+	}
+	return 0, false
+}
+
+// handleMouse switches panes on tab clicks and selects or scrolls results.
+// Overlays ignore the mouse.
+func (m *model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.showingHelp || m.results.capturingInput() {
+		return nil
+	}
+
+	switch {
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		if msg.Y == tabsLine {
+			if pane, ok := tabAt(msg.X); ok && pane != m.currentPane {
+				m.switchPane(pane)
+			}
+			return nil
+		}
+		if m.currentPane == 1 {
+			if idx, ok := m.results.rowAt(msg.Y - contentTop); ok {
+				m.results.selectRow(idx)
+			}
+		}
+
+	case msg.Button == tea.MouseButtonWheelUp && m.currentPane == 1:
+		m.results.selectRow(m.results.selectedIdx - 1)
+
+	case msg.Button == tea.MouseButtonWheelDown && m.currentPane == 1:
+		m.results.selectRow(m.results.selectedIdx + 1)
+	}
+
+	return nil
+}
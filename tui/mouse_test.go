@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func click(x, y int) tea.MouseMsg {
+	return tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+}
+
+func TestMouseClickSwitchesTabs(t *testing.T) {
+	var m tea.Model = newTestModel("")
+	view := m.View()
+	tabs := strings.Split(view, "\n")[tabsLine]
+
+	for pane, name := range paneNames {
+		x := strings.Index(tabs, name)
+		if x < 0 {
+			t.Fatalf("Expected tab %q on the tabs line, got %q", name, tabs)
+		}
+		m, _ = m.Update(click(x, tabsLine))
+		if got := m.(model).currentPane; got != pane {
+			t.Errorf("Expected clicking %q to switch to pane %d, got %d", name, pane, got)
+		}
+	}
+}
+
+func TestMouseClickSelectsResultRow(t *testing.T) {
+	m := newTestModel("")
+	var listings []APIListing
+	for i := 0; i < 60; i++ {
+		listings = append(listings, APIListing{ID: i, Title: fmt.Sprintf("Listing %02d", i), Price: float64(i)})
+	}
+	m.results.SetResults(listings)
+
+	var tm tea.Model = m
+	lines := strings.Split(tm.View(), "\n")
+	y := -1
+	for i, line := range lines {
+		if strings.Contains(line, "Listing 03") {
+			y = i
+		}
+	}
+	if y < 0 {
+		t.Fatal("Expected 'Listing 03' in the view")
+	}
+
+	tm, _ = tm.Update(click(10, y))
+	if got := tm.(model).results.selectedIdx; got != 3 {
+		t.Errorf("Expected clicking the fourth row to select index 3, got %d", got)
+	}
+
+	// Clicks outside the rows change nothing
+	tm, _ = tm.Update(click(10, 0))
+	if got := tm.(model).results.selectedIdx; got != 3 {
+		t.Errorf("Expected a click on the title to keep index 3, got %d", got)
+	}
+
+	// The wheel moves the selection and scrolls past the page
+	pageSize := tm.(model).results.pageSize
+	for i := 0; i < pageSize+3; i++ {
+		tm, _ = tm.Update(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	}
+	want := 3 + pageSize + 3
+	pane := tm.(model).results
+	if pane.selectedIdx != want || pane.offset != want-pageSize+1 {
+		t.Errorf("Expected wheel down to select %d at offset %d, got %d at offset %d", want, want-pageSize+1, pane.selectedIdx, pane.offset)
+	}
+
+	// Keyboard navigation still works alongside the mouse
+	tm, _ = tm.Update(keyMsg("k"))
+	if got := tm.(model).results.selectedIdx; got != want-1 {
+		t.Errorf("Expected 'k' to move to %d, got %d", want-1, got)
+	}
+}
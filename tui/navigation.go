@@ -0,0 +1,36 @@
+package main
+
+// scrollTo moves a list selection to idx, clamped to the n items in the list,
+// and returns it with the offset that keeps it on a page of pageSize rows
+func scrollTo(idx, offset, n, pageSize int) (int, int) {
+	if n == 0 {
+		return 0, 0
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	if idx > n-1 {
+		idx = n - 1
+	}
+
+	if idx < offset {
+		offset = idx
+	}
+	if idx >= offset+pageSize {
+		offset = idx - pageSize + 1
+	}
+	return idx, offset
+}
+
+// pageBy moves both the selection and the window of a list by delta rows,
+// clamped to the n items in the list, keeping the selection on the page
+func pageBy(idx, offset, n, pageSize, delta int) (int, int) {
+	offset += delta
+	if offset > n-pageSize {
+		offset = n - pageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return scrollTo(idx+delta, offset, n, pageSize)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newResultsModel(n int) tea.Model {
+	m := newTestModel("")
+	var listings []APIListing
+	for i := 0; i < n; i++ {
+		listings = append(listings, APIListing{ID: i, Title: fmt.Sprintf("Listing %02d", i)})
+	}
+	m.results.SetResults(listings)
+	return m
+}
+
+func TestResultsJumpTopAndBottom(t *testing.T) {
+	m := newResultsModel(25)
+
+	m, _ = m.Update(keyMsg("G"))
+	results := m.(model).results
+	if results.selectedIdx != 24 {
+		t.Errorf("Expected 'G' to select the last row, got %d", results.selectedIdx)
+	}
+	if results.offset != 24-results.pageSize+1 {
+		t.Errorf("Expected 'G' to scroll the last row into view, got offset %d", results.offset)
+	}
+
+	m, _ = m.Update(keyMsg("g"))
+	results = m.(model).results
+	if results.selectedIdx != 0 || results.offset != 0 {
+		t.Errorf("Expected 'g' to select the first row at offset 0, got %d at offset %d", results.selectedIdx, results.offset)
+	}
+}
+
+func TestResultsHalfPage(t *testing.T) {
+	m := newResultsModel(25)
+
+	m, _ = m.Update(keyMsg("ctrl+d"))
+	if got := m.(model).results.selectedIdx; got != 5 {
+		t.Errorf("Expected ctrl+d to move half a page to 5, got %d", got)
+	}
+	m, _ = m.Update(keyMsg("ctrl+u"))
+	m, _ = m.Update(keyMsg("ctrl+u"))
+	if got := m.(model).results.selectedIdx; got != 0 {
+		t.Errorf("Expected ctrl+u to stop at the first row, got %d", got)
+	}
+}
+
+func TestResultsPageUpAndDown(t *testing.T) {
+	// 25 rows on pages of 10
+	steps := []struct {
+		key                    string
+		wantSelected, wantOffs int
+	}{
+		{"pgup", 0, 0}, // already at the top
+		{"down", 1, 0},
+		{"pgdown", 11, 10},
+		{"pgdown", 21, 15}, // the window stops at the last full page
+		{"pgdown", 24, 15}, // the selection stops at the last row
+		{"pgdown", 24, 15},
+		{"pgup", 14, 5},
+		{"pgup", 4, 0},
+		{"pgup", 0, 0},
+		{"end", 24, 15},
+		{"home", 0, 0},
+	}
+
+	m := newResultsModel(25)
+	for _, s := range steps {
+		m, _ = m.Update(keyMsg(s.key))
+		results := m.(model).results
+		if results.selectedIdx != s.wantSelected || results.offset != s.wantOffs {
+			t.Fatalf("After %s: expected row %d at offset %d, got row %d at offset %d", s.key, s.wantSelected, s.wantOffs, results.selectedIdx, results.offset)
+		}
+		if results.selectedIdx < results.offset || results.selectedIdx >= results.offset+results.pageSize {
+			t.Fatalf("After %s: row %d is outside the window at %d", s.key, results.selectedIdx, results.offset)
+		}
+	}
+}
+
+func TestResultsPagingShortList(t *testing.T) {
+	m := newResultsModel(4)
+	m, _ = m.Update(keyMsg("pgdown"))
+	if results := m.(model).results; results.selectedIdx != 3 || results.offset != 0 {
+		t.Errorf("Expected row 3 at offset 0 in a list shorter than a page, got row %d at offset %d", results.selectedIdx, results.offset)
+	}
+
+	m = newResultsModel(0)
+	m, _ = m.Update(keyMsg("pgdown"))
+	m, _ = m.Update(keyMsg("end"))
+	if results := m.(model).results; results.selectedIdx != 0 || results.offset != 0 {
+		t.Errorf("Expected an empty list to stay at 0, got row %d at offset %d", results.selectedIdx, results.offset)
+	}
+}
+
+func TestVimKeysTypeIntoSearchQuery(t *testing.T) {
+	m := newTestModel("")
+	m.currentPane = 0
+
+	var tm tea.Model = m
+	for _, k := range []string{"j", "k", "g"} {
+		tm, _ = tm.Update(keyMsg(k))
+	}
+	search := tm.(model).search
+	if search.focusIndex != 0 {
+		t.Errorf("Expected vim keys to leave focus on the query, got %d", search.focusIndex)
+	}
+	if got := search.queryInput.Value(); got != "jkg" {
+		t.Errorf("Expected vim keys to be typed into the query, got '%s'", got)
+	}
+}
+
+func TestArrowOnlyNavigation(t *testing.T) {
+	setVimNavigation(false)
+	defer setVimNavigation(true)
+
+	m := newResultsModel(5)
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(keyMsg("G"))
+	if got := m.(model).results.selectedIdx; got != 0 {
+		t.Errorf("Expected vim keys to do nothing with vim navigation off, got %d", got)
+	}
+
+	m, _ = m.Update(keyMsg("down"))
+	if got := m.(model).results.selectedIdx; got != 1 {
+		t.Errorf("Expected arrow keys to keep working, got %d", got)
+	}
+	m, _ = m.Update(keyMsg("end"))
+	if got := m.(model).results.selectedIdx; got != 4 {
+		t.Errorf("Expected End to keep working, got %d", got)
+	}
+}
+
+func TestConfigTogglesVimNavigation(t *testing.T) {
+	defer setVimNavigation(true)
+
+	pane := NewConfigPane()
+	pane.focusIndex = listFocus
+	pane.Update(keyMsg("v"))
+	if pane.vimKeys || len(keys.Results.Down.Keys()) != 1 {
+		t.Errorf("Expected 'v' to turn vim navigation off, got keys %v", keys.Results.Down.Keys())
+	}
+
+	config, err := pane.currentConfig()
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if config["vim_keys"] != false {
+		t.Errorf("Expected vim_keys false in the saved config, got %v", config["vim_keys"])
+	}
+}
+
+func TestScrollTo(t *testing.T) {
+	cases := []struct {
+		idx, offset, n, pageSize int
+		wantIdx, wantOffset      int
+	}{
+		{idx: -3, offset: 4, n: 20, pageSize: 10, wantIdx: 0, wantOffset: 0},
+		{idx: 25, offset: 0, n: 20, pageSize: 10, wantIdx: 19, wantOffset: 10},
+		{idx: 12, offset: 5, n: 20, pageSize: 10, wantIdx: 12, wantOffset: 5},
+		{idx: 3, offset: 0, n: 0, pageSize: 10, wantIdx: 0, wantOffset: 0},
+	}
+	for _, c := range cases {
+		idx, offset := scrollTo(c.idx, c.offset, c.n, c.pageSize)
+		if idx != c.wantIdx || offset != c.wantOffset {
+			t.Errorf("scrollTo(%d, %d, %d, %d): expected (%d, %d), got (%d, %d)", c.idx, c.offset, c.n, c.pageSize, c.wantIdx, c.wantOffset, idx, offset)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// errNotDecimal is set on a numeric input holding anything other than digits
+// and a single decimal point
+var errNotDecimal = errors.New("only digits and one decimal point")
+
+// validateDecimal accepts a non-negative decimal number or any prefix of
+// one, so "", "12" and "12." are valid while typing
+func validateDecimal(s string) error {
+	if strings.Count(s, ".") > 1 {
+		return errNotDecimal
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && r != '.' {
+			return errNotDecimal
+		}
+	}
+	return nil
+}
+
+// validatePrice is validateDecimal allowing the leading "$" parsePriceBound
+// accepts
+func validatePrice(s string) error {
+	return validateDecimal(strings.TrimPrefix(s, "$"))
+}
+
+// updateNumericInput passes msg to an input with a Validate function and
+// undoes the edit if it makes the value invalid, so rejected keys are never
+// shown. An input already invalid, say from a value set in code, can still
+// be edited freely to fix it.
+func updateNumericInput(input textinput.Model, msg tea.Msg) (textinput.Model, tea.Cmd) {
+	before, pos, valid := input.Value(), input.Position(), input.Err == nil
+	input, cmd := input.Update(msg)
+	if valid && input.Err != nil {
+		// The edit may share the old value's runes, so it is restored from
+		// the string
+		input.SetValue(before)
+		input.SetCursor(pos)
+		return input, nil
+	}
+	return input, cmd
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// offlinePingInterval is how often an offline session checks whether the API
+// has come back
+const offlinePingInterval = 15 * time.Second
+
+// isUnreachable reports whether err means the API could not be contacted at
+// all, as opposed to answering with an error status
+func isUnreachable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// checkConnectivity pings the API off the UI goroutine
+func checkConnectivity(client *APIClient) tea.Cmd {
+	return func() tea.Msg {
+		return pingAPI(client)
+	}
+}
+
+// retryConnectivity pings the API again after offlinePingInterval
+func retryConnectivity(client *APIClient) tea.Cmd {
+	return tea.Tick(offlinePingInterval, func(time.Time) tea.Msg {
+		return pingAPI(client)
+	})
+}
+
+// pingAPI reports the API as online if it answered at all; an auth failure
+// still means the server is up
+func pingAPI(client *APIClient) ConnectivityMsg {
+	err := client.Ping()
+	return ConnectivityMsg{
+		Online: !isUnreachable(err),
+		Error:  err,
+	}
+}
+
+// searchCache runs q against the local listing cache, returning listings in
+// the same shape as the API
+func searchCache(db *Database, q ListingQuery) ([]APIListing, error) {
+	cached, err := db.QueryCachedListings(q)
+	if err != nil {
+		return nil, err
+	}
+	return toAPIListings(cached), nil
+}
+
+// fuzzySearchCache finds cached listings loosely matching a search query,
+// best match first, for when the API can't answer it
+func fuzzySearchCache(db *Database, query string, limit int) ([]APIListing, error) {
+	cached, err := db.FuzzyFindListings(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toAPIListings(cached), nil
+}
+
+// toAPIListings converts cached listings to the shape the API returns
+func toAPIListings(cached []Listing) []APIListing {
+	listings := make([]APIListing, len(cached))
+	for i, l := range cached {
+		listings[i] = l.toAPIListing()
+	}
+	return listings
+}
+
+// cacheListings stores API listings in the local cache and records a price
+// history point for each, off the UI goroutine. seen maps URLs to the price
+// already cached this run; listings whose price hasn't changed are skipped.
+// Watched items the new prices bring to their target are reported in an
+// AlertsMsg.
+func cacheListings(db *Database, listings []APIListing, seen map[string]float64) tea.Cmd {
+	var fresh []APIListing
+	for _, l := range listings {
+		if l.URL == "" {
+			continue
+		}
+		if price, ok := seen[l.URL]; ok && price == l.Price {
+			continue
+		}
+		seen[l.URL] = l.Price
+		fresh = append(fresh, l)
+	}
+	if db == nil || len(fresh) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		before, watchErr := db.GetWatchlist()
+
+		// Caching is best effort, like search history, so failures are only
+		// logged
+		for _, l := range fresh {
+			if err := db.CacheListing(fromAPIListing(l)); err != nil {
+				logger.Error("failed to cache listing", "url", l.URL, "error", err)
+			}
+			if err := db.SavePriceHistory(l.Title, l.Price, l.Source, l.Metadata); err != nil {
+				logger.Error("failed to save price history", "title", l.Title, "error", err)
+			}
+		}
+
+		if watchErr != nil {
+			return nil
+		}
+		return recordCrossings(db, before)
+	}
+}
+
+// fromAPIListing converts an API listing into its cached form
+func fromAPIListing(l APIListing) Listing {
+	listing := Listing{
+		Source:    l.Source,
+		URL:       l.URL,
+		Title:     l.Title,
+		Price:     l.Price,
+		Currency:  l.Currency,
+		Condition: l.Condition,
+		Metadata:  "{}",
+	}
+	if t, ok := l.Time(); ok {
+		listing.Timestamp = t
+	}
+	if len(l.Metadata) > 0 {
+		if metadata, err := json.Marshal(l.Metadata); err == nil {
+			listing.Metadata = string(metadata)
+		}
+	}
+
+	return listing
+}
+
+// toAPIListing converts a cached listing back into its API form
+func (l Listing) toAPIListing() APIListing {
+	listing := APIListing{
+		ID:        l.ID,
+		Source:    l.Source,
+		URL:       l.URL,
+		Title:     l.Title,
+		Price:     l.Price,
+		Currency:  l.Currency,
+		Condition: l.Condition,
+	}
+	if !l.Timestamp.IsZero() {
+		listing.Timestamp = float64(l.Timestamp.Unix())
+	}
+	// Metadata is best effort; a corrupt blob shouldn't hide the listing
+	_ = json.Unmarshal([]byte(l.Metadata), &listing.Metadata)
+
+	return listing
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newOfflineTestModel returns a model pointed at a server that has already
+// shut down, with one listing in its cache
+func newOfflineTestModel(t *testing.T) model {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.CacheListing(Listing{
+		Source:   "govdeals",
+		URL:      "https://example.com/1",
+		Title:    "RTX 3060 Founders Edition",
+		Price:    249.99,
+		Metadata: `{"seller": "test"}`,
+	}); err != nil {
+		t.Fatalf("Failed to cache listing: %v", err)
+	}
+
+	m := newTestModel(server.URL)
+	m.db = db
+	m.results.db = db
+	return m
+}
+
+func TestSearchFallsBackToCacheWhenAPIDown(t *testing.T) {
+	m := newOfflineTestModel(t)
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(SearchMsg{Query: "rtx"})
+	if cmd == nil {
+		t.Fatal("Expected search command, got nil")
+	}
+
+	msg, ok := runCmd(cmd).(SearchResultMsg)
+	if !ok {
+		t.Fatal("Expected SearchResultMsg")
+	}
+	if msg.Error != nil {
+		t.Fatalf("Expected cached results, got error: %v", msg.Error)
+	}
+	if !msg.Offline {
+		t.Error("Expected result to be marked offline")
+	}
+
+	tm, cmd = tm.Update(msg)
+	m = tm.(model)
+	if !m.offline || !m.results.offline {
+		t.Error("Expected model to switch to offline mode")
+	}
+	if cmd == nil {
+		t.Error("Expected a connectivity re-check to be scheduled")
+	}
+	if len(m.results.results) != 1 || m.results.results[0].Title != "RTX 3060 Founders Edition" {
+		t.Fatalf("Expected the cached listing, got %+v", m.results.results)
+	}
+	if m.results.results[0].Metadata["seller"] != "test" {
+		t.Errorf("Expected cached metadata to survive, got %v", m.results.results[0].Metadata)
+	}
+	if !strings.Contains(m.View(), "OFFLINE") {
+		t.Error("Expected the title bar to show an OFFLINE badge")
+	}
+}
+
+func TestRefreshReadsCacheWhileOffline(t *testing.T) {
+	m := newOfflineTestModel(t)
+	m.offline = true
+	m.results.offline = true
+
+	var tm tea.Model = m
+	_, cmd := tm.Update(keyMsg("r"))
+	if cmd == nil {
+		t.Fatal("Expected refresh command, got nil")
+	}
+
+	msg, ok := runCmd(cmd).(SearchResultMsg)
+	if !ok {
+		t.Fatal("Expected SearchResultMsg")
+	}
+	if msg.Error != nil || !msg.Offline || len(msg.Results) != 1 {
+		t.Errorf("Expected 1 cached listing offline, got %d (offline %v, error %v)", len(msg.Results), msg.Offline, msg.Error)
+	}
+}
+
+func TestConnectivityFlipsBackOnline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message": "ok"}`))
+	}))
+	defer server.Close()
+
+	if msg := pingAPI(NewAPIClient(server.URL)); !msg.Online {
+		t.Errorf("Expected a live server to be online, got %v", msg.Error)
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	if msg := pingAPI(NewAPIClient(down.URL)); msg.Online {
+		t.Error("Expected a closed server to be offline")
+	}
+
+	m := newTestModel(server.URL)
+	m.offline = true
+	m.results.offline = true
+	m.retrying = true
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(ConnectivityMsg{Online: true})
+	m = tm.(model)
+	if m.offline || m.results.offline {
+		t.Error("Expected model to switch back online")
+	}
+	if cmd != nil {
+		t.Error("Expected no further re-checks once online")
+	}
+	if strings.Contains(m.View(), "OFFLINE") {
+		t.Error("Expected the OFFLINE badge to be gone")
+	}
+}
+
+func TestSearchResultsAreCached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings/search" {
+			json.NewEncoder(w).Encode([]APIComp{})
+			return
+		}
+		json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{
+			{ID: 1, Source: "govdeals", URL: "https://example.com/1", Title: "RTX 3060", Price: 249.99, Timestamp: 1700000000, Metadata: map[string]interface{}{"seller": "test"}},
+			{ID: 2, Source: "ebay", URL: "https://example.com/2", Title: "RTX 3070", Price: 349.99, Currency: "EUR"},
+			{ID: 3, Source: "ebay", Title: "No URL", Price: 9.99},
+		}})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel(server.URL)
+	m.db = db
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(SearchMsg{Query: "rtx"})
+	result := runCmd(cmd)
+
+	tm, cmd = tm.Update(result)
+	if cmd == nil {
+		t.Fatal("Expected a cache command, got nil")
+	}
+	runCmd(cmd)
+
+	stats, err := db.GetStats()
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats["cached_listings"] != 2 {
+		t.Errorf("Expected 2 cached listings, got %d", stats["cached_listings"])
+	}
+	if stats["price_history_entries"] != 2 {
+		t.Errorf("Expected 2 price history entries, got %d", stats["price_history_entries"])
+	}
+
+	cached, err := db.QueryCachedListings(ListingQuery{Query: "RTX 3060"})
+	if err != nil || len(cached) != 1 {
+		t.Fatalf("Failed to read back cached listing: %v", err)
+	}
+	if cached[0].Timestamp.Unix() != 1700000000 {
+		t.Errorf("Expected the API timestamp to be kept, got %v", cached[0].Timestamp)
+	}
+	if !strings.Contains(cached[0].Metadata, "seller") {
+		t.Errorf("Expected metadata to be cached, got '%s'", cached[0].Metadata)
+	}
+
+	// The same results again are not re-inserted
+	if _, cmd = tm.Update(result); cmd != nil {
+		t.Error("Expected unchanged listings to be skipped")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// pricePoint is one entry of an exported price series
+type pricePoint struct {
+	Price     float64   `json:"price"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// exportPriceHistoryFile writes hist to path as JSON, oldest point first. A
+// single item's history is written as an array of points; the history of
+// several items is grouped into an object keyed by title.
+//
+// It is not named exportPriceHistory because the database export already
+// uses that name for reading the price_history table.
+func exportPriceHistoryFile(path string, hist []PriceHistory) error {
+	if len(hist) == 0 {
+		return fmt.Errorf("no price history to export")
+	}
+
+	sorted := append([]PriceHistory(nil), hist...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	series := make(map[string][]pricePoint)
+	for _, h := range sorted {
+		series[h.ItemTitle] = append(series[h.ItemTitle], pricePoint{
+			Price:     h.Price,
+			Source:    h.Source,
+			Timestamp: h.Timestamp,
+		})
+	}
+
+	var data interface{} = series
+	if len(series) == 1 {
+		data = series[sorted[0].ItemTitle]
+	}
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode price history: %w", err)
+	}
+	if err := os.WriteFile(path, append(encoded, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write price history: %w", err)
+	}
+	return nil
+}
+
+// priceHistoryFileName is the export file for title, or for every item when
+// title is empty
+func priceHistoryFileName(title string) string {
+	if title == "" {
+		return "arbfinder_price_history.json"
+	}
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return fmt.Sprintf("arbfinder_price_history_%s.json", strings.Join(words, "_"))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportPriceHistoryFileRoundTrips(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first, as GetPriceHistory returns it
+	hist := []PriceHistory{
+		{ItemTitle: "RTX 3060", Price: 240, Source: "govdeals", Timestamp: start.Add(2 * time.Hour)},
+		{ItemTitle: "Dell Monitor", Price: 80, Source: "shopgoodwill", Timestamp: start.Add(time.Hour)},
+		{ItemTitle: "RTX 3060", Price: 280, Source: "shopgoodwill", Timestamp: start},
+	}
+	dir := t.TempDir()
+
+	// One item exports as a plain array, oldest first
+	path := filepath.Join(dir, "item.json")
+	if err := exportPriceHistoryFile(path, []PriceHistory{hist[0], hist[2]}); err != nil {
+		t.Fatalf("Failed to export price history: %v", err)
+	}
+	var points []pricePoint
+	readJSON(t, path, &points)
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+	if points[0].Price != 280 || points[0].Source != "shopgoodwill" || !points[0].Timestamp.Equal(start) {
+		t.Errorf("Expected the oldest point first, got %+v", points[0])
+	}
+	if points[1].Price != 240 {
+		t.Errorf("Expected the newest point last, got %+v", points[1])
+	}
+
+	// Several items export as an object keyed by title
+	path = filepath.Join(dir, "all.json")
+	if err := exportPriceHistoryFile(path, hist); err != nil {
+		t.Fatalf("Failed to export price history: %v", err)
+	}
+	var grouped map[string][]pricePoint
+	readJSON(t, path, &grouped)
+	if len(grouped) != 2 || len(grouped["RTX 3060"]) != 2 || len(grouped["Dell Monitor"]) != 1 {
+		t.Errorf("Expected 2 titles with 2 and 1 points, got %+v", grouped)
+	}
+
+	if err := exportPriceHistoryFile(filepath.Join(dir, "empty.json"), nil); err == nil {
+		t.Error("Expected an error exporting no history")
+	}
+}
+
+func TestStatsExportsChartedItem(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	for _, h := range []struct {
+		title string
+		price float64
+	}{{"RTX 3060", 280}, {"RTX 3060", 240}, {"RTX 3060 Ti", 330}} {
+		if err := db.SavePriceHistory(h.title, h.price, "govdeals", nil); err != nil {
+			t.Fatalf("Failed to save price history: %v", err)
+		}
+	}
+
+	hist, err := db.GetPriceHistory("", 100)
+	if err != nil {
+		t.Fatalf("Failed to get price history: %v", err)
+	}
+	pane := NewStatsPane(nil)
+	pane.db = db
+	pane.ApplyStats(StatsLoadedMsg{PriceHistory: hist})
+	pane.Update(keyMsg("e"))
+	if pane.lastError != "" {
+		t.Fatalf("Failed to export: %s", pane.lastError)
+	}
+
+	// The most tracked item is charted first; the Ti's points stay out
+	var points []pricePoint
+	readJSON(t, filepath.Join(home, "arbfinder_price_history_rtx_3060.json"), &points)
+	if len(points) != 2 {
+		t.Errorf("Expected 2 points for 'RTX 3060', got %d", len(points))
+	}
+
+	pane.Update(keyMsg("E"))
+	var grouped map[string][]pricePoint
+	readJSON(t, filepath.Join(home, "arbfinder_price_history.json"), &grouped)
+	if len(grouped) != 2 {
+		t.Errorf("Expected 2 titles in the full export, got %d", len(grouped))
+	}
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to decode %s: %v", path, err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// PriceSummary describes the spread of a price series
+type PriceSummary struct {
+	Count  int
+	Mean   float64
+	Median float64
+	P25    float64
+	P75    float64
+	StdDev float64
+}
+
+// priceStats summarises values. Percentiles interpolate linearly between the
+// closest ranks, so the median of an even count is the mean of the middle
+// two. StdDev is the population standard deviation. Empty input yields a
+// zero summary.
+func priceStats(values []float64) PriceSummary {
+	if len(values) == 0 {
+		return PriceSummary{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+	mean := total / float64(len(sorted))
+
+	var squares float64
+	for _, v := range sorted {
+		squares += (v - mean) * (v - mean)
+	}
+
+	return PriceSummary{
+		Count:  len(sorted),
+		Mean:   mean,
+		Median: percentile(sorted, 50),
+		P25:    percentile(sorted, 25),
+		P75:    percentile(sorted, 75),
+		StdDev: math.Sqrt(squares / float64(len(sorted))),
+	}
+}
+
+// percentile returns the pth percentile of sorted, which must not be empty
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower == len(sorted)-1 {
+		return sorted[lower]
+	}
+	frac := rank - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
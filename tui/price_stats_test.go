@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   PriceSummary
+	}{
+		{"empty", nil, PriceSummary{}},
+		{"single", []float64{42}, PriceSummary{Count: 1, Mean: 42, Median: 42, P25: 42, P75: 42}},
+		{
+			"odd count",
+			[]float64{50, 10, 40, 20, 30},
+			PriceSummary{Count: 5, Mean: 30, Median: 30, P25: 20, P75: 40, StdDev: math.Sqrt(200)},
+		},
+		{
+			"even count",
+			[]float64{4, 1, 3, 2},
+			PriceSummary{Count: 4, Mean: 2.5, Median: 2.5, P25: 1.75, P75: 3.25, StdDev: math.Sqrt(1.25)},
+		},
+		{
+			"constant",
+			[]float64{99.99, 99.99, 99.99},
+			PriceSummary{Count: 3, Mean: 99.99, Median: 99.99, P25: 99.99, P75: 99.99},
+		},
+		{
+			"known deviation",
+			[]float64{2, 4, 4, 4, 5, 5, 7, 9},
+			PriceSummary{Count: 8, Mean: 5, Median: 4.5, P25: 4, P75: 5.5, StdDev: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := priceStats(tt.values)
+			if got.Count != tt.want.Count {
+				t.Errorf("Expected count %d, got %d", tt.want.Count, got.Count)
+			}
+			fields := []struct {
+				name      string
+				got, want float64
+			}{
+				{"mean", got.Mean, tt.want.Mean},
+				{"median", got.Median, tt.want.Median},
+				{"p25", got.P25, tt.want.P25},
+				{"p75", got.P75, tt.want.P75},
+				{"std dev", got.StdDev, tt.want.StdDev},
+			}
+			for _, f := range fields {
+				if math.Abs(f.got-f.want) > 1e-9 {
+					t.Errorf("Expected %s %.4f, got %.4f", f.name, f.want, f.got)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceStatsLeavesInputUnsorted(t *testing.T) {
+	values := []float64{3, 1, 2}
+	priceStats(values)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("Expected the input to be left alone, got %v", values)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// trendIntervals are the bucket sizes a price trend can be grouped by
+var trendIntervals = []string{"day", "week", "month"}
+
+// trendIntervalLabels names each of trendIntervals in a trend's label
+var trendIntervalLabels = map[string]string{
+	"day":   "daily",
+	"week":  "weekly",
+	"month": "monthly",
+}
+
+// statsTrendInterval buckets the long-term trend charted in the stats pane
+const statsTrendInterval = "week"
+
+// maxLocalTrendHistory caps the price history read to build a trend locally
+const maxLocalTrendHistory = 10000
+
+// validTrendInterval reports whether interval is one of trendIntervals
+func validTrendInterval(interval string) bool {
+	for _, i := range trendIntervals {
+		if i == interval {
+			return true
+		}
+	}
+	return false
+}
+
+// trendBucket returns the start of the interval bucket t falls in, in UTC.
+// Weeks start on Monday.
+func trendBucket(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case "week":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "month":
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// localPriceTrend builds the price trend of query from the local price
+// history, in the same shape as the API returns it
+func localPriceTrend(db *Database, query, source, interval string) ([]TrendPoint, error) {
+	history, err := db.GetPriceHistoryInRange(query, time.Time{}, maxLocalTrendHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	type bucket struct {
+		total float64
+		count int
+	}
+	buckets := make(map[time.Time]*bucket)
+	for _, h := range history {
+		if source != "" && !strings.EqualFold(h.Source, source) {
+			continue
+		}
+		start := trendBucket(h.Timestamp, interval)
+		b, ok := buckets[start]
+		if !ok {
+			b = &bucket{}
+			buckets[start] = b
+		}
+		b.total += h.Price
+		b.count++
+	}
+
+	points := make([]TrendPoint, 0, len(buckets))
+	for start, b := range buckets {
+		points = append(points, TrendPoint{
+			Timestamp: float64(start.Unix()),
+			AvgPrice:  b.total / float64(b.count),
+			Count:     b.count,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp < points[j].Timestamp
+	})
+	return points, nil
+}
+
+// trendUnavailable reports whether err means the API can't serve a price
+// trend at all: it is unreachable, or too old to have the endpoint
+func trendUnavailable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusNotImplemented
+	}
+	return isUnreachable(err)
+}
+
+// priceTrend retrieves the price trend of query from the API, falling back
+// to the local price history when the API can't provide one. local reports
+// whether the points came from the local history.
+func priceTrend(ctx context.Context, client *APIClient, db *Database, query, source, interval string) (points []TrendPoint, local bool, err error) {
+	if !validTrendInterval(interval) {
+		return nil, false, fmt.Errorf("unknown trend interval %q", interval)
+	}
+	if client != nil {
+		points, err = client.GetPriceTrendCtx(ctx, query, source, interval)
+		if err == nil || !trendUnavailable(err) {
+			return points, false, err
+		}
+	}
+	if db == nil {
+		if err == nil {
+			err = errors.New("no database available")
+		}
+		return nil, false, err
+	}
+
+	points, err = localPriceTrend(db, query, source, interval)
+	return points, true, err
+}
+
+// loadPriceTrend fetches the long-term trend of title from source, or every
+// source when it is empty, off the UI goroutine
+func loadPriceTrend(client *APIClient, db *Database, title, source string) tea.Cmd {
+	return func() tea.Msg {
+		points, local, err := priceTrend(context.Background(), client, db, title, source, statsTrendInterval)
+		return PriceTrendMsg{Title: title, Source: source, Points: points, Local: local, Error: err}
+	}
+}
+
+// trendSource is the source a long-term trend is narrowed to when provider
+// was searched: all providers cover every source
+func trendSource(provider string) string {
+	if provider == allProvider {
+		return ""
+	}
+	return provider
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTrendBucket(t *testing.T) {
+	// A Thursday afternoon
+	at := time.Date(2025, time.March, 13, 15, 30, 0, 0, time.UTC)
+	for interval, want := range map[string]time.Time{
+		"day":   time.Date(2025, time.March, 13, 0, 0, 0, 0, time.UTC),
+		"week":  time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC),
+		"month": time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got := trendBucket(at, interval); !got.Equal(want) {
+			t.Errorf("%s: expected bucket %v, got %v", interval, want, got)
+		}
+	}
+
+	// Sunday belongs to the week that started the Monday before
+	sunday := time.Date(2025, time.March, 16, 23, 0, 0, 0, time.UTC)
+	if got := trendBucket(sunday, "week"); got.Day() != 10 {
+		t.Errorf("Expected Sunday in the week of the 10th, got %v", got)
+	}
+}
+
+// seedTrendHistory records prices of an RTX 3060 across three weeks
+func seedTrendHistory(t *testing.T, db *Database) {
+	t.Helper()
+	for _, r := range []struct {
+		price  float64
+		source string
+		at     string
+	}{
+		{300, "ebay", "2025-03-03 10:00:00"},
+		{280, "govdeals", "2025-03-05 10:00:00"},
+		{260, "ebay", "2025-03-11 10:00:00"},
+		{240, "ebay", "2025-03-20 10:00:00"},
+	} {
+		if _, err := db.db.Exec(
+			"INSERT INTO price_history (item_title, price, source, timestamp, metadata) VALUES ('RTX 3060', ?, ?, ?, '{}')",
+			r.price, r.source, r.at,
+		); err != nil {
+			t.Fatalf("Failed to seed price history: %v", err)
+		}
+	}
+}
+
+func TestPriceTrendFallsBackToLocalHistory(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	seedTrendHistory(t, db)
+
+	// A backend without the trend endpoint
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	client := NewAPIClient(server.URL)
+
+	points, local, err := priceTrend(context.Background(), client, db, "RTX 3060", "", "week")
+	if err != nil {
+		t.Fatalf("Failed to get price trend: %v", err)
+	}
+	if !local {
+		t.Error("Expected the trend to come from the local history")
+	}
+	want := []TrendPoint{
+		{Timestamp: float64(time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC).Unix()), AvgPrice: 290, Count: 2},
+		{Timestamp: float64(time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC).Unix()), AvgPrice: 260, Count: 1},
+		{Timestamp: float64(time.Date(2025, time.March, 17, 0, 0, 0, 0, time.UTC).Unix()), AvgPrice: 240, Count: 1},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("Expected %d weekly points, got %+v", len(want), points)
+	}
+	for i := range want {
+		if points[i] != want[i] {
+			t.Errorf("Point %d: expected %+v, got %+v", i, want[i], points[i])
+		}
+	}
+
+	// Narrowed to one source
+	points, _, err = priceTrend(context.Background(), client, db, "RTX 3060", "govdeals", "month")
+	if err != nil || len(points) != 1 || points[0].AvgPrice != 280 {
+		t.Errorf("Expected one govdeals point of $280, got %+v, %v", points, err)
+	}
+
+	// An unreachable backend falls back too
+	server.Close()
+	if _, local, err := priceTrend(context.Background(), client, db, "RTX 3060", "", "day"); err != nil || !local {
+		t.Errorf("Expected a local trend with the backend down, got local=%v, %v", local, err)
+	}
+}
+
+func TestPriceTrendPrefersAPI(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	seedTrendHistory(t, db)
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, "boom", status)
+			return
+		}
+		w.Write([]byte(`[{"ts": 1704067200, "avg_price": 410, "count": 30}, {"ts": 1735689600, "avg_price": 275, "count": 52}]`))
+	}))
+	defer server.Close()
+	client := NewAPIClient(server.URL)
+
+	points, local, err := priceTrend(context.Background(), client, db, "RTX 3060", "", "month")
+	if err != nil || local || len(points) != 2 || points[0].AvgPrice != 410 {
+		t.Errorf("Expected the API's trend, got %+v, local=%v, %v", points, local, err)
+	}
+
+	// A server error isn't hidden behind the local history
+	status = http.StatusInternalServerError
+	if _, local, err := priceTrend(context.Background(), client, db, "RTX 3060", "", "month"); err == nil || local {
+		t.Errorf("Expected the server error, got local=%v, %v", local, err)
+	}
+}
+
+func TestStatsShowsLongTermTrend(t *testing.T) {
+	pane := NewStatsPane(nil)
+	pane.priceHist = []PriceHistory{
+		{ItemTitle: "GPU", Price: 250},
+		{ItemTitle: "GPU", Price: 300},
+		{ItemTitle: "CPU", Price: 90},
+	}
+	points := []TrendPoint{{Timestamp: 1, AvgPrice: 410}, {Timestamp: 2, AvgPrice: 275}}
+
+	// A trend for an item no longer charted is dropped
+	pane.ApplyTrend(PriceTrendMsg{Title: "CPU", Points: points})
+	if view := pane.View(120, 40); strings.Contains(view, "Long-term") {
+		t.Errorf("Expected no long-term trend for another item, got:\n%s", view)
+	}
+
+	pane.ApplyTrend(PriceTrendMsg{Title: "GPU", Points: points, Local: true})
+	view := pane.View(120, 40)
+	if !strings.Contains(view, "Long-term (weekly, local):") || !strings.Contains(view, "$410.00 → $275.00") {
+		t.Errorf("Expected the long-term trend of GPU, got:\n%s", view)
+	}
+
+	// Searching one provider narrows the trend to it
+	pane.source = "ebay"
+	if view := pane.View(120, 40); strings.Contains(view, "Long-term") {
+		t.Errorf("Expected the every-source trend hidden once ebay is selected, got:\n%s", view)
+	}
+	pane.ApplyTrend(PriceTrendMsg{Title: "GPU", Source: "ebay", Points: points})
+	if view := pane.View(120, 40); !strings.Contains(view, "Long-term (weekly, ebay, API):") {
+		t.Errorf("Expected the ebay trend, got:\n%s", view)
+	}
+}
+
+func TestTrendIntervalLabels(t *testing.T) {
+	for _, interval := range trendIntervals {
+		if trendIntervalLabels[interval] == "" {
+			t.Errorf("Expected a label for the %q interval", interval)
+		}
+	}
+	if got := trendIntervalLabels["day"]; got != "daily" {
+		t.Errorf("Expected 'daily', got '%s'", got)
+	}
+}
+
+func TestSearchNarrowsTrendToProvider(t *testing.T) {
+	sources := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/comps/trend" {
+			sources <- r.URL.Query().Get("source")
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.stats.priceHist = []PriceHistory{{ItemTitle: "GPU", Price: 250}}
+
+	_, cmd := m.Update(SearchMsg{Query: "gpu", Provider: "govdeals"})
+	if m.stats.source != "govdeals" {
+		t.Fatalf("Expected the stats to follow the govdeals search, got '%s'", m.stats.source)
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the search and a trend reload, got %T", cmd())
+	}
+	msg, ok := batch[1]().(PriceTrendMsg)
+	if !ok || msg.Source != "govdeals" || <-sources != "govdeals" {
+		t.Errorf("Expected the trend of govdeals, got %+v", msg)
+	}
+
+	// Every provider covers every source
+	m.Update(SearchMsg{Query: "gpu", Provider: allProvider})
+	if m.stats.source != "" {
+		t.Errorf("Expected no source for all providers, got '%s'", m.stats.source)
+	}
+}
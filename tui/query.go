@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+)
+
+// parseQuery splits a search query into the terms to search for and the
+// terms to exclude, which are written with a leading "-", as in
+// "switch -case -screen". Excluded terms are lowercased for matching; a lone
+// "-" is kept as a search term.
+func parseQuery(raw string) (terms, excludes []string) {
+	for _, field := range strings.Fields(raw) {
+		if len(field) > 1 && field[0] == '-' {
+			excludes = append(excludes, strings.ToLower(field[1:]))
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms, excludes
+}
+
+// serverQuery is the part of a search query sent to the API. Exclusions are
+// applied to the results locally, since the server may not support them.
+func serverQuery(raw string) string {
+	terms, _ := parseQuery(raw)
+	return strings.Join(terms, " ")
+}
+
+// titleExcluded reports whether title contains any of the excluded terms,
+// ignoring case
+func titleExcluded(title string, excludes []string) bool {
+	if len(excludes) == 0 {
+		return false
+	}
+	title = strings.ToLower(title)
+	for _, term := range excludes {
+		if strings.Contains(title, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeListings drops the listings whose titles contain an excluded term
+func excludeListings(listings []APIListing, excludes []string) []APIListing {
+	if len(excludes) == 0 {
+		return listings
+	}
+	kept := make([]APIListing, 0, len(listings))
+	for _, l := range listings {
+		if !titleExcluded(l.Title, excludes) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		raw      string
+		terms    []string
+		excludes []string
+	}{
+		{"switch", []string{"switch"}, nil},
+		{"switch -case -screen", []string{"switch"}, []string{"case", "screen"}},
+		{"  nintendo  -Case switch ", []string{"nintendo", "switch"}, []string{"case"}},
+		{"usb-c hub", []string{"usb-c", "hub"}, nil},
+		{"rtx - 3060", []string{"rtx", "-", "3060"}, nil},
+		{"-broken", nil, []string{"broken"}},
+		{"", nil, nil},
+	}
+
+	for _, tt := range tests {
+		terms, excludes := parseQuery(tt.raw)
+		if !reflect.DeepEqual(terms, tt.terms) {
+			t.Errorf("parseQuery(%q): expected terms %q, got %q", tt.raw, tt.terms, terms)
+		}
+		if !reflect.DeepEqual(excludes, tt.excludes) {
+			t.Errorf("parseQuery(%q): expected excludes %q, got %q", tt.raw, tt.excludes, excludes)
+		}
+	}
+
+	if got := serverQuery("switch -case -screen"); got != "switch" {
+		t.Errorf("Expected the server query 'switch', got '%s'", got)
+	}
+}
+
+func TestSearchExclusionsFilterResults(t *testing.T) {
+	m := newTestModel("http://localhost:8080")
+	m.search.lastQuery = "switch -case -SCREEN"
+	tm, _ := m.Update(SearchResultMsg{
+		Query: "switch",
+		Results: []APIListing{
+			{Title: "Nintendo Switch Console", Price: 200},
+			{Title: "Switch Carrying Case", Price: 15},
+			{Title: "Cisco 24-Port Switch", Price: 80},
+			{Title: "Switch Screen Protector", Price: 8},
+		},
+	})
+	m = tm.(model)
+
+	if got := resultTitles(m.results); got != "Nintendo Switch Console,Cisco 24-Port Switch" {
+		t.Errorf("Expected excluded titles to be dropped, got '%s'", got)
+	}
+	if got := m.results.filter.String(); got != "excluding case, screen" {
+		t.Errorf("Expected the filter to describe the exclusions, got '%s'", got)
+	}
+
+	// The filter bar keeps the exclusions, like the source filter
+	m.results.Update(keyMsg("/"))
+	m.results.filterBar.inputs[1].SetValue("100")
+	m.results.Update(keyMsg("enter"))
+	if got := resultTitles(m.results); got != "Cisco 24-Port Switch" {
+		t.Errorf("Expected the price filter to combine with the exclusions, got '%s'", got)
+	}
+
+	// A search without exclusions shows everything again
+	m.search.lastQuery = "switch"
+	tm, _ = m.Update(SearchResultMsg{
+		Query:   "switch",
+		Results: []APIListing{{Title: "Switch Carrying Case", Price: 15}},
+	})
+	m = tm.(model)
+	if got := resultTitles(m.results); got != "Switch Carrying Case" {
+		t.Errorf("Expected no exclusions for a plain query, got '%s'", got)
+	}
+}
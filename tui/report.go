@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportName is the base name of opportunities report files, which are
+// suffixed with the time they were generated
+const reportName = "arbfinder_report"
+
+// reportTimeFormat stamps report file names so reports never overwrite
+// each other
+const reportTimeFormat = "20060102-150405"
+
+// opportunityReport is the listings of one search matched to comps and
+// ordered by margin, best first
+type opportunityReport struct {
+	Query         string
+	Generated     time.Time
+	Opportunities []Opportunity
+}
+
+// reportSummary is the headline numbers of a report
+type reportSummary struct {
+	Listings    int
+	Matched     int // listings with a comp
+	Profitable  int // listings with a positive margin
+	BestMargin  float64
+	AvgMargin   float64 // mean margin of the matched listings
+	TotalProfit float64 // sum of the positive margins
+	Prices      PriceSummary
+}
+
+// newReport computes the margins of listings against comps and sorts them by
+// margin, highest first. Listings without a comp go last, in their original
+// order.
+func newReport(query string, listings []APIListing, comps []APIComp, generated time.Time) opportunityReport {
+	opportunities := ComputeMargins(listings, comps)
+	sort.SliceStable(opportunities, func(i, j int) bool {
+		a, b := opportunities[i], opportunities[j]
+		if a.HasMatch() != b.HasMatch() {
+			return a.HasMatch()
+		}
+		return a.Margin > b.Margin
+	})
+	return opportunityReport{Query: query, Generated: generated, Opportunities: opportunities}
+}
+
+// summary totals the report's opportunities
+func (r opportunityReport) summary() reportSummary {
+	s := reportSummary{Listings: len(r.Opportunities)}
+	prices := make([]float64, 0, len(r.Opportunities))
+	var marginTotal float64
+	for _, o := range r.Opportunities {
+		prices = append(prices, o.Listing.Price)
+		if !o.HasMatch() {
+			continue
+		}
+		if s.Matched == 0 || o.Margin > s.BestMargin {
+			s.BestMargin = o.Margin
+		}
+		s.Matched++
+		marginTotal += o.Margin
+		if o.Margin > 0 {
+			s.Profitable++
+			s.TotalProfit += o.Margin
+		}
+	}
+	if s.Matched > 0 {
+		s.AvgMargin = marginTotal / float64(s.Matched)
+	}
+	s.Prices = priceStats(prices)
+	return s
+}
+
+// writeMarkdown writes the report as a Markdown document: a summary followed
+// by a table of every listing
+func (r opportunityReport) writeMarkdown(w io.Writer) error {
+	s := r.summary()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Opportunities report: %s\n\n", markdownCell(r.Query))
+	fmt.Fprintf(&b, "Generated %s\n\n", r.Generated.Format("2006-01-02 15:04 MST"))
+
+	b.WriteString("## Summary\n\n")
+	fmt.Fprintf(&b, "- Listings: %d\n", s.Listings)
+	fmt.Fprintf(&b, "- With comps: %d\n", s.Matched)
+	fmt.Fprintf(&b, "- Profitable: %d\n", s.Profitable)
+	if s.Matched > 0 {
+		fmt.Fprintf(&b, "- Best margin: $%.2f\n", s.BestMargin)
+		fmt.Fprintf(&b, "- Average margin: $%.2f\n", s.AvgMargin)
+		fmt.Fprintf(&b, "- Total potential profit: $%.2f\n", s.TotalProfit)
+	}
+	if s.Prices.Count > 0 {
+		fmt.Fprintf(&b, "- Listing prices: median $%.2f, mean $%.2f, IQR $%.2f–$%.2f\n",
+			s.Prices.Median, s.Prices.Mean, s.Prices.P25, s.Prices.P75)
+	}
+
+	b.WriteString("\n## Listings\n\n")
+	if len(r.Opportunities) == 0 {
+		b.WriteString("No listings found.\n")
+	} else {
+		b.WriteString("| # | Title | Source | Price | Comp | Comp Median | Margin | Margin % |\n")
+		b.WriteString("|---|---|---|---:|---|---:|---:|---:|\n")
+		for i, o := range r.Opportunities {
+			title := markdownLink(o.Listing.Title, o.Listing.URL)
+			comp, median, margin, pct := "—", "—", "—", "—"
+			if o.HasMatch() {
+				comp = markdownCell(o.Comp.KeyTitle)
+				median = fmt.Sprintf("$%.2f", o.Comp.MedianPrice)
+				margin = fmt.Sprintf("$%.2f", o.Margin)
+				pct = fmt.Sprintf("%.1f%%", o.MarginPct)
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s | $%.2f | %s | %s | %s | %s |\n",
+				i+1, title, markdownCell(o.Listing.Source), o.Listing.Price, comp, median, margin, pct)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// reportCSVHeader names the columns written by writeCSV
+var reportCSVHeader = []string{"rank", "id", "source", "title", "price", "currency", "condition", "url", "comp", "comp_median", "margin", "margin_pct"}
+
+// writeCSV writes the report's listings as CSV with a header row. The comp
+// and margin columns are empty for listings without a comp.
+func (r opportunityReport) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(reportCSVHeader); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	for i, o := range r.Opportunities {
+		comp, median, margin, pct := "", "", "", ""
+		if o.HasMatch() {
+			comp = o.Comp.KeyTitle
+			median = strconv.FormatFloat(o.Comp.MedianPrice, 'f', 2, 64)
+			margin = strconv.FormatFloat(o.Margin, 'f', 2, 64)
+			pct = strconv.FormatFloat(o.MarginPct, 'f', 1, 64)
+		}
+		record := []string{
+			strconv.Itoa(i + 1),
+			strconv.Itoa(o.Listing.ID),
+			o.Listing.Source,
+			o.Listing.Title,
+			strconv.FormatFloat(o.Listing.Price, 'f', 2, 64),
+			valueOr(o.Listing.Currency, defaultCurrency),
+			o.Listing.Condition,
+			o.Listing.URL,
+			comp,
+			median,
+			margin,
+			pct,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeFiles writes the report to dir as Markdown and CSV, named after the
+// time it was generated, and returns their paths
+func (r opportunityReport) writeFiles(dir string) (mdPath, csvPath string, err error) {
+	base := filepath.Join(dir, reportName+"_"+r.Generated.Format(reportTimeFormat))
+	mdPath, csvPath = base+".md", base+".csv"
+	if err := writeReportFile(mdPath, r.writeMarkdown); err != nil {
+		return "", "", err
+	}
+	if err := writeReportFile(csvPath, r.writeCSV); err != nil {
+		return "", "", err
+	}
+	return mdPath, csvPath, nil
+}
+
+// writeReportFile creates path and fills it with write
+func writeReportFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// markdownCell makes text safe to put in a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", `\|`)
+}
+
+// markdownTextEscaper and markdownURLEscaper escape what would end a link's
+// text or URL early, or its table cell
+var (
+	markdownTextEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+	markdownURLEscaper  = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "|", "%7C")
+)
+
+// markdownLink renders text as a link to url for a Markdown table cell, or
+// as plain text without a URL
+func markdownLink(text, url string) string {
+	text = markdownCell(text)
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("[%s](%s)", markdownTextEscaper.Replace(text), markdownURLEscaper.Replace(url))
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportFixture is a search for "rtx 3060" with one listing per kind of
+// margin: profitable, loss-making and without a comp
+func reportFixture() opportunityReport {
+	listings := []APIListing{
+		{ID: 1, Source: "ebay", Title: "Dell | Monitor", Price: 50, URL: "https://ebay.com/1"},
+		{ID: 2, Source: "ebay", Title: "RTX 3060 12GB", Price: 350},
+		{ID: 3, Source: "govdeals", Title: "RTX 3060 Ti", Price: 250, URL: "https://govdeals.com/3"},
+		{ID: 4, Source: "ebay", Title: "RTX 3060 Gaming", Price: 200, Currency: "EUR"},
+	}
+	comps := []APIComp{
+		{KeyTitle: "rtx 3060", MedianPrice: 300},
+		{KeyTitle: "rtx 3060 ti", MedianPrice: 400},
+	}
+	generated := time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+	return newReport("rtx 3060 -broken", listings, comps, generated)
+}
+
+func TestNewReportSortsByMargin(t *testing.T) {
+	report := reportFixture()
+
+	var ids []int
+	for _, o := range report.Opportunities {
+		ids = append(ids, o.Listing.ID)
+	}
+	// Margins 150, 100 and -50, then the listing without a comp
+	if got := fmt.Sprint(ids); got != "[3 4 2 1]" {
+		t.Errorf("Expected listings ordered [3 4 2 1], got %s", got)
+	}
+
+	s := report.summary()
+	if s.Listings != 4 || s.Matched != 3 || s.Profitable != 2 {
+		t.Errorf("Expected 4 listings, 3 matched and 2 profitable, got %+v", s)
+	}
+	if s.BestMargin != 150 || s.AvgMargin != 200.0/3 || s.TotalProfit != 250 {
+		t.Errorf("Expected best 150, average 66.67 and total 250, got %+v", s)
+	}
+	if s.Prices.Median != 225 {
+		t.Errorf("Expected a median listing price of 225, got %v", s.Prices.Median)
+	}
+
+	// A loss is still the best margin when it is the only one
+	only := newReport("rtx", []APIListing{{Title: "RTX 3060", Price: 400}}, []APIComp{{KeyTitle: "rtx 3060", MedianPrice: 300}}, time.Now())
+	if s := only.summary(); s.BestMargin != -100 || s.Profitable != 0 {
+		t.Errorf("Expected a best margin of -100 and nothing profitable, got %+v", s)
+	}
+}
+
+func TestReportMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	if err := reportFixture().writeMarkdown(&out); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	md := out.String()
+
+	for _, want := range []string{
+		"# Opportunities report: rtx 3060 -broken\n",
+		"Generated 2026-03-14 09:26 UTC",
+		"- With comps: 3\n",
+		"- Best margin: $150.00\n",
+		"- Average margin: $66.67\n",
+		"- Total potential profit: $250.00\n",
+		"| 1 | [RTX 3060 Ti](https://govdeals.com/3) | govdeals | $250.00 | rtx 3060 ti | $400.00 | $150.00 | 37.5% |\n",
+		"| 3 | RTX 3060 12GB | ebay | $350.00 | rtx 3060 | $300.00 | $-50.00 | -16.7% |\n",
+		"| 4 | [Dell \\| Monitor](https://ebay.com/1) | ebay | $50.00 | — | — | — | — |\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected the report to contain %q, got:\n%s", want, md)
+		}
+	}
+
+	out.Reset()
+	empty := opportunityReport{Query: "nothing", Generated: time.Now()}
+	if err := empty.writeMarkdown(&out); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	if !strings.Contains(out.String(), "No listings found.") || strings.Contains(out.String(), "Best margin") {
+		t.Errorf("Expected an empty report without margins, got:\n%s", out.String())
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := reportFixture().writeCSV(&out); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read report CSV: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected a header and 4 rows, got %d", len(records))
+	}
+	if got := strings.Join(records[0], ","); got != strings.Join(reportCSVHeader, ",") {
+		t.Errorf("Expected the report header, got '%s'", got)
+	}
+	if got := strings.Join(records[2], ","); got != "2,4,ebay,RTX 3060 Gaming,200.00,EUR,,,rtx 3060,300.00,100.00,33.3" {
+		t.Errorf("Unexpected second row '%s'", got)
+	}
+	if got := strings.Join(records[4], ","); got != "4,1,ebay,Dell | Monitor,50.00,USD,,https://ebay.com/1,,,," {
+		t.Errorf("Expected empty comp columns without a comp, got '%s'", got)
+	}
+}
+
+func TestSearchOnceWritesReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/listings/search":
+			json.NewEncoder(w).Encode(APIResponse{Items: []APIListing{
+				{ID: 1, Title: "RTX 3060", Price: 250},
+				{ID: 2, Title: "RTX 3060 Broken", Price: 50},
+			}, Total: 2})
+		case "/api/comps/search":
+			if q := r.URL.Query().Get("q"); q != "rtx 3060" {
+				t.Errorf("Expected comps for 'rtx 3060', got '%s'", q)
+			}
+			json.NewEncoder(w).Encode([]APIComp{{KeyTitle: "rtx 3060", MedianPrice: 300}})
+		default:
+			t.Errorf("Unexpected request for '%s'", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	opts, err := parseFlags([]string{"--query", "rtx 3060 -broken", "--report"}, io.Discard)
+	if err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	var out bytes.Buffer
+	if err := searchOnce(NewAPIClient(server.URL), opts, AppConfig{}, &out); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "1 listings, 1 with comps, 1 profitable\n") {
+		t.Errorf("Expected a summary line, got %q", out.String())
+	}
+
+	reports, _ := filepath.Glob(filepath.Join(home, reportName+"_*.md"))
+	if len(reports) != 1 {
+		t.Fatalf("Expected one Markdown report, got %v", reports)
+	}
+	data, err := os.ReadFile(reports[0])
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(data), "| 1 | RTX 3060 | ") || strings.Contains(string(data), "Broken") {
+		t.Errorf("Expected the report to list only the unexcluded listing, got:\n%s", data)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(reports[0], ".md") + ".csv"); err != nil {
+		t.Errorf("Expected a CSV report alongside: %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resultsExportName is the file, without extension, written to the home
+// directory by the results export actions
+const resultsExportName = "arbfinder_results"
+
+// exportNoticeDuration is how long the export confirmation stays up
+const exportNoticeDuration = 5 * time.Second
+
+// toggleSelected adds the highlighted listing to the selection, or takes it
+// out. The selection is keyed by listing ID, so it survives re-sorting and
+// filtering.
+func (p *ResultsPane) toggleSelected() {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return
+	}
+
+	p.notice = ""
+	id := p.results[p.selectedIdx].ID
+	if id == 0 {
+		p.notice = "This listing has no ID, so it can't be selected"
+		return
+	}
+
+	if p.selected[id] {
+		delete(p.selected, id)
+		return
+	}
+	if p.selected == nil {
+		p.selected = make(map[int]bool)
+	}
+	p.selected[id] = true
+}
+
+// toggleSelectAll selects every shown listing, or clears the selection when
+// they are all selected already
+func (p *ResultsPane) toggleSelectAll() {
+	all := true
+	for _, l := range p.results {
+		if l.ID != 0 && !p.selected[l.ID] {
+			all = false
+			break
+		}
+	}
+	if all {
+		p.selected = nil
+		return
+	}
+
+	if p.selected == nil {
+		p.selected = make(map[int]bool, len(p.results))
+	}
+	for _, l := range p.results {
+		if l.ID != 0 {
+			p.selected[l.ID] = true
+		}
+	}
+}
+
+// pruneSelection drops selected IDs that are no longer loaded
+func (p *ResultsPane) pruneSelection() {
+	if len(p.selected) == 0 {
+		return
+	}
+	loaded := make(map[int]bool, len(p.all))
+	for _, l := range p.all {
+		loaded[l.ID] = true
+	}
+	for id := range p.selected {
+		if !loaded[id] {
+			delete(p.selected, id)
+		}
+	}
+}
+
+// exportSet returns the listings an export writes: the selected ones in
+// display order, or every shown listing when nothing is selected
+func (p *ResultsPane) exportSet() []APIListing {
+	if len(p.selected) == 0 {
+		return p.results
+	}
+	return p.selectedListings()
+}
+
+// selectedListings returns the selected listings in display order.
+// Selected listings hidden by the filter are included; the selection is an
+// explicit choice.
+func (p *ResultsPane) selectedListings() []APIListing {
+	listings := make([]APIListing, 0, len(p.selected))
+	for _, l := range p.all {
+		if p.selected[l.ID] {
+			listings = append(listings, l)
+		}
+	}
+	return listings
+}
+
+// openAllConfirmAbove is the number of selected listings that can be opened
+// without confirming, so a large selection doesn't flood the browser with
+// tabs by accident
+const openAllConfirmAbove = 10
+
+// openAllLimit caps how many listings one open-all opens, even once
+// confirmed
+const openAllLimit = 50
+
+// openAllSelected opens every selected listing in the browser, asking first
+// when more than openAllConfirmAbove are selected
+func (p *ResultsPane) openAllSelected() {
+	p.lastError = ""
+	p.notice = ""
+
+	listings := p.selectedListings()
+	if len(listings) == 0 {
+		p.notice = "Select listings with space or * first"
+		return
+	}
+	if len(listings) <= openAllConfirmAbove {
+		p.openListings(listings)
+		return
+	}
+
+	prompt := fmt.Sprintf("Open %d listings in the browser?", len(listings))
+	if len(listings) > openAllLimit {
+		prompt = fmt.Sprintf("Open the first %d of %d listings in the browser?", openAllLimit, len(listings))
+	}
+	p.confirm = NewConfirmDialog(prompt, func() tea.Cmd {
+		p.openListings(listings)
+		return nil
+	})
+}
+
+// openListings opens up to openAllLimit listings in the browser and reports
+// how many opened
+func (p *ResultsPane) openListings(listings []APIListing) {
+	if len(listings) > openAllLimit {
+		listings = listings[:openAllLimit]
+	}
+
+	opened, noURL := 0, 0
+	var firstErr error
+	for _, l := range listings {
+		if l.URL == "" {
+			noURL++
+			continue
+		}
+		if err := browserOpener(l.URL); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		opened++
+	}
+
+	p.notice = fmt.Sprintf("Opened %d of %d listings", opened, len(listings))
+	if noURL > 0 {
+		p.notice += fmt.Sprintf(" • %d without a URL", noURL)
+	}
+	if firstErr != nil {
+		p.lastError = fmt.Sprintf("%d failed to open: %s", len(listings)-opened-noURL, firstErr)
+	}
+}
+
+// exportResults writes the export set to the home directory as CSV or JSON
+func (p *ResultsPane) exportResults(ext string) tea.Cmd {
+	p.lastError = ""
+	p.notice = ""
+
+	listings := p.exportSet()
+	if len(listings) == 0 {
+		p.notice = "No listings to export"
+		return nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	path := filepath.Join(homeDir, resultsExportName+"."+ext)
+
+	if err := exportListingsFile(path, listings); err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+
+	p.notice = fmt.Sprintf("Exported %d listings to %s", len(listings), path)
+	return expireNotice(p.notice, exportNoticeDuration)
+}
+
+// exportListingsFile writes listings to path, as JSON when it ends in .json
+// and as CSV otherwise
+func exportListingsFile(path string, listings []APIListing) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+
+	write := writeListingsCSV
+	if filepath.Ext(path) == ".json" {
+		write = writeListingsJSON
+	}
+	if err := write(f, listings); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// writeListingsJSON writes listings as an indented JSON array
+func writeListingsJSON(w io.Writer, listings []APIListing) error {
+	encoded, err := json.MarshalIndent(listings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode listings: %w", err)
+	}
+	if _, err := w.Write(append(encoded, '\n')); err != nil {
+		return fmt.Errorf("failed to write listings: %w", err)
+	}
+	return nil
+}
+
+// listingsCSVHeader names the columns written by writeListingsCSV
+var listingsCSVHeader = []string{"id", "source", "title", "price", "currency", "condition", "url", "listed_at"}
+
+// writeListingsCSV writes listings as CSV with a header row. Listing times
+// are RFC 3339 in UTC, empty when a listing has none.
+func writeListingsCSV(w io.Writer, listings []APIListing) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(listingsCSVHeader); err != nil {
+		return fmt.Errorf("failed to write listings: %w", err)
+	}
+	for _, l := range listings {
+		listedAt := ""
+		if t, ok := l.Time(); ok {
+			listedAt = t.UTC().Format(time.RFC3339)
+		}
+		record := []string{
+			strconv.Itoa(l.ID),
+			l.Source,
+			l.Title,
+			strconv.FormatFloat(l.Price, 'f', 2, 64),
+			valueOr(l.Currency, defaultCurrency),
+			l.Condition,
+			l.URL,
+			listedAt,
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write listings: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write listings: %w", err)
+	}
+	return nil
+}
+
+// copyMarkdown copies the export set to the clipboard as a Markdown table.
+// When the clipboard is unavailable the table is written to the home
+// directory instead.
+func (p *ResultsPane) copyMarkdown() tea.Cmd {
+	p.lastError = ""
+	p.notice = ""
+
+	listings := p.exportSet()
+	if len(listings) == 0 {
+		p.notice = "No listings to copy"
+		return nil
+	}
+	table := listingsMarkdown(ComputeMargins(listings, p.comps))
+
+	if err := systemClipboard.WriteAll(table); err != nil {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			p.lastError = err.Error()
+			return nil
+		}
+		path := filepath.Join(homeDir, resultsExportName+".md")
+		if err := os.WriteFile(path, []byte(table), 0o644); err != nil {
+			p.lastError = fmt.Sprintf("failed to write Markdown table: %v", err)
+			return nil
+		}
+		p.notice = fmt.Sprintf("Clipboard unavailable - wrote %d listings to %s", len(listings), path)
+		return expireNotice(p.notice, exportNoticeDuration)
+	}
+	p.notice = fmt.Sprintf("Copied %d listings as a Markdown table", len(listings))
+	return expireNotice(p.notice, copiedNoticeDuration)
+}
+
+// listingsMarkdown renders listings as a GitHub-flavored Markdown table,
+// linking titles to their listings. The margin is a dash for listings
+// without a comp.
+func listingsMarkdown(opportunities []Opportunity) string {
+	var b strings.Builder
+	b.WriteString("| Title | Source | Price | Condition | Margin |\n")
+	b.WriteString("|---|---|---:|---|---:|\n")
+	for _, o := range opportunities {
+		l := o.Listing
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+			markdownLink(l.Title, l.URL),
+			markdownCell(l.Source),
+			formatPrice(l.Price, l.Currency),
+			markdownCell(l.Condition),
+			formatMargin(o))
+	}
+	return b.String()
+}
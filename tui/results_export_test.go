@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selectionTestPane returns a results pane with three listings, cheapest
+// last so sorting by price reorders them
+func selectionTestPane() *ResultsPane {
+	p := NewResultsPane(nil)
+	p.SetResults([]APIListing{
+		{ID: 1, Source: "govdeals", Title: "RTX 3060", Price: 300},
+		{ID: 2, Source: "ebay", Title: "ThinkPad X1", Price: 200},
+		{ID: 3, Source: "shopgoodwill", Title: "Steam Deck", Price: 100},
+	})
+	return p
+}
+
+func TestResultsSelection(t *testing.T) {
+	p := selectionTestPane()
+
+	*p, _ = p.Update(keyMsg(" "))
+	*p, _ = p.Update(keyMsg("j"))
+	*p, _ = p.Update(keyMsg("j"))
+	*p, _ = p.Update(keyMsg(" "))
+	if len(p.selected) != 2 || !p.selected[1] || !p.selected[3] {
+		t.Fatalf("Expected listings 1 and 3 selected, got %v", p.selected)
+	}
+
+	// Sorting moves the rows, not the selection
+	*p, _ = p.Update(keyMsg("p"))
+	if p.results[0].ID != 3 || !p.selected[3] || p.selected[2] {
+		t.Errorf("Expected the selection to follow the listings, got %v", p.selected)
+	}
+	view := p.View(120, 40)
+	if !strings.Contains(view, "2 selected") || strings.Count(view, "✓") != 2 {
+		t.Errorf("Expected two checked rows and a selection count:\n%s", view)
+	}
+
+	// Space again deselects
+	*p, _ = p.Update(keyMsg(" "))
+	if p.selected[3] || len(p.selected) != 1 {
+		t.Errorf("Expected listing 3 deselected, got %v", p.selected)
+	}
+
+	*p, _ = p.Update(keyMsg("A"))
+	if len(p.selected) != 3 {
+		t.Errorf("Expected 'A' to select all, got %v", p.selected)
+	}
+	*p, _ = p.Update(keyMsg("A"))
+	if len(p.selected) != 0 {
+		t.Errorf("Expected a second 'A' to clear the selection, got %v", p.selected)
+	}
+
+	// A new search drops selected listings it no longer contains
+	*p, _ = p.Update(keyMsg(" "))
+	p.SetResults([]APIListing{{ID: 4, Source: "ebay", Title: "iPad Air", Price: 150}})
+	if len(p.selected) != 0 {
+		t.Errorf("Expected stale selections to be dropped, got %v", p.selected)
+	}
+}
+
+func TestResultsExportSelected(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	p := selectionTestPane()
+	p.all[1].Timestamp = float64(time.Date(2024, 3, 9, 14, 30, 0, 0, time.UTC).Unix())
+	p.rebuild()
+	*p, _ = p.Update(keyMsg("j"))
+	*p, _ = p.Update(keyMsg(" "))
+
+	*p, _ = p.Update(keyMsg("e"))
+	if p.lastError != "" {
+		t.Fatalf("Failed to export CSV: %s", p.lastError)
+	}
+	f, err := os.Open(filepath.Join(home, "arbfinder_results.csv"))
+	if err != nil {
+		t.Fatalf("Failed to open CSV export: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV export: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected a header and 1 selected row, got %v", records)
+	}
+	want := []string{"2", "ebay", "ThinkPad X1", "200.00", "USD", "", "", "2024-03-09T14:30:00Z"}
+	if strings.Join(records[1], ",") != strings.Join(want, ",") {
+		t.Errorf("Expected row %v, got %v", want, records[1])
+	}
+
+	// With nothing selected every listing is exported
+	*p, _ = p.Update(keyMsg(" "))
+	*p, _ = p.Update(keyMsg("E"))
+	if p.lastError != "" {
+		t.Fatalf("Failed to export JSON: %s", p.lastError)
+	}
+	data, err := os.ReadFile(filepath.Join(home, "arbfinder_results.json"))
+	if err != nil {
+		t.Fatalf("Failed to read JSON export: %v", err)
+	}
+	var listings []APIListing
+	if err := json.Unmarshal(data, &listings); err != nil {
+		t.Fatalf("Failed to parse JSON export: %v", err)
+	}
+	if len(listings) != 3 {
+		t.Errorf("Expected all 3 listings without a selection, got %d", len(listings))
+	}
+	if !strings.Contains(p.notice, "Exported 3 listings") {
+		t.Errorf("Expected an export notice, got %q", p.notice)
+	}
+}
+
+func TestListingsMarkdown(t *testing.T) {
+	opportunities := ComputeMargins([]APIListing{
+		{Source: "ebay", Title: "Dell | Monitor  24\"", Price: 50, Condition: "used", URL: "https://ebay.com/1"},
+		{Source: "govdeals", Title: "RTX 3060 [Ti]", Price: 250, URL: "https://govdeals.com/search?q=rtx (ti)"},
+		{Source: "ebay", Title: "RTX 3060", Price: 200, Currency: "EUR", Condition: "new|sealed"},
+	}, []APIComp{{KeyTitle: "rtx 3060", MedianPrice: 300}})
+
+	want := `| Title | Source | Price | Condition | Margin |
+|---|---|---:|---|---:|
+| [Dell \| Monitor 24"](https://ebay.com/1) | ebay | $50.00 | used | — |
+| [RTX 3060 \[Ti\]](https://govdeals.com/search?q=rtx%20%28ti%29) | govdeals | $250.00 |  | $50.00 (17%) |
+| RTX 3060 | ebay | €200.00 | new\|sealed | $100.00 (33%) |
+`
+	if got := listingsMarkdown(opportunities); got != want {
+		t.Errorf("Expected Markdown table\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestResultsCopyMarkdown(t *testing.T) {
+	clip := &recordingClipboard{}
+	systemClipboard = clip
+	defer func() { systemClipboard = osClipboard{} }()
+
+	p := selectionTestPane()
+	*p, _ = p.Update(keyMsg("j"))
+	*p, _ = p.Update(keyMsg(" "))
+
+	*p, _ = p.Update(keyMsg("M"))
+	want := "| Title | Source | Price | Condition | Margin |\n|---|---|---:|---|---:|\n| ThinkPad X1 | ebay | $200.00 |  | — |\n"
+	if clip.copied != want {
+		t.Errorf("Expected the selected listing as Markdown\n%s\ngot\n%s", want, clip.copied)
+	}
+	if p.notice != "Copied 1 listings as a Markdown table" {
+		t.Errorf("Expected a copy notice, got %q", p.notice)
+	}
+
+	// Without a clipboard every shown listing is written to a file instead
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	systemClipboard = &recordingClipboard{err: errors.New("no clipboard utilities available")}
+	*p, _ = p.Update(keyMsg(" "))
+	*p, _ = p.Update(keyMsg("M"))
+	data, err := os.ReadFile(filepath.Join(home, "arbfinder_results.md"))
+	if err != nil {
+		t.Fatalf("Failed to read Markdown fallback: %v", err)
+	}
+	if rows := strings.Count(string(data), "\n"); rows != 5 {
+		t.Errorf("Expected a header, a divider and 3 rows, got:\n%s", data)
+	}
+	if !strings.Contains(p.notice, "Clipboard unavailable") {
+		t.Errorf("Expected a fallback notice, got %q", p.notice)
+	}
+}
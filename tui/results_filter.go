@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// resultsFilter narrows the loaded results without another API call. Zero
+// price bounds and an empty condition, source or exclusion list mean "no
+// constraint".
+type resultsFilter struct {
+	minPrice  float64
+	maxPrice  float64
+	condition string
+	source    string   // cycled with f rather than typed in the filter bar
+	excludes  []string // the search query's "-term" exclusions
+}
+
+// active reports whether the filter constrains anything
+func (f resultsFilter) active() bool {
+	return f.minPrice > 0 || f.maxPrice > 0 || f.condition != "" || f.source != "" || len(f.excludes) > 0
+}
+
+// matches reports whether a listing passes every constraint of the filter
+func (f resultsFilter) matches(l APIListing) bool {
+	if f.minPrice > 0 && l.Price < f.minPrice {
+		return false
+	}
+	if f.maxPrice > 0 && l.Price > f.maxPrice {
+		return false
+	}
+	if f.condition != "" && !strings.Contains(strings.ToLower(l.Condition), f.condition) {
+		return false
+	}
+	if f.source != "" && !strings.EqualFold(l.Source, f.source) {
+		return false
+	}
+	if titleExcluded(l.Title, f.excludes) {
+		return false
+	}
+	return true
+}
+
+// String describes the active constraints for the pane footer
+func (f resultsFilter) String() string {
+	var parts []string
+	if f.minPrice > 0 {
+		parts = append(parts, fmt.Sprintf("price ≥ $%.2f", f.minPrice))
+	}
+	if f.maxPrice > 0 {
+		parts = append(parts, fmt.Sprintf("price ≤ $%.2f", f.maxPrice))
+	}
+	if f.condition != "" {
+		parts = append(parts, fmt.Sprintf("condition ~ %q", f.condition))
+	}
+	if f.source != "" {
+		parts = append(parts, "source = "+f.source)
+	}
+	if len(f.excludes) > 0 {
+		parts = append(parts, "excluding "+strings.Join(f.excludes, ", "))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// parseFilter builds a filter from the raw filter bar fields
+func parseFilter(minRaw, maxRaw, condition string) (resultsFilter, error) {
+	var f resultsFilter
+	var err error
+
+	if f.minPrice, err = parsePriceBound(minRaw); err != nil {
+		return resultsFilter{}, fmt.Errorf("min price: %w", err)
+	}
+	if f.maxPrice, err = parsePriceBound(maxRaw); err != nil {
+		return resultsFilter{}, fmt.Errorf("max price: %w", err)
+	}
+	if f.minPrice > 0 && f.maxPrice > 0 && f.minPrice > f.maxPrice {
+		return resultsFilter{}, fmt.Errorf("min price $%.2f is above max price $%.2f", f.minPrice, f.maxPrice)
+	}
+	f.condition = strings.ToLower(strings.TrimSpace(condition))
+
+	return f, nil
+}
+
+// parsePriceBound parses an optional non-negative price; empty means no bound
+func parsePriceBound(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+
+	price, err := strconv.ParseFloat(strings.TrimPrefix(raw, "$"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be a number, got %q", raw)
+	}
+	if price < 0 {
+		return 0, fmt.Errorf("must not be negative, got %g", price)
+	}
+
+	return price, nil
+}
+
+// resultSources returns the distinct sources of the listings, sorted
+func resultSources(listings []APIListing) []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, l := range listings {
+		if l.Source != "" && !seen[l.Source] {
+			seen[l.Source] = true
+			sources = append(sources, l.Source)
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// nextSource returns the source after current in the cycle "all" (empty),
+// then each of sources. A source no longer among them cycles back to "all".
+func nextSource(sources []string, current string) string {
+	if current == "" {
+		if len(sources) == 0 {
+			return ""
+		}
+		return sources[0]
+	}
+	for i, s := range sources {
+		if s == current && i+1 < len(sources) {
+			return sources[i+1]
+		}
+	}
+	return ""
+}
+
+// cycleSource shows the next source present in the loaded results
+func (p *ResultsPane) cycleSource() {
+	f := p.filter
+	f.source = nextSource(resultSources(p.all), f.source)
+	p.setFilter(f)
+}
+
+// filterBar is the inline form for editing the results filter
+type filterBar struct {
+	inputs     []textinput.Model // min price, max price, condition
+	focusIndex int
+}
+
+func newFilterBar(f resultsFilter) *filterBar {
+	placeholders := []string{"min $", "max $", "condition"}
+	values := []string{"", "", f.condition}
+	if f.minPrice > 0 {
+		values[0] = strconv.FormatFloat(f.minPrice, 'f', -1, 64)
+	}
+	if f.maxPrice > 0 {
+		values[1] = strconv.FormatFloat(f.maxPrice, 'f', -1, 64)
+	}
+
+	bar := &filterBar{inputs: make([]textinput.Model, len(placeholders))}
+	for i, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 12
+		if i < 2 {
+			input.Validate = validatePrice
+		}
+		input.SetValue(values[i])
+		bar.inputs[i] = input
+	}
+	bar.inputs[0].Focus()
+
+	return bar
+}
+
+// updateFilter handles input while the filter bar is open. Enter applies the
+// filter, Esc closes the bar leaving the current filter in place.
+func (p *ResultsPane) updateFilter(msg tea.Msg) (ResultsPane, tea.Cmd) {
+	bar := p.filterBar
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Filter.Close):
+			p.filterBar = nil
+			return *p, nil
+
+		case key.Matches(msg, keys.Filter.Apply):
+			f, err := parseFilter(bar.inputs[0].Value(), bar.inputs[1].Value(), bar.inputs[2].Value())
+			if err != nil {
+				p.lastError = err.Error()
+				return *p, nil
+			}
+			p.lastError = ""
+			p.filterBar = nil
+			f.source, f.excludes = p.filter.source, p.filter.excludes
+			p.setFilter(f)
+			return *p, nil
+
+		case key.Matches(msg, keys.Filter.PrevField):
+			bar.focus((bar.focusIndex + len(bar.inputs) - 1) % len(bar.inputs))
+			return *p, nil
+
+		case key.Matches(msg, keys.Filter.NextField):
+			bar.focus((bar.focusIndex + 1) % len(bar.inputs))
+			return *p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	if bar.inputs[bar.focusIndex].Validate != nil {
+		bar.inputs[bar.focusIndex], cmd = updateNumericInput(bar.inputs[bar.focusIndex], msg)
+	} else {
+		bar.inputs[bar.focusIndex], cmd = bar.inputs[bar.focusIndex].Update(msg)
+	}
+	return *p, cmd
+}
+
+func (b *filterBar) focus(index int) {
+	b.inputs[b.focusIndex].Blur()
+	b.focusIndex = index
+	b.inputs[b.focusIndex].Focus()
+}
+
+func (b *filterBar) View() string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	fields := make([]string, len(b.inputs))
+	for i, input := range b.inputs {
+		fields[i] = input.View()
+	}
+
+	return labelStyle.Render("Filter: ") + strings.Join(fields, "  ") + "\n" +
+		infoStyle.Render("↑/↓: Switch field • Enter: Apply • Esc: Close • empty fields clear the filter")
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func filterTestListings() []APIListing {
+	return []APIListing{
+		{Title: "A", Price: 10, Condition: "New"},
+		{Title: "B", Price: 50, Condition: "used - good"},
+		{Title: "C", Price: 100, Condition: "Used"},
+		{Title: "D", Price: 500, Condition: "for parts"},
+	}
+}
+
+func filteredTitles(f resultsFilter) string {
+	var titles []string
+	for _, l := range filterTestListings() {
+		if f.matches(l) {
+			titles = append(titles, l.Title)
+		}
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestResultsFilterPredicates(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter resultsFilter
+		want   string
+	}{
+		{"none", resultsFilter{}, "A,B,C,D"},
+		{"min price", resultsFilter{minPrice: 50}, "B,C,D"},
+		{"max price", resultsFilter{maxPrice: 100}, "A,B,C"},
+		{"condition", resultsFilter{condition: "used"}, "B,C"},
+		{"min and max", resultsFilter{minPrice: 20, maxPrice: 200}, "B,C"},
+		{"all combined", resultsFilter{minPrice: 60, maxPrice: 200, condition: "used"}, "C"},
+		{"no matches", resultsFilter{minPrice: 1000}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := filteredTitles(tt.filter); got != tt.want {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter("$20", " 200 ", " USED ")
+	if err != nil {
+		t.Fatalf("Failed to parse filter: %v", err)
+	}
+	if f.minPrice != 20 || f.maxPrice != 200 || f.condition != "used" {
+		t.Errorf("Unexpected filter %+v", f)
+	}
+
+	for _, bad := range [][2]string{{"abc", ""}, {"", "-1"}, {"300", "200"}} {
+		if _, err := parseFilter(bad[0], bad[1], ""); err == nil {
+			t.Errorf("Expected error for min=%q max=%q", bad[0], bad[1])
+		}
+	}
+}
+
+func TestResultsFilterBar(t *testing.T) {
+	m := newTestModel("")
+	m.results.SetResults(filterTestListings())
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(keyMsg("/"))
+	if !m.results.capturingInput() {
+		t.Fatal("Expected filter bar to capture input")
+	}
+
+	// Min price, ignoring the letter, then down to the condition field
+	for _, key := range []string{"5", "x", "0"} {
+		tm, _ = tm.Update(keyMsg(key))
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyDown})
+	tm, _ = tm.Update(keyMsg("u"))
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	pane := tm.(model).results
+	if pane.filterBar != nil {
+		t.Fatal("Expected filter bar to close on Enter")
+	}
+	if got := resultTitles(pane); got != "B,C" {
+		t.Fatalf("Expected B,C to be shown, got %s", got)
+	}
+	if !strings.Contains(pane.View(150, 40), "2 of 4 shown") {
+		t.Error("Expected '2 of 4 shown' in pagination line")
+	}
+
+	// Clearing restores everything
+	tm, _ = tm.Update(keyMsg("x"))
+	if got := len(tm.(model).results.results); got != 4 {
+		t.Errorf("Expected 4 results after clearing filter, got %d", got)
+	}
+}
+
+func sourceTestListings() []APIListing {
+	return []APIListing{
+		{Title: "A", Source: "govdeals", Price: 10},
+		{Title: "B", Source: "ebay", Price: 50},
+		{Title: "C", Source: "govdeals", Price: 100},
+		{Title: "D", Source: "shopgoodwill", Price: 500},
+		{Title: "E", Price: 20},
+	}
+}
+
+func TestResultSources(t *testing.T) {
+	got := strings.Join(resultSources(sourceTestListings()), ",")
+	if got != "ebay,govdeals,shopgoodwill" {
+		t.Errorf("Expected sorted distinct sources, got %s", got)
+	}
+	if sources := resultSources(nil); len(sources) != 0 {
+		t.Errorf("Expected no sources for no listings, got %v", sources)
+	}
+
+	sources := []string{"ebay", "govdeals"}
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{"", "ebay"},
+		{"ebay", "govdeals"},
+		{"govdeals", ""},
+		{"craigslist", ""},
+	}
+	for _, tt := range tests {
+		if got := nextSource(sources, tt.current); got != tt.want {
+			t.Errorf("Expected '%s' after '%s', got '%s'", tt.want, tt.current, got)
+		}
+	}
+	if got := nextSource(nil, ""); got != "" {
+		t.Errorf("Expected 'all' to stay put without sources, got '%s'", got)
+	}
+}
+
+func TestResultsSourceCycling(t *testing.T) {
+	m := newTestModel("")
+	m.results.SetResults(sourceTestListings())
+
+	var tm tea.Model = m
+	want := []string{"B", "A,C", "D", "A,B,C,D,E"}
+	for _, titles := range want {
+		tm, _ = tm.Update(keyMsg("f"))
+		if got := resultTitles(tm.(model).results); got != titles {
+			t.Fatalf("Expected %s after cycling, got %s", titles, got)
+		}
+	}
+
+	// A source filter combines with the filter bar and shows in the counts
+	tm, _ = tm.Update(keyMsg("f"))
+	tm, _ = tm.Update(keyMsg("f"))
+	tm, _ = tm.Update(keyMsg("/"))
+	for _, key := range []string{"5", "0"} {
+		tm, _ = tm.Update(keyMsg(key))
+	}
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	pane := tm.(model).results
+	if got := resultTitles(pane); got != "C" {
+		t.Fatalf("Expected only C with both filters, got %s", got)
+	}
+	view := pane.View(150, 40)
+	if !strings.Contains(view, "source = govdeals") || !strings.Contains(view, "1 of 5 shown") {
+		t.Errorf("Expected the source filter and count in the view, got:\n%s", view)
+	}
+
+	tm, _ = tm.Update(keyMsg("x"))
+	if got := resultTitles(tm.(model).results); got != "A,B,C,D,E" {
+		t.Errorf("Expected clearing to drop the source filter, got %s", got)
+	}
+}
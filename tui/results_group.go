@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// resultGroup is one source's share of the filtered results in the grouped
+// view
+type resultGroup struct {
+	Source   string
+	Count    int
+	AvgPrice float64
+	rows     []int // indexes of the group's listings in the grouped slice
+}
+
+// groupBySource buckets listings by source, sources in alphabetical order and
+// each group's listings in their existing, sorted, order
+func groupBySource(listings []APIListing) []resultGroup {
+	index := make(map[string]int)
+	var groups []resultGroup
+	for i, l := range listings {
+		g, ok := index[l.Source]
+		if !ok {
+			g = len(groups)
+			index[l.Source] = g
+			groups = append(groups, resultGroup{Source: l.Source})
+		}
+		groups[g].rows = append(groups[g].rows, i)
+		groups[g].Count++
+		groups[g].AvgPrice += l.Price
+	}
+
+	for i := range groups {
+		groups[i].AvgPrice /= float64(groups[i].Count)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Source < groups[j].Source
+	})
+	return groups
+}
+
+// groupResults reorders the displayed rows by source when the grouped view
+// is on, dropping the rows of collapsed groups
+func (p *ResultsPane) groupResults() {
+	if !p.grouped {
+		p.groups = nil
+		return
+	}
+
+	p.groups = groupBySource(p.results)
+	results := make([]APIListing, 0, len(p.results))
+	opportunities := make([]Opportunity, 0, len(p.results))
+	for _, g := range p.groups {
+		if p.collapsed[g.Source] {
+			continue
+		}
+		for _, i := range g.rows {
+			results = append(results, p.results[i])
+			opportunities = append(opportunities, p.opportunities[i])
+		}
+	}
+	p.results = results
+	p.opportunities = opportunities
+}
+
+// toggleGrouped switches between the flat list and the grouped view, keeping
+// the selected listing selected
+func (p *ResultsPane) toggleGrouped() {
+	p.grouped = !p.grouped
+	p.collapsed = nil
+	p.regroup()
+}
+
+// collapseGroup hides the rows of the selected listing's group, leaving its
+// header; the selection moves on to the next row shown
+func (p *ResultsPane) collapseGroup() {
+	if !p.grouped || p.selectedIdx >= len(p.results) {
+		return
+	}
+	if p.collapsed == nil {
+		p.collapsed = make(map[string]bool)
+	}
+	p.collapsed[p.results[p.selectedIdx].Source] = true
+	p.rebuild()
+	p.selectRow(p.selectedIdx)
+}
+
+// expandGroups shows every collapsed group again
+func (p *ResultsPane) expandGroups() {
+	if !p.grouped || len(p.collapsed) == 0 {
+		return
+	}
+	p.collapsed = nil
+	p.regroup()
+}
+
+// regroup rebuilds the rows after a grouping change and selects the listing
+// that was selected before, wherever it has moved to
+func (p *ResultsPane) regroup() {
+	var selected *APIListing
+	if p.selectedIdx < len(p.results) {
+		l := p.results[p.selectedIdx]
+		selected = &l
+	}
+
+	p.rebuild()
+
+	idx := 0
+	if selected != nil {
+		idx, _ = p.rowOf(*selected)
+	}
+	p.selectRow(idx)
+}
+
+// jumpGroup selects the first listing of the next group, or with dir < 0 of
+// the selected listing's group, or the group before it if already there
+func (p *ResultsPane) jumpGroup(dir int) {
+	if !p.grouped {
+		return
+	}
+
+	var starts []int
+	idx := 0
+	for _, g := range p.groups {
+		if !p.collapsed[g.Source] {
+			starts = append(starts, idx)
+			idx += g.Count
+		}
+	}
+
+	target := -1
+	for _, start := range starts {
+		if dir > 0 && start > p.selectedIdx {
+			target = start
+			break
+		}
+		if dir < 0 && start < p.selectedIdx {
+			target = start
+		}
+	}
+	if target >= 0 {
+		p.selectRow(target)
+	}
+}
+
+// groupHeader describes a group for its header line
+func groupHeader(g resultGroup, collapsed bool) string {
+	source := g.Source
+	if source == "" {
+		source = "(no source)"
+	}
+	header := fmt.Sprintf("▾ %s • %d listings • avg $%.2f", source, g.Count, g.AvgPrice)
+	if collapsed {
+		header = fmt.Sprintf("▸ %s • %d listings • avg $%.2f • collapsed", source, g.Count, g.AvgPrice)
+	}
+	return header
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGroupBySource(t *testing.T) {
+	groups := groupBySource(sourceTestListings())
+
+	want := []struct {
+		source string
+		count  int
+		avg    float64
+		rows   string
+	}{
+		{"", 1, 20, "E"},
+		{"ebay", 1, 50, "B"},
+		{"govdeals", 2, 55, "A,C"},
+		{"shopgoodwill", 1, 500, "D"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("Expected %d groups, got %+v", len(want), groups)
+	}
+	listings := sourceTestListings()
+	for i, w := range want {
+		g := groups[i]
+		if g.Source != w.source || g.Count != w.count || g.AvgPrice != w.avg {
+			t.Errorf("Expected group %s with %d listings averaging $%.2f, got %s with %d averaging $%.2f",
+				w.source, w.count, w.avg, g.Source, g.Count, g.AvgPrice)
+		}
+		var titles []string
+		for _, row := range g.rows {
+			titles = append(titles, listings[row].Title)
+		}
+		if got := strings.Join(titles, ","); got != w.rows {
+			t.Errorf("Expected %s rows %s, got %s", w.source, w.rows, got)
+		}
+	}
+
+	if groups := groupBySource(nil); len(groups) != 0 {
+		t.Errorf("Expected no groups for no listings, got %+v", groups)
+	}
+}
+
+func TestResultsGroupedView(t *testing.T) {
+	m := newTestModel("")
+	m.results.SetResults(sourceTestListings())
+	m.results.selectRow(3) // D
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(keyMsg("v"))
+	pane := tm.(model).results
+	if got := resultTitles(pane); got != "E,B,A,C,D" {
+		t.Fatalf("Expected rows grouped by source, got %s", got)
+	}
+	if got := pane.results[pane.selectedIdx].Title; got != "D" {
+		t.Errorf("Expected D to stay selected, got %s", got)
+	}
+	view := pane.View(150, 40)
+	for _, header := range []string{"▾ (no source) • 1 listings • avg $20.00", "▾ govdeals • 2 listings • avg $55.00", "4 sources"} {
+		if !strings.Contains(view, header) {
+			t.Errorf("Expected '%s' in the grouped view, got:\n%s", header, view)
+		}
+	}
+
+	// Headers aren't rows: a click on one selects nothing
+	if _, ok := pane.rowAt(pane.rowsTop); ok {
+		t.Error("Expected the first group header not to be a row")
+	}
+	if idx, ok := pane.rowAt(pane.rowsTop + 1); !ok || idx != 0 {
+		t.Errorf("Expected the line under the header to be row 0, got %d, %v", idx, ok)
+	}
+
+	// Jumping between groups
+	tm, _ = tm.Update(keyMsg("["))
+	if got := tm.(model).results.selectedIdx; got != 2 {
+		t.Errorf("Expected '[' to move to the govdeals group (2), got %d", got)
+	}
+	tm, _ = tm.Update(keyMsg("["))
+	tm, _ = tm.Update(keyMsg("["))
+	tm, _ = tm.Update(keyMsg("]"))
+	if got := tm.(model).results.selectedIdx; got != 1 {
+		t.Errorf("Expected ']' to move to the ebay group (1), got %d", got)
+	}
+
+	// Collapsing ebay hides its row but keeps its header
+	tm, _ = tm.Update(keyMsg("z"))
+	pane = tm.(model).results
+	if got := resultTitles(pane); got != "E,A,C,D" {
+		t.Fatalf("Expected ebay's rows to be hidden, got %s", got)
+	}
+	if got := pane.results[pane.selectedIdx].Title; got != "A" {
+		t.Errorf("Expected the selection to move on to A, got %s", got)
+	}
+	if view := pane.View(150, 40); !strings.Contains(view, "▸ ebay • 1 listings • avg $50.00 • collapsed") {
+		t.Errorf("Expected the collapsed header, got:\n%s", view)
+	}
+
+	tm, _ = tm.Update(keyMsg("Z"))
+	if got := resultTitles(tm.(model).results); got != "E,B,A,C,D" {
+		t.Errorf("Expected Z to expand every group, got %s", got)
+	}
+
+	// Collapsing everything leaves just the headers
+	for range 4 {
+		tm, _ = tm.Update(keyMsg("z"))
+	}
+	pane = tm.(model).results
+	if len(pane.results) != 0 {
+		t.Fatalf("Expected every group collapsed, got %s", resultTitles(pane))
+	}
+	view = pane.View(150, 40)
+	if strings.Count(view, "• collapsed") != 4 || !strings.Contains(view, "Every source is collapsed") {
+		t.Errorf("Expected 4 collapsed headers, got:\n%s", view)
+	}
+
+	tm, _ = tm.Update(keyMsg("v"))
+	if got := resultTitles(tm.(model).results); got != "A,B,C,D,E" {
+		t.Errorf("Expected the flat list back, got %s", got)
+	}
+}
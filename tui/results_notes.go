@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// noteCharLimit caps the length of a listing note
+const noteCharLimit = 500
+
+// loadNotes reads which listings have notes, for the 📝 marker on their rows
+func (p *ResultsPane) loadNotes() {
+	if p.db == nil {
+		return
+	}
+	notes, err := p.db.GetListingNotes()
+	if err != nil {
+		logger.Error("failed to load listing notes", "error", err)
+		return
+	}
+	p.notes = notes
+}
+
+// editNote opens the note editor on the listing in the detail overlay
+func (p *ResultsPane) editNote() {
+	if p.detail == nil || p.detail.raw != "" {
+		return
+	}
+	if p.db == nil {
+		p.detail.notice = "Notes need the local database, which isn't available"
+		return
+	}
+	if p.detail.listing.URL == "" {
+		p.detail.notice = "This listing has no URL, so it can't have a note"
+		return
+	}
+
+	input := textinput.New()
+	input.Placeholder = "e.g. seller looks sketchy"
+	input.CharLimit = noteCharLimit
+	input.SetValue(p.detail.note)
+	input.Focus()
+	p.detail.noteInput = &input
+	p.detail.notice = ""
+}
+
+// updateNoteEditor handles input while the note editor is open. Enter saves
+// the note, Esc discards the edit.
+func (p *ResultsPane) updateNoteEditor(msg tea.Msg) tea.Cmd {
+	input := p.detail.noteInput
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Note.Save):
+			p.detail.noteInput = nil
+			return p.saveNote(p.detail.listing, input.Value())
+		case key.Matches(msg, keys.Note.Cancel):
+			p.detail.noteInput = nil
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	*input, cmd = input.Update(msg)
+	return cmd
+}
+
+// saveNote stores note on listing in the background. Listings are cached
+// in the background after each search, so one that isn't cached yet is
+// cached first.
+func (p *ResultsPane) saveNote(listing APIListing, note string) tea.Cmd {
+	db := p.db
+	return func() tea.Msg {
+		err := db.SetListingNote(listing.URL, note)
+		if errors.Is(err, errListingNotCached) {
+			if err = db.CacheListing(fromAPIListing(listing)); err == nil {
+				err = db.SetListingNote(listing.URL, note)
+			}
+		}
+		return NoteSavedMsg{URL: listing.URL, Note: strings.TrimSpace(note), Error: err}
+	}
+}
+
+// ApplyNote shows a saved note on the listing's row and in the detail
+// overlay, if it is still open on that listing
+func (p *ResultsPane) ApplyNote(msg NoteSavedMsg) {
+	onDetail := p.detail != nil && p.detail.listing.URL == msg.URL
+	if msg.Error != nil {
+		p.lastError = "failed to save note: " + msg.Error.Error()
+		if onDetail {
+			p.detail.notice = "Failed to save the note: " + msg.Error.Error()
+		}
+		return
+	}
+
+	if p.notes == nil {
+		p.notes = make(map[string]string)
+	}
+	notice := "Note saved"
+	if msg.Note == "" {
+		delete(p.notes, msg.URL)
+		notice = "Note removed"
+	} else {
+		p.notes[msg.URL] = msg.Note
+	}
+	if onDetail {
+		p.detail.note = msg.Note
+		p.detail.notice = notice
+	}
+}
@@ -1,37 +1,120 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type ResultsPane struct {
-	results      []APIListing
-	selectedIdx  int
-	offset       int
-	pageSize     int
-	loading      bool
-	lastError    string
-	apiClient    *APIClient
+	all           []APIListing // everything loaded, in display order
+	results       []APIListing // the rows shown, after filtering
+	selectedIdx   int
+	offset        int
+	pageSize      int
+	loading       bool
+	spinner       spinner.Model
+	lastError     string
+	notice        string
+	apiClient     *APIClient
+	db            *Database
+	offline       bool // refresh from the local cache instead of the API
+	detail        *DetailView
+	showingDetail bool
+	source        string       // source filter applied on refresh, empty for all
+	orderBy       string       // API sort column applied on refresh, empty for default
+	threshold     float64      // minimum discount (%) below the median to flag a deal
+	searchFilter  SearchFilter // the search's price and condition filter, also applied to what the API returns
+	median        float64      // median price of the loaded results
+	comps         []APIComp
+	notes         map[string]string // notes on cached listings, keyed by URL
+	opportunities []Opportunity     // margins for results, index-aligned
+	sortKey       sortKey
+	sortDesc      bool
+	filter        resultsFilter
+	filterBar     *filterBar      // non-nil while the filter bar is open
+	addForm       *addListingForm // non-nil while a listing is being entered
+	query         string          // search behind the results, empty after a refresh
+	total         int             // matches on the server, zero if unknown
+	loadingMore   bool
+	rowsTop       int             // line of the first result row in the last View, for mouse clicks
+	absoluteAges  bool            // show listing dates in the Age column instead of "3h ago"
+	selected      map[int]bool    // IDs of the listings picked for export
+	confirm       *ConfirmDialog  // non-nil while opening many listings awaits confirmation
+	grouped       bool            // show the rows under a header per source
+	groups        []resultGroup   // groups of the filtered results, while grouped
+	collapsed     map[string]bool // sources whose rows are hidden while grouped
+	lineRows      []int           // result drawn on each row line of the last View, -1 for a group header
+	maxRows       int             // most rows shown at once, zero for as many as fit
 }
 
-func NewResultsPane() *ResultsPane {
+// refreshLimit is the number of listings fetched by a refresh
+const refreshLimit = 100
+
+func NewResultsPane(apiClient *APIClient) *ResultsPane {
 	return &ResultsPane{
 		results:   []APIListing{},
 		pageSize:  10,
-		apiClient: NewAPIClient(""),
+		spinner:   newSpinner(),
+		apiClient: apiClient,
+	}
+}
+
+// saveSession remembers the Age column format for the next run
+func (p *ResultsPane) saveSession() {
+	if p.db == nil {
+		return
+	}
+	_ = p.db.SaveSession(map[string]interface{}{"absolute_ages": p.absoluteAges})
+}
+
+// loadSession restores the Age column format of the last run, if any
+func (p *ResultsPane) loadSession() {
+	if p.db == nil {
+		return
+	}
+	session, err := p.db.LoadConfig(sessionConfigName)
+	if err != nil {
+		return
+	}
+	if absolute, ok := session["absolute_ages"].(bool); ok {
+		p.absoluteAges = absolute
 	}
 }
 
 func (p *ResultsPane) Update(msg tea.Msg) (ResultsPane, tea.Cmd) {
+	if p.showingDetail {
+		return p.updateDetail(msg)
+	}
+	if p.filterBar != nil {
+		return p.updateFilter(msg)
+	}
+	if p.addForm != nil {
+		return p.updateAddForm(msg)
+	}
+	if p.confirm != nil {
+		var cmd tea.Cmd
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			var done bool
+			if done, cmd = p.confirm.Update(msg); done {
+				p.confirm = nil
+			}
+		}
+		return *p, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up", "k":
+		switch {
+		case key.Matches(msg, keys.Results.Up):
 			if p.selectedIdx > 0 {
 				p.selectedIdx--
 				if p.selectedIdx < p.offset {
@@ -40,23 +123,166 @@ func (p *ResultsPane) Update(msg tea.Msg) (ResultsPane, tea.Cmd) {
 			}
 			return *p, nil
 
-		case "down", "j":
+		case key.Matches(msg, keys.Results.Down):
 			if p.selectedIdx < len(p.results)-1 {
 				p.selectedIdx++
 				if p.selectedIdx >= p.offset+p.pageSize {
 					p.offset = p.selectedIdx - p.pageSize + 1
 				}
+			} else if p.canLoadMore() {
+				// Scrolling past the end fetches the next page
+				return *p, p.loadMore()
 			}
 			return *p, nil
 
-		case "r":
+		case key.Matches(msg, keys.Results.Top):
+			p.selectRow(0)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Bottom):
+			p.selectRow(len(p.results) - 1)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.HalfPageUp):
+			p.selectRow(p.selectedIdx - p.pageSize/2)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.HalfPageDn):
+			p.selectRow(p.selectedIdx + p.pageSize/2)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.PageUp):
+			p.selectedIdx, p.offset = pageBy(p.selectedIdx, p.offset, len(p.results), p.pageSize, -p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.PageDown):
+			p.selectedIdx, p.offset = pageBy(p.selectedIdx, p.offset, len(p.results), p.pageSize, p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Refresh):
 			// Refresh results
 			p.loading = true
-			// TODO: Implement refresh
+			p.lastError = ""
+			return *p, tea.Batch(p.refresh(), p.spinner.Tick)
+
+		case key.Matches(msg, keys.Results.Details):
+			// View details
+			if len(p.results) > 0 && p.selectedIdx < len(p.results) {
+				p.detail = NewDetailView(p.results[p.selectedIdx])
+				p.detail.note = p.notes[p.detail.listing.URL]
+				p.showingDetail = true
+				return *p, p.fetchDetail()
+			}
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.SortPrice):
+			p.toggleSort(sortByPrice)
 			return *p, nil
 
-		case "enter":
-			// TODO: View details
+		case key.Matches(msg, keys.Results.SortTitle):
+			p.toggleSort(sortByTitle)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.SortAge):
+			p.toggleSort(sortByAge)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.AgeFormat):
+			p.absoluteAges = !p.absoluteAges
+			p.saveSession()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.SortSource):
+			p.toggleSort(sortBySource)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.SortMargin):
+			// Margin starts best-first
+			p.toggleSort(sortByMargin)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Open):
+			// Open the selected listing in the browser
+			p.openSelected()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.OpenAll):
+			// Open every selected listing, asking first if there are many
+			p.openAllSelected()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Copy):
+			// Copy the selected listing's URL
+			return *p, p.copySelected()
+
+		case key.Matches(msg, keys.Results.CopyJSON):
+			// Copy the selected listing as JSON
+			return *p, p.copySelectedJSON()
+
+		case key.Matches(msg, keys.Results.Markdown):
+			// Copy the selection, or every shown listing, as a Markdown table
+			return *p, p.copyMarkdown()
+
+		case key.Matches(msg, keys.Results.Watch):
+			// Track the selected listing's title on the watchlist
+			return *p, p.watchSelected()
+
+		case key.Matches(msg, keys.Results.Favorite):
+			// Star the selected listing, or unstar it
+			return *p, p.toggleFavorite()
+
+		case key.Matches(msg, keys.Results.Select):
+			p.toggleSelected()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.SelectAll):
+			p.toggleSelectAll()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.ExportCSV):
+			return *p, p.exportResults("csv")
+
+		case key.Matches(msg, keys.Results.ExportJSON):
+			return *p, p.exportResults("json")
+
+		case key.Matches(msg, keys.Results.Filter):
+			// Edit the price/condition filter
+			p.filterBar = newFilterBar(p.filter)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.ClearFilter):
+			// Clear the filter
+			p.setFilter(resultsFilter{})
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Source):
+			// Show one source at a time, then all again
+			p.cycleSource()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Group):
+			p.toggleGrouped()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Collapse):
+			p.collapseGroup()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.ExpandAll):
+			p.expandGroups()
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.NextGroup):
+			p.jumpGroup(1)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.PrevGroup):
+			p.jumpGroup(-1)
+			return *p, nil
+
+		case key.Matches(msg, keys.Results.Add):
+			// Enter a listing found by hand
+			p.addForm = newAddListingForm()
 			return *p, nil
 		}
 	}
@@ -64,96 +290,524 @@ func (p *ResultsPane) Update(msg tea.Msg) (ResultsPane, tea.Cmd) {
 	return *p, nil
 }
 
+// rowAt returns the index of the result drawn on line y of the last View
+func (p *ResultsPane) rowAt(y int) (int, bool) {
+	line := y - p.rowsTop
+	if p.loading || line < 0 || line >= len(p.lineRows) || p.lineRows[line] < 0 {
+		return 0, false
+	}
+	return p.lineRows[line], true
+}
+
+// selectRow highlights result idx, or the nearest row if it is out of range,
+// scrolling it into view
+func (p *ResultsPane) selectRow(idx int) {
+	p.selectedIdx, p.offset = scrollTo(idx, p.offset, len(p.results), p.pageSize)
+}
+
+// resultsFooterRows is the lines View draws below the rows: a blank line,
+// the pagination and median lines, another blank line and the key hints
+const resultsFooterRows = 5
+
+// footerRows is the lines below the result rows, including any notice or
+// error
+func (p *ResultsPane) footerRows() int {
+	rows := resultsFooterRows
+	if p.notice != "" {
+		rows += 2
+	}
+	if p.lastError != "" {
+		rows += 2
+	}
+	return rows
+}
+
+// fitPage sets the page size to the rows that fit, at least one and at most
+// maxRows, and moves the window so the selection stays on it without leaving
+// rows unused
+func (p *ResultsPane) fitPage(rows int) {
+	if p.maxRows > 0 && rows > p.maxRows {
+		rows = p.maxRows
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	p.pageSize = rows
+	if p.offset > len(p.results)-rows {
+		p.offset = max(len(p.results)-rows, 0)
+	}
+	p.selectedIdx, p.offset = scrollTo(p.selectedIdx, p.offset, len(p.results), p.pageSize)
+}
+
+// capturingInput reports whether the pane needs every key, e.g. while an
+// overlay, the filter bar, the add listing form or a confirmation is open
+func (p *ResultsPane) capturingInput() bool {
+	return p.showingDetail || p.filterBar != nil || p.addForm != nil || p.confirm != nil
+}
+
+// refresh fetches the latest listings off the UI goroutine. The pane itself is
+// only updated once the resulting SearchResultMsg comes back through Update.
+func (p *ResultsPane) refresh() tea.Cmd {
+	client, db := p.apiClient, p.db
+	source, orderBy := p.source, p.orderBy
+	offline := p.offline
+	return func() tea.Msg {
+		var listings []APIListing
+		var err error
+		if !offline {
+			if source == "" && orderBy == "" {
+				listings, err = client.GetRecentListings(refreshLimit)
+			} else {
+				listings, err = client.GetListings(refreshLimit, 0, source, orderBy)
+			}
+		}
+		if db != nil && (offline || isUnreachable(err)) {
+			listings, err = searchCache(db, ListingQuery{Source: source, OrderBy: orderBy, Limit: refreshLimit})
+			offline = true
+		}
+		return SearchResultMsg{
+			Results: listings,
+			Error:   err,
+			Refresh: true,
+			Offline: offline,
+		}
+	}
+}
+
+// canLoadMore reports whether the server has search results not yet loaded
+func (p *ResultsPane) canLoadMore() bool {
+	return p.query != "" && !p.loadingMore && p.total > len(p.all)
+}
+
+// loadMore fetches the next page of the current search
+func (p *ResultsPane) loadMore() tea.Cmd {
+	p.loadingMore = true
+	p.lastError = ""
+
+	client := p.apiClient
+	query, source, filter, offset := p.query, p.source, p.searchFilter, len(p.all)
+	return func() tea.Msg {
+		page, err := client.SearchListingsPage(query, source, filter, searchPageSize, offset)
+		return MoreResultsMsg{
+			Query:   query,
+			Results: page.Items,
+			Total:   page.Total,
+			Error:   err,
+		}
+	}
+}
+
+// fetchDetail loads the full record of the listing in the detail overlay.
+// Listings without an API ID, such as cached ones shown offline, keep the
+// list row.
+func (p *ResultsPane) fetchDetail() tea.Cmd {
+	id := p.detail.listing.ID
+	if id == 0 || p.offline || p.apiClient == nil {
+		return nil
+	}
+
+	p.detail.loading = true
+	client := p.apiClient
+	return func() tea.Msg {
+		listing, err := client.GetListingByID(id)
+		return ListingDetailMsg{ID: id, Listing: listing, Error: err}
+	}
+}
+
+// ApplyDetail shows a loaded full record in the detail overlay, if it is
+// still open on that listing. On error the list row stays up.
+func (p *ResultsPane) ApplyDetail(msg ListingDetailMsg) {
+	if p.detail == nil || p.detail.listing.ID != msg.ID {
+		return
+	}
+
+	p.detail.loading = false
+	if msg.Error != nil {
+		reason := describeError(msg.Error)
+		var apiErr *APIError
+		if errors.As(msg.Error, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			reason = "the API has no record of this listing"
+		}
+		p.detail.notice = fmt.Sprintf("Showing the list row; full details unavailable: %s", reason)
+		return
+	}
+	p.detail.listing = *msg.Listing
+}
+
+// updateDetail handles input while the detail overlay is open
+func (p *ResultsPane) updateDetail(msg tea.Msg) (ResultsPane, tea.Cmd) {
+	if p.detail != nil && p.detail.noteInput != nil {
+		return *p, p.updateNoteEditor(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.Detail.Note):
+			p.editNote()
+		case key.Matches(msg, keys.Detail.Close):
+			p.showingDetail = false
+			p.detail = nil
+		case key.Matches(msg, keys.Detail.Open):
+			p.openSelected()
+		}
+	}
+	return *p, nil
+}
+
+// watchSelected adds the highlighted listing to the watchlist, targeting its
+// current price
+func (p *ResultsPane) watchSelected() tea.Cmd {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return nil
+	}
+	if p.db == nil {
+		p.lastError = "no database available"
+		return nil
+	}
+
+	db := p.db
+	listing := p.results[p.selectedIdx]
+	return func() tea.Msg {
+		return WatchlistChangedMsg{
+			Title: listing.Title,
+			Error: db.AddToWatchlist(listing.Title, listing.Price),
+		}
+	}
+}
+
+// toggleFavorite adds the highlighted listing to the favorites, or removes
+// it if it is one already
+func (p *ResultsPane) toggleFavorite() tea.Cmd {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return nil
+	}
+	if p.db == nil {
+		p.lastError = "no database available"
+		return nil
+	}
+
+	listing := p.results[p.selectedIdx]
+	if listing.URL == "" {
+		p.notice = "This listing has no URL, so it can't be a favorite"
+		return nil
+	}
+
+	db := p.db
+	return func() tea.Msg {
+		added, err := db.ToggleFavorite(listing)
+		return FavoriteToggledMsg{Title: listing.Title, Added: added, Error: err}
+	}
+}
+
+// openSelected opens the highlighted listing's URL in the system browser
+func (p *ResultsPane) openSelected() {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return
+	}
+
+	p.lastError = ""
+	p.notice = ""
+
+	listing := p.results[p.selectedIdx]
+	if listing.URL == "" {
+		p.notice = "No URL available for this listing"
+		return
+	}
+
+	if err := browserOpener(listing.URL); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	p.notice = fmt.Sprintf("Opened %s", listing.URL)
+}
+
+// copySelected copies the highlighted listing's URL to the clipboard. When
+// the clipboard is unavailable the URL is shown instead so it can still be
+// copied by hand.
+func (p *ResultsPane) copySelected() tea.Cmd {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return nil
+	}
+
+	p.lastError = ""
+	p.notice = ""
+
+	listing := p.results[p.selectedIdx]
+	if listing.URL == "" {
+		p.notice = "No URL available for this listing"
+		return nil
+	}
+
+	if err := systemClipboard.WriteAll(listing.URL); err != nil {
+		p.notice = fmt.Sprintf("Clipboard unavailable - URL: %s", listing.URL)
+		return nil
+	}
+	p.notice = "Copied! " + listing.URL
+	return expireNotice(p.notice, copiedNoticeDuration)
+}
+
+// listingJSON renders a listing as indented JSON. Titles are pasted into
+// chats and scripts, so characters like < and & are left unescaped.
+func listingJSON(listing APIListing) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(listing); err != nil {
+		return "", fmt.Errorf("failed to encode listing: %w", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// copySelectedJSON copies the highlighted listing to the clipboard as
+// indented JSON. When the clipboard is unavailable the JSON is shown in the
+// detail overlay instead.
+func (p *ResultsPane) copySelectedJSON() tea.Cmd {
+	if len(p.results) == 0 || p.selectedIdx >= len(p.results) {
+		return nil
+	}
+
+	p.lastError = ""
+	p.notice = ""
+
+	listing := p.results[p.selectedIdx]
+	encoded, err := listingJSON(listing)
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+
+	if err := systemClipboard.WriteAll(encoded); err != nil {
+		p.detail = NewDetailView(listing)
+		p.detail.raw = encoded
+		p.detail.notice = "Clipboard unavailable - copy the JSON below by hand"
+		p.showingDetail = true
+		return nil
+	}
+	p.notice = fmt.Sprintf("Copied '%s' as JSON", listing.Title)
+	return expireNotice(p.notice, copiedNoticeDuration)
+}
+
 func (p *ResultsPane) View(width, height int) string {
+	if p.showingDetail && p.detail != nil {
+		return p.detail.View(width, height)
+	}
+	if p.addForm != nil {
+		return p.addForm.View(width, height)
+	}
+	if p.confirm != nil {
+		return p.confirm.View(width, height)
+	}
+
 	var b strings.Builder
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
+		Foreground(theme.Primary).
 		MarginBottom(1)
 
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(lipgloss.Color("#3a3a3a")).
+		Foreground(theme.Text).
+		Background(theme.HeaderBg).
 		Padding(0, 1)
 
 	itemStyle := lipgloss.NewStyle().
 		Padding(0, 1)
 
 	selectedItemStyle := itemStyle.Copy().
-		Background(lipgloss.Color("#7D56F4")).
+		Background(theme.SelectedBg).
 		Bold(true)
 
+	dealItemStyle := itemStyle.Copy().
+		Foreground(theme.Success)
+
 	infoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Muted).
 		Italic(true)
 
 	// Title
-	b.WriteString(titleStyle.Render(fmt.Sprintf("📊 Results (%d listings)", len(p.results))))
+	heading := fmt.Sprintf("📊 Results (%d listings)", len(p.all))
+	if len(p.selected) > 0 {
+		heading += fmt.Sprintf(" • %d selected", len(p.selected))
+	}
+	b.WriteString(titleStyle.Render(heading))
 	b.WriteString("\n\n")
 
+	// Filter
+	if p.filterBar != nil {
+		b.WriteString(p.filterBar.View())
+		b.WriteString("\n\n")
+	} else if p.filter.active() {
+		b.WriteString(infoStyle.Render("Filter: " + p.filter.String()))
+		b.WriteString("\n\n")
+	}
+
+	// Comp prices for the search, to judge the listings against
+	if comp := queryComp(p.query, p.comps); comp != nil && !p.loading {
+		compStyle := lipgloss.NewStyle().
+			Foreground(theme.Accent)
+		b.WriteString(compStyle.Render(fmt.Sprintf("💲 Comps for '%s': median $%.2f • avg $%.2f • %d sales",
+			comp.KeyTitle, comp.MedianPrice, comp.AvgPrice, comp.Count)))
+		b.WriteString("\n\n")
+	}
+
 	if p.loading {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
+			Foreground(theme.Success).
 			Bold(true)
-		b.WriteString(statusStyle.Render("🔄 Loading..."))
+		b.WriteString(statusStyle.Render(p.spinner.View() + " Loading..."))
 		b.WriteString("\n")
-	} else if len(p.results) == 0 {
+	} else if len(p.results) == 0 && len(p.groups) == 0 {
 		emptyStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
+			Foreground(theme.Subtle).
 			Italic(true)
-		b.WriteString(emptyStyle.Render("No results yet. Perform a search to see listings."))
+		if len(p.all) > 0 {
+			b.WriteString(emptyStyle.Render("No listings match the filter. Press x to clear it."))
+		} else {
+			b.WriteString(emptyStyle.Render("No results yet. Perform a search to see listings."))
+		}
 		b.WriteString("\n")
 	} else {
 		// Header
-		header := fmt.Sprintf("%-20s %-40s %10s %12s", "Source", "Title", "Price", "Age")
+		header := fmt.Sprintf("  %s %s %s %8s %s %s",
+			padRight(p.columnLabel("Source", sortBySource), 20),
+			padRight(p.columnLabel("Title", sortByTitle), 40),
+			padLeft(p.columnLabel("Price", sortByPrice), 10),
+			"Disc",
+			padLeft(p.columnLabel("Margin", sortByMargin), 16),
+			padLeft(p.columnLabel("Age", sortByAge), ageWidth),
+		)
 		b.WriteString(headerStyle.Render(header))
 		b.WriteString("\n")
 
-		// Display results (paginated)
+		// Display results (paginated), as many rows as fit above the footer,
+		// leaving room for a header per group
+		p.rowsTop = strings.Count(b.String(), "\n")
+		p.fitPage(height - p.rowsTop - p.footerRows() - len(p.groups))
 		end := p.offset + p.pageSize
 		if end > len(p.results) {
 			end = len(p.results)
 		}
 
-		for i := p.offset; i < end; i++ {
+		p.lineRows = p.lineRows[:0]
+		row := func(i int) {
 			result := p.results[i]
-			title := result.Title
-			if len(title) > 40 {
-				title = title[:37] + "..."
+			title := truncate(result.Title, 40)
+			if p.notes[result.URL] != "" {
+				title = truncate(result.Title, 37) + " 📝"
 			}
 
-			age := formatAge(result.Timestamp)
-			line := fmt.Sprintf("%-20s %-40s $%8.2f %12s",
+			age := formatListingAge(result.Timestamp, p.absoluteAges)
+			discount := discountPct(result.Price, p.dealReference(i))
+			disc := fmt.Sprintf("%.0f%%", discount)
+			if p.isDeal(i) {
+				disc = "★ " + disc
+			}
+			mark := " "
+			if p.selected[result.ID] {
+				mark = "✓"
+			}
+			line := fmt.Sprintf("%s %-20s %s %s %s %s %s",
+				mark,
 				result.Source,
-				title,
-				result.Price,
-				age,
+				padRight(title, 40),
+				padLeft(formatPrice(result.Price, result.Currency), 9),
+				padLeft(disc, 8),
+				padLeft(formatMargin(p.opportunities[i]), 16),
+				padLeft(age, ageWidth),
 			)
 
 			if i == p.selectedIdx {
 				b.WriteString(selectedItemStyle.Render("▸ " + line))
+			} else if p.isDeal(i) {
+				b.WriteString(dealItemStyle.Render("  " + line))
 			} else {
 				b.WriteString(itemStyle.Render("  " + line))
 			}
 			b.WriteString("\n")
+			p.lineRows = append(p.lineRows, i)
+		}
+
+		if p.grouped {
+			// A group's header shows above its rows on the page; a collapsed
+			// group's header shows where its rows would be
+			groupStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Accent)
+			idx := 0
+			for _, g := range p.groups {
+				collapsed := p.collapsed[g.Source]
+				first, last := max(idx, p.offset), idx+g.Count
+				if collapsed {
+					last = idx
+				}
+				last = min(last, end)
+				shown := first < last
+				if collapsed {
+					shown = idx >= p.offset && (idx < end || end == len(p.results))
+				}
+				if shown {
+					b.WriteString(groupStyle.Render(groupHeader(g, collapsed)))
+					b.WriteString("\n")
+					p.lineRows = append(p.lineRows, -1)
+				}
+				for i := first; i < last; i++ {
+					row(i)
+				}
+				if !collapsed {
+					idx += g.Count
+				}
+			}
+		} else {
+			for i := p.offset; i < end; i++ {
+				row(i)
+			}
 		}
 
 		// Pagination info
 		b.WriteString("\n")
-		pageInfo := fmt.Sprintf("Showing %d-%d of %d", p.offset+1, end, len(p.results))
+		total := len(p.results)
+		if p.total > total && !p.filter.active() {
+			total = p.total
+		}
+		pageInfo := fmt.Sprintf("Showing %d-%d of %d", p.offset+1, end, total)
+		if p.filter.active() {
+			pageInfo = fmt.Sprintf("Showing %d-%d • %d of %d shown", p.offset+1, end, len(p.results), len(p.all))
+		}
+		if p.grouped {
+			pageInfo += fmt.Sprintf(" • %d sources", len(p.groups))
+			if len(p.results) == 0 {
+				pageInfo = "Every source is collapsed • Z: Expand all"
+			}
+		}
+		if p.loadingMore {
+			pageInfo += " • Loading more..."
+		} else if p.canLoadMore() {
+			pageInfo += fmt.Sprintf(" • %d loaded, ↓ past the end for more", len(p.all))
+		}
 		b.WriteString(infoStyle.Render(pageInfo))
+		b.WriteString("\n")
+		dealInfo := fmt.Sprintf("Median $%.2f • ★ = at least %.0f%% below median", p.median, p.threshold)
+		if len(p.comps) > 0 {
+			dealInfo = fmt.Sprintf("★ = at least %.0f%% below the comp median", p.threshold)
+		}
+		b.WriteString(infoStyle.Render(dealInfo))
 	}
 
 	// Instructions
 	b.WriteString("\n\n")
-	b.WriteString(infoStyle.Render("↑/↓ or j/k: Navigate • Enter: View details • r: Refresh • Tab: Switch pane"))
+	b.WriteString(infoStyle.Render("↑/↓ or j/k: Navigate • Enter: View details • o/O: Open one/selected • c: Copy URL • J: Copy JSON • M: Markdown • w: Watch • *: Favorite • Enter then n: Note • space/A: Select • e/E: Export • p/t/a/s/m: Sort • /: Filter • f: Source • v: Group • x: Clear filter • n: Add listing • r: Refresh • Tab: Switch pane"))
+
+	// Notice
+	if p.notice != "" {
+		b.WriteString("\n\n")
+		b.WriteString(infoStyle.Render(p.notice))
+	}
 
 	// Error
 	if p.lastError != "" {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
+			Foreground(theme.Error).
 			Bold(true)
 		b.WriteString("\n\n")
 		b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Error: %s", p.lastError)))
@@ -162,15 +816,35 @@ func (p *ResultsPane) View(width, height int) string {
 	return b.String()
 }
 
+// ageWidth fits an absolute date in the Age column
+const ageWidth = len(absoluteAgeLayout)
+
+// absoluteAgeLayout is how the Age column shows dates when toggled absolute
+const absoluteAgeLayout = "2006-01-02 15:04"
+
+// formatListingAge renders a listing timestamp for the Age column, as a
+// relative age or, when absolute is set, a local date and time
+func formatListingAge(timestamp float64, absolute bool) string {
+	t, ok := parseEpoch(timestamp)
+	if !absolute || !ok {
+		return formatAge(timestamp)
+	}
+	return t.Format(absoluteAgeLayout)
+}
+
+// formatAge renders a listing timestamp as a relative age. Timestamps in the
+// future, from a server clock running ahead, count as just now.
 func formatAge(timestamp float64) string {
-	if timestamp == 0 {
+	t, ok := parseEpoch(timestamp)
+	if !ok {
 		return "unknown"
 	}
 
-	t := time.Unix(int64(timestamp), 0)
 	duration := time.Since(t)
 
-	if duration.Hours() < 1 {
+	if duration < time.Minute {
+		return "just now"
+	} else if duration.Hours() < 1 {
 		return fmt.Sprintf("%dm ago", int(duration.Minutes()))
 	} else if duration.Hours() < 24 {
 		return fmt.Sprintf("%dh ago", int(duration.Hours()))
@@ -179,9 +853,159 @@ func formatAge(timestamp float64) string {
 	}
 }
 
+// SetResults shows the listings of a new search from the first row
 func (p *ResultsPane) SetResults(results []APIListing) {
-	p.results = results
+	p.all = results
+	p.median = medianPrice(results)
+	p.rebuild()
+	p.pruneSelection()
+	p.loadNotes()
 	p.selectedIdx = 0
 	p.offset = 0
 	p.loading = false
 }
+
+// RefreshResults replaces the listings after a refresh without losing the
+// user's place: the selected listing stays selected where it is still
+// loaded, otherwise the selection stays on the same row, and the table only
+// scrolls as far as it must to keep it in view
+func (p *ResultsPane) RefreshResults(results []APIListing) {
+	idx := p.selectedIdx
+	var selected *APIListing
+	if idx < len(p.results) {
+		l := p.results[idx]
+		selected = &l
+	}
+
+	p.all = results
+	p.median = medianPrice(results)
+	p.rebuild()
+	p.pruneSelection()
+	p.loadNotes()
+	p.loading = false
+
+	if selected != nil {
+		if i, ok := p.rowOf(*selected); ok {
+			idx = i
+		}
+	}
+	// Fewer rows than before shouldn't leave the page part empty
+	if last := len(p.results) - p.pageSize; p.offset > last {
+		p.offset = max(last, 0)
+	}
+	p.selectRow(idx)
+}
+
+// rowOf returns the displayed row of listing l
+func (p *ResultsPane) rowOf(l APIListing) (int, bool) {
+	for i, r := range p.results {
+		if r.ID == l.ID && r.URL == l.URL && r.Title == l.Title {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// AppendResults adds the next page of a search, keeping the selection and
+// moving it onto the first new row where possible
+func (p *ResultsPane) AppendResults(results []APIListing, total int) {
+	p.all = append(p.all, results...)
+	p.total = total
+	if len(results) == 0 {
+		// The server has nothing more, whatever its total says
+		p.total = len(p.all)
+	}
+	p.median = medianPrice(p.all)
+	p.rebuild()
+	p.loadNotes()
+
+	if p.selectedIdx < len(p.results)-1 {
+		p.selectedIdx++
+		if p.selectedIdx >= p.offset+p.pageSize {
+			p.offset = p.selectedIdx - p.pageSize + 1
+		}
+	}
+}
+
+// SetComps sets the comparable prices used to compute margins
+func (p *ResultsPane) SetComps(comps []APIComp) {
+	p.comps = comps
+	p.rebuild()
+}
+
+// setFilter applies a new filter and jumps back to the first row
+func (p *ResultsPane) setFilter(f resultsFilter) {
+	p.filter = f
+	p.rebuild()
+	p.selectedIdx = 0
+	p.offset = 0
+}
+
+// rebuild recomputes margins and sort order for everything loaded, then
+// derives the displayed rows by applying the search filter, in case the API
+// ignored it, and the results filter
+func (p *ResultsPane) rebuild() {
+	opportunities := ComputeMargins(p.all, p.comps)
+	p.sortOpportunities(opportunities)
+
+	p.results = make([]APIListing, 0, len(opportunities))
+	p.opportunities = make([]Opportunity, 0, len(opportunities))
+	for i, o := range opportunities {
+		p.all[i] = o.Listing
+		if p.searchFilter.matches(o.Listing) && p.filter.matches(o.Listing) {
+			p.results = append(p.results, o.Listing)
+			p.opportunities = append(p.opportunities, o)
+		}
+	}
+	p.groupResults()
+}
+
+// formatMargin renders a margin as "$profit (pct%)", or a dash without a comp
+func formatMargin(o Opportunity) string {
+	if !o.HasMatch() {
+		return "—"
+	}
+	return fmt.Sprintf("$%.2f (%.0f%%)", o.Margin, o.MarginPct)
+}
+
+// dealReference returns the price result i is judged against: the median of
+// its matched comp, else of the search's best comp, and only without comps
+// the median of the loaded results
+func (p *ResultsPane) dealReference(i int) float64 {
+	if i < len(p.opportunities) {
+		if comp := p.opportunities[i].Comp; comp != nil && comp.MedianPrice > 0 {
+			return comp.MedianPrice
+		}
+	}
+	if comp := queryComp(p.query, p.comps); comp != nil && comp.MedianPrice > 0 {
+		return comp.MedianPrice
+	}
+	return p.median
+}
+
+// isDeal reports whether result i is discounted past the threshold
+func (p *ResultsPane) isDeal(i int) bool {
+	reference := p.dealReference(i)
+	return reference > 0 && discountPct(p.results[i].Price, reference) >= p.threshold
+}
+
+// medianPrice returns the median price of the listings, or 0 if there are none
+func medianPrice(listings []APIListing) float64 {
+	if len(listings) == 0 {
+		return 0
+	}
+
+	prices := make([]float64, len(listings))
+	for i, l := range listings {
+		prices[i] = l.Price
+	}
+	return priceStats(prices).Median
+}
+
+// discountPct returns how far price sits below reference, as a percentage
+func discountPct(price, reference float64) float64 {
+	if reference <= 0 {
+		return 0
+	}
+	return (reference - price) / reference * 100
+}
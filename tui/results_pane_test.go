@@ -0,0 +1,964 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestResultsRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings" {
+			t.Errorf("Expected path '/api/listings', got '%s'", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(APIResponse{
+			Items: []APIListing{
+				{ID: 1, Source: "shopgoodwill", Title: "RTX 3060", Price: 299.99},
+				{ID: 2, Source: "govdeals", Title: "RTX 3070", Price: 399.99},
+			},
+			Total: 2,
+		})
+	}))
+	defer server.Close()
+
+	var m tea.Model = newTestModel(server.URL)
+	m, cmd := m.Update(keyMsg("r"))
+	if cmd == nil {
+		t.Fatal("Expected refresh command, got nil")
+	}
+	if !m.(model).results.loading {
+		t.Error("Expected results pane to be loading")
+	}
+
+	// Run the command off the main goroutine while the view is rendered,
+	// as the Bubble Tea runtime would. Run with -race to catch shared writes.
+	msgs := make(chan tea.Msg)
+	go func() {
+		msgs <- runCmd(cmd)
+	}()
+	_ = m.View()
+	msg := <-msgs
+
+	result, ok := msg.(SearchResultMsg)
+	if !ok {
+		t.Fatalf("Expected SearchResultMsg, got %T", msg)
+	}
+	if result.Error != nil {
+		t.Fatalf("Refresh failed: %v", result.Error)
+	}
+
+	m, _ = m.Update(result)
+	results := m.(model).results
+	if results.loading {
+		t.Error("Expected loading to be cleared after refresh")
+	}
+	if len(results.results) != 2 {
+		t.Errorf("Expected 2 results, got %d", len(results.results))
+	}
+}
+
+func TestResultsRefreshError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var m tea.Model = newTestModel(server.URL)
+	m, cmd := m.Update(keyMsg("r"))
+	m, _ = m.Update(runCmd(cmd))
+
+	results := m.(model).results
+	if results.loading {
+		t.Error("Expected loading to be cleared after failed refresh")
+	}
+	if results.lastError == "" {
+		t.Error("Expected an error to be recorded")
+	}
+}
+
+func TestResultsRefreshUsesFilters(t *testing.T) {
+	var gotSource, gotOrder string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSource = r.URL.Query().Get("source")
+		gotOrder = r.URL.Query().Get("order_by")
+		json.NewEncoder(w).Encode(APIResponse{})
+	}))
+	defer server.Close()
+
+	pane := NewResultsPane(NewAPIClient(server.URL))
+	pane.source = "govdeals"
+	pane.orderBy = "price"
+
+	_, cmd := pane.Update(keyMsg("r"))
+	if msg := runCmd(cmd).(SearchResultMsg); msg.Error != nil {
+		t.Fatalf("Refresh failed: %v", msg.Error)
+	}
+
+	if gotSource != "govdeals" {
+		t.Errorf("Expected source 'govdeals', got '%s'", gotSource)
+	}
+	if gotOrder != "price" {
+		t.Errorf("Expected order_by 'price', got '%s'", gotOrder)
+	}
+}
+
+func TestResultsOpenInBrowser(t *testing.T) {
+	var opened []string
+	browserOpener = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	defer func() { browserOpener = openURL }()
+
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults([]APIListing{
+		{Title: "RTX 3060", URL: "https://example.com/1"},
+		{Title: "RTX 3070", URL: "https://example.com/2"},
+		{Title: "No link"},
+	})
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyDown})
+	pane.Update(keyMsg("o"))
+
+	if len(opened) != 1 || opened[0] != "https://example.com/2" {
+		t.Fatalf("Expected to open 'https://example.com/2', got %v", opened)
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyDown})
+	pane.Update(keyMsg("o"))
+
+	if len(opened) != 1 {
+		t.Errorf("Expected no browser call for empty URL, got %v", opened)
+	}
+	if pane.notice != "No URL available for this listing" {
+		t.Errorf("Expected no-URL notice, got '%s'", pane.notice)
+	}
+}
+
+func TestResultsOpenInBrowserError(t *testing.T) {
+	browserOpener = func(url string) error {
+		return errors.New("no browser")
+	}
+	defer func() { browserOpener = openURL }()
+
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults([]APIListing{{Title: "RTX 3060", URL: "https://example.com/1"}})
+	pane.Update(keyMsg("o"))
+
+	if pane.lastError != "no browser" {
+		t.Errorf("Expected error 'no browser', got '%s'", pane.lastError)
+	}
+}
+
+func TestResultsOpenAllSelected(t *testing.T) {
+	var opened []string
+	browserOpener = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+	defer func() { browserOpener = openURL }()
+
+	listings := make([]APIListing, 12)
+	for i := range listings {
+		listings[i] = APIListing{ID: i + 1, Title: fmt.Sprintf("Item %02d", i+1), URL: fmt.Sprintf("https://example.com/%d", i+1)}
+	}
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults(listings)
+
+	pane.Update(keyMsg("O"))
+	if len(opened) != 0 || pane.notice == "" {
+		t.Errorf("Expected a hint and nothing opened without a selection, got %v", opened)
+	}
+
+	// A few selected listings open straight away, once each
+	pane.Update(keyMsg(" "))
+	pane.Update(keyMsg("down"))
+	pane.Update(keyMsg("down"))
+	pane.Update(keyMsg(" "))
+	pane.Update(keyMsg("O"))
+	if strings.Join(opened, ",") != "https://example.com/1,https://example.com/3" {
+		t.Errorf("Expected each selected URL opened once, got %v", opened)
+	}
+	if pane.notice != "Opened 2 of 2 listings" {
+		t.Errorf("Expected the opened count, got '%s'", pane.notice)
+	}
+
+	// More than openAllConfirmAbove asks first; n opens nothing
+	opened = nil
+	pane.Update(keyMsg("A"))
+	pane.Update(keyMsg("O"))
+	if pane.confirm == nil || len(opened) != 0 {
+		t.Fatalf("Expected a confirmation before opening 12 listings, opened %v", opened)
+	}
+	pane.Update(keyMsg("n"))
+	if pane.confirm != nil || len(opened) != 0 {
+		t.Fatalf("Expected cancelling to open nothing, opened %v", opened)
+	}
+
+	pane.Update(keyMsg("O"))
+	pane.Update(keyMsg("y"))
+	if len(opened) != 12 {
+		t.Errorf("Expected 12 listings opened once confirmed, got %d", len(opened))
+	}
+	if pane.notice != "Opened 12 of 12 listings" {
+		t.Errorf("Expected the opened count, got '%s'", pane.notice)
+	}
+}
+
+func TestResultsOpenAllReportsFailures(t *testing.T) {
+	browserOpener = func(url string) error {
+		if strings.HasSuffix(url, "/2") {
+			return errors.New("no browser")
+		}
+		return nil
+	}
+	defer func() { browserOpener = openURL }()
+
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults([]APIListing{
+		{ID: 1, Title: "A", URL: "https://example.com/1"},
+		{ID: 2, Title: "B", URL: "https://example.com/2"},
+		{ID: 3, Title: "C"},
+	})
+	pane.Update(keyMsg("A"))
+	pane.Update(keyMsg("O"))
+
+	if pane.notice != "Opened 1 of 3 listings • 1 without a URL" {
+		t.Errorf("Expected the opened count, got '%s'", pane.notice)
+	}
+	if pane.lastError != "1 failed to open: no browser" {
+		t.Errorf("Expected the failure, got '%s'", pane.lastError)
+	}
+}
+
+func TestResultsDetailOverlay(t *testing.T) {
+	m := newTestModel("")
+	m.results.SetResults([]APIListing{{
+		Source:   "shopgoodwill",
+		Title:    "NVIDIA GeForce RTX 3060 Ti Founders Edition 8GB GDDR6 Graphics Card",
+		Price:    299.99,
+		Currency: "USD",
+		URL:      "https://example.com/1",
+		Metadata: map[string]interface{}{"seller": "test_seller"},
+	}})
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !tm.(model).results.showingDetail {
+		t.Fatal("Expected detail overlay to be open")
+	}
+
+	view := tm.View()
+	for _, want := range []string{"Founders Edition", "$299.99", "test_seller"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected detail view to contain '%s'", want)
+		}
+	}
+
+	// Tab and q belong to the overlay while it's open
+	tm, _ = tm.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if tm.(model).currentPane != 1 {
+		t.Errorf("Expected to stay on results pane, got pane %d", tm.(model).currentPane)
+	}
+
+	tm, cmd := tm.Update(keyMsg("q"))
+	if cmd != nil {
+		t.Error("Expected q to close the overlay, not quit")
+	}
+	if tm.(model).results.showingDetail {
+		t.Error("Expected detail overlay to be closed")
+	}
+}
+
+func TestResultsDetailFetchesFullListing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/listings/7" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(APIListing{
+			ID:       7,
+			Title:    "RTX 3060",
+			Price:    249.99,
+			Metadata: map[string]interface{}{"description": "Tested, works great"},
+		})
+	}))
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.results.SetResults([]APIListing{{ID: 7, Title: "RTX 3060", Price: 249.99}, {ID: 8, Title: "RTX 3070", Price: 349.99}})
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(keyMsg("enter"))
+	if cmd == nil {
+		t.Fatal("Expected a detail fetch, got nil")
+	}
+	if !strings.Contains(tm.View(), "Loading full details") {
+		t.Error("Expected a loading note while the listing is fetched")
+	}
+	tm, _ = tm.Update(cmd())
+	if view := tm.View(); !strings.Contains(view, "Tested, works great") {
+		t.Errorf("Expected the full listing in the overlay, got:\n%s", view)
+	}
+
+	// A listing the API no longer has keeps its list row
+	tm, _ = tm.Update(keyMsg("esc"))
+	tm, _ = tm.Update(keyMsg("down"))
+	tm, cmd = tm.Update(keyMsg("enter"))
+	tm, _ = tm.Update(cmd())
+	view := tm.View()
+	if !strings.Contains(view, "RTX 3070") || !strings.Contains(view, "no record of this listing") {
+		t.Errorf("Expected the list row with a not-found note, got:\n%s", view)
+	}
+
+	// A late answer for a listing that is no longer open is ignored
+	tm.(model).results.ApplyDetail(ListingDetailMsg{ID: 7, Listing: &APIListing{ID: 7, Title: "stale"}})
+	if strings.Contains(tm.View(), "stale") {
+		t.Error("Expected a late detail for another listing to be ignored")
+	}
+}
+
+func TestAddListingForm(t *testing.T) {
+	var posted []APIListing
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			json.NewEncoder(w).Encode([]APIListing{})
+			return
+		}
+		var l APIListing
+		json.NewDecoder(r.Body).Decode(&l)
+		if strings.Contains(l.Title, "duplicate") {
+			http.Error(w, "listing already exists", http.StatusConflict)
+			return
+		}
+		posted = append(posted, l)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(l)
+	}))
+	defer server.Close()
+
+	var tm tea.Model = newTestModel(server.URL)
+	tm, _ = tm.Update(keyMsg("n"))
+	form := tm.(model).results.addForm
+	if form == nil {
+		t.Fatal("Expected n to open the add listing form")
+	}
+
+	// Fields are checked before anything is sent
+	tm, cmd := tm.Update(keyMsg("enter"))
+	if cmd != nil || form.lastError != "title is required" {
+		t.Errorf("Expected a missing title to be caught, got '%s'", form.lastError)
+	}
+
+	for _, text := range []string{"RTX 3060 duplicate", "tab", "$120", "tab", "tab", "tab", "used"} {
+		tm, _ = tm.Update(keyMsg(text))
+	}
+	tm, cmd = tm.Update(keyMsg("enter"))
+	tm, _ = tm.Update(cmd())
+	if tm.(model).results.addForm == nil {
+		t.Fatal("Expected a rejected listing to keep the form open")
+	}
+	if view := tm.View(); !strings.Contains(view, "listing already exists") {
+		t.Errorf("Expected the API's reason in the form, got:\n%s", view)
+	}
+
+	form.inputs[addFieldTitle].SetValue("RTX 3060")
+	tm, cmd = tm.Update(keyMsg("enter"))
+	tm, cmd = tm.Update(cmd())
+	if tm.(model).results.addForm != nil {
+		t.Error("Expected the form to close once the listing is stored")
+	}
+	if len(posted) != 1 || posted[0].Source != manualProvider || posted[0].Price != 120 || posted[0].Condition != "used" {
+		t.Errorf("Expected one manual listing at $120, got %+v", posted)
+	}
+	if !tm.(model).results.loading || cmd == nil {
+		t.Error("Expected the results to be refreshed")
+	}
+}
+
+func TestResultsFlagDeals(t *testing.T) {
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.threshold = 25
+	pane.SetResults([]APIListing{
+		{Title: "A", Price: 100},
+		{Title: "B", Price: 200},
+		{Title: "C", Price: 140},
+		{Title: "D", Price: 250},
+	})
+
+	if pane.median != 170 {
+		t.Fatalf("Expected median 170, got %v", pane.median)
+	}
+
+	want := map[string]bool{"A": true, "B": false, "C": false, "D": false}
+	for i, l := range pane.results {
+		if got := pane.isDeal(i); got != want[l.Title] {
+			t.Errorf("isDeal(%s): expected %v, got %v", l.Title, want[l.Title], got)
+		}
+	}
+}
+
+func TestResultsDealsUseCompMedian(t *testing.T) {
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.threshold = 20
+	pane.query = "rtx 3060"
+
+	// Every listing is overpriced against the market, so none is a deal
+	// however they compare with each other
+	pane.SetComps([]APIComp{{KeyTitle: "rtx 3060", MedianPrice: 100}})
+	pane.SetResults([]APIListing{
+		{Title: "RTX 3060 A", Price: 150},
+		{Title: "RTX 3060 B", Price: 300},
+		{Title: "RTX 3060 C", Price: 310},
+	})
+	for i, l := range pane.results {
+		if pane.isDeal(i) {
+			t.Errorf("Expected %s at $%.2f not to be a deal against a $100 comp", l.Title, l.Price)
+		}
+	}
+
+	// A listing without its own comp is judged against the search's
+	pane.SetComps([]APIComp{{KeyTitle: "rtx 3060", MedianPrice: 400}})
+	for i, l := range pane.results {
+		if !pane.isDeal(i) {
+			t.Errorf("Expected %s at $%.2f to be a deal against a $400 comp", l.Title, l.Price)
+		}
+	}
+	pane.SetResults([]APIListing{{Title: "Graphics card", Price: 150}})
+	if !pane.isDeal(0) || pane.opportunities[0].HasMatch() {
+		t.Error("Expected an unmatched listing to be judged against the search's comp")
+	}
+}
+
+func TestResultsWatchSelected(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("")
+	m.results.db = db
+	m.watchlist.db = db
+	m.results.SetResults([]APIListing{{ID: 1, Source: "govdeals", Title: "RTX 3060", Price: 249.99}})
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(keyMsg("w"))
+	if cmd == nil {
+		t.Fatal("Expected watchlist command, got nil")
+	}
+
+	tm, _ = tm.Update(runCmd(cmd))
+	m = tm.(model)
+	if m.results.lastError != "" {
+		t.Fatalf("Failed to watch listing: %s", m.results.lastError)
+	}
+	if len(m.watchlist.items) != 1 {
+		t.Fatalf("Expected watchlist pane to show 1 item, got %d", len(m.watchlist.items))
+	}
+	if item := m.watchlist.items[0]; item.Title != "RTX 3060" || item.TargetPrice != 249.99 {
+		t.Errorf("Expected 'RTX 3060' targeting 249.99, got %+v", item)
+	}
+}
+
+func TestResultsToggleFavorite(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("")
+	m.results.db = db
+	m.favorites.db = db
+	m.results.SetResults([]APIListing{
+		{ID: 1, Source: "govdeals", Title: "RTX 3060", Price: 249.99, URL: "https://example.com/1"},
+		{ID: 2, Source: "ebay", Title: "No link", Price: 10},
+	})
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(keyMsg("*"))
+	if cmd == nil {
+		t.Fatal("Expected favorite command, got nil")
+	}
+	tm, _ = tm.Update(runCmd(cmd))
+	m = tm.(model)
+	if len(m.favorites.favorites) != 1 || m.favorites.favorites[0].Listing.Title != "RTX 3060" {
+		t.Fatalf("Expected the Favorites pane to show 'RTX 3060', got %+v", m.favorites.favorites)
+	}
+
+	// The pane shows the stored snapshot, with no API behind it
+	tm, _ = tm.Update(keyMsg("7"))
+	if view := tm.View(); !strings.Contains(view, "Favorites (1 listings)") || !strings.Contains(view, "RTX 3060") {
+		t.Errorf("Expected the favorite in the Favorites pane:\n%s", view)
+	}
+
+	// F again on the same listing removes it
+	tm, _ = tm.Update(keyMsg("2"))
+	tm, cmd = tm.Update(keyMsg("*"))
+	tm, _ = tm.Update(runCmd(cmd))
+	if n := len(tm.(model).favorites.favorites); n != 0 {
+		t.Errorf("Expected toggling to remove the favorite, got %d", n)
+	}
+
+	// A listing without a URL can't be a favorite
+	tm, _ = tm.Update(keyMsg("j"))
+	if _, cmd = tm.Update(keyMsg("*")); cmd != nil {
+		t.Error("Expected no command for a listing without a URL")
+	}
+}
+
+func TestResultsListingNote(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("")
+	m.results.db = db
+	// The listing isn't cached yet, as when a search's listings are still
+	// being cached in the background
+	m.results.SetResults([]APIListing{{Source: "ebay", Title: "RTX 3060", Price: 250, URL: "https://example.com/1"}})
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(keyMsg("enter"))
+	tm, _ = tm.Update(keyMsg("n"))
+	if tm.(model).results.detail.noteInput == nil {
+		t.Fatal("Expected n to open the note editor")
+	}
+	// Keys that close the overlay or act on the pane are typed into the note
+	for _, k := range []string{"seller ", "q", "uit ", "o", "k"} {
+		tm, _ = tm.Update(keyMsg(k))
+	}
+	tm, cmd := tm.Update(keyMsg("enter"))
+	if cmd == nil {
+		t.Fatal("Expected a command to save the note")
+	}
+	tm, _ = tm.Update(runCmd(cmd))
+
+	m = tm.(model)
+	if m.results.lastError != "" {
+		t.Fatalf("Failed to save note: %s", m.results.lastError)
+	}
+	if note, _ := db.GetListingNote("https://example.com/1"); note != "seller quit ok" {
+		t.Errorf("Expected the note to be stored, got %q", note)
+	}
+	if view := m.View(); !strings.Contains(view, "📝 seller quit ok") || !strings.Contains(view, "Note saved") {
+		t.Errorf("Expected the note in the detail overlay:\n%s", view)
+	}
+
+	// Rows with a note are marked, also after the results are reloaded
+	tm, _ = tm.Update(keyMsg("esc"))
+	m = tm.(model)
+	m.results.SetResults([]APIListing{{Source: "ebay", Title: "RTX 3060", Price: 240, URL: "https://example.com/1"}})
+	if view := m.View(); !strings.Contains(view, "RTX 3060 📝") {
+		t.Errorf("Expected a 📝 marker on the row:\n%s", view)
+	}
+
+	// Esc discards an edit and an empty note removes it
+	tm, _ = tm.Update(keyMsg("enter"))
+	tm, _ = tm.Update(keyMsg("n"))
+	tm, _ = tm.Update(keyMsg("ctrl+u"))
+	tm, _ = tm.Update(keyMsg("esc"))
+	if d := tm.(model).results.detail; d == nil || d.noteInput != nil || d.note != "seller quit ok" {
+		t.Fatalf("Expected esc to leave the note editor unchanged, got %+v", d)
+	}
+	tm, _ = tm.Update(keyMsg("n"))
+	tm, _ = tm.Update(keyMsg("ctrl+u"))
+	tm, cmd = tm.Update(keyMsg("enter"))
+	tm, _ = tm.Update(runCmd(cmd))
+	if notes, _ := db.GetListingNotes(); len(notes) != 0 {
+		t.Errorf("Expected the note to be removed, got %v", notes)
+	}
+	if tm.(model).results.notes["https://example.com/1"] != "" {
+		t.Error("Expected the row marker to go with the note")
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		currency string
+		expected string
+	}{
+		{"", "$12.50"},
+		{"USD", "$12.50"},
+		{"eur", "€12.50"},
+		{"GBP", "£12.50"},
+		{"CHF", "12.50 CHF"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPrice(12.5, tt.currency); got != tt.expected {
+			t.Errorf("formatPrice(12.5, %q): expected '%s', got '%s'", tt.currency, tt.expected, got)
+		}
+	}
+
+	p := NewResultsPane(nil)
+	p.SetResults([]APIListing{{Source: "ebay", Title: "ThinkPad", Price: 249.5, Currency: "EUR"}})
+	if view := p.View(120, 40); !strings.Contains(view, "€249.50") {
+		t.Error("Expected results view to render the euro price")
+	}
+}
+
+func TestFormatListingAge(t *testing.T) {
+	ts := float64(time.Now().Add(-3 * time.Hour).Unix())
+	if got := formatListingAge(ts, false); got != "3h ago" {
+		t.Errorf("Expected relative age '3h ago', got '%s'", got)
+	}
+	want := time.Unix(int64(ts), 0).Format("2006-01-02 15:04")
+	if got := formatListingAge(ts, true); got != want {
+		t.Errorf("Expected absolute age '%s', got '%s'", want, got)
+	}
+	for _, absolute := range []bool{false, true} {
+		if got := formatListingAge(0, absolute); got != "unknown" {
+			t.Errorf("Expected 'unknown' for a zero timestamp (absolute=%v), got '%s'", absolute, got)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		timestamp float64
+		expected  string
+	}{
+		{"missing", 0, "unknown"},
+		{"seconds", float64(now.Add(-90 * time.Minute).Unix()), "1h ago"},
+		{"fractional seconds", float64(now.Add(-3*24*time.Hour).UnixMilli()) / 1000, "3d ago"},
+		{"milliseconds", float64(now.Add(-5 * time.Minute).UnixMilli()), "5m ago"},
+		{"recent", float64(now.Add(-10 * time.Second).Unix()), "just now"},
+		{"future", float64(now.Add(5 * time.Minute).Unix()), "just now"},
+		{"future milliseconds", float64(now.Add(5 * time.Minute).UnixMilli()), "just now"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.timestamp); got != tt.expected {
+			t.Errorf("%s: expected '%s', got '%s'", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestParseEpoch(t *testing.T) {
+	if _, ok := parseEpoch(0); ok {
+		t.Error("Expected 0 to mean no timestamp")
+	}
+
+	// Values near the epoch are data, not missing
+	if got, ok := parseEpoch(1); !ok || !got.Equal(time.Unix(1, 0)) {
+		t.Errorf("Expected one second past the epoch, got %v (%v)", got, ok)
+	}
+
+	want := time.Date(2024, 3, 9, 14, 30, 0, 500_000_000, time.UTC)
+	if got, _ := parseEpoch(float64(want.UnixMilli())); !got.Equal(want) {
+		t.Errorf("Expected milliseconds to parse as %v, got %v", want, got)
+	}
+	if got, _ := parseEpoch(float64(want.UnixMilli()) / 1000); !got.Equal(want) {
+		t.Errorf("Expected seconds to parse as %v, got %v", want, got)
+	}
+}
+
+func TestResultsAgeFormatToggle(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	ts := time.Date(2024, 3, 9, 14, 30, 0, 0, time.Local)
+	p := NewResultsPane(nil)
+	p.db = db
+	p.SetResults([]APIListing{{Source: "govdeals", Title: "RTX 3060", Price: 249.99, Timestamp: float64(ts.Unix())}})
+
+	*p, _ = p.Update(keyMsg("T"))
+	if !strings.Contains(p.View(120, 40), "2024-03-09 14:30") {
+		t.Error("Expected 'T' to show absolute dates in the Age column")
+	}
+
+	// The choice survives a restart without clobbering the search session
+	search := NewSearchPane()
+	search.db = db
+	search.saveSession()
+
+	restored := NewResultsPane(nil)
+	restored.db = db
+	restored.loadSession()
+	if !restored.absoluteAges {
+		t.Error("Expected the absolute age format to be restored")
+	}
+
+	*p, _ = p.Update(keyMsg("T"))
+	if strings.Contains(p.View(120, 40), "2024-03-09 14:30") {
+		t.Error("Expected a second 'T' to go back to relative ages")
+	}
+}
+
+func TestResultsLoadMore(t *testing.T) {
+	server := newPagedSearchServer(t, 137)
+	defer server.Close()
+
+	var m tea.Model = newTestModel(server.URL)
+	m, _ = m.Update(performSearch(context.Background(), SearchMsg{Query: "item"}, NewAPIClient(server.URL), nil, false)())
+
+	results := m.(model).results
+	if len(results.all) != searchPageSize {
+		t.Fatalf("Expected %d results after the first page, got %d", searchPageSize, len(results.all))
+	}
+	if view := results.View(120, 40); !strings.Contains(view, fmt.Sprintf("Showing 1-%d of 137", results.pageSize)) {
+		t.Errorf("Expected pagination against the server total, got:\n%s", view)
+	}
+
+	// Scrolling past the last loaded row fetches the next page until none is left
+	for _, want := range []int{100, 137} {
+		m.(model).results.selectedIdx = len(m.(model).results.results) - 1
+		var cmd tea.Cmd
+		m, cmd = m.Update(keyMsg("down"))
+		if cmd == nil {
+			t.Fatalf("Expected a load more command with %d loaded", len(m.(model).results.all))
+		}
+		m, _ = m.Update(runCmd(cmd))
+
+		results := m.(model).results
+		if len(results.all) != want {
+			t.Fatalf("Expected %d results, got %d", want, len(results.all))
+		}
+		if results.loadingMore {
+			t.Error("Expected loading more to be cleared")
+		}
+	}
+
+	m.(model).results.selectedIdx = len(m.(model).results.results) - 1
+	if _, cmd := m.Update(keyMsg("down")); cmd != nil {
+		t.Error("Expected no load more command once every result is loaded")
+	}
+}
+
+// recordingClipboard captures copied text, failing with err if set
+type recordingClipboard struct {
+	copied string
+	err    error
+}
+
+func (c *recordingClipboard) WriteAll(text string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.copied = text
+	return nil
+}
+
+func TestResultsCopySelected(t *testing.T) {
+	clip := &recordingClipboard{}
+	systemClipboard = clip
+	defer func() { systemClipboard = osClipboard{} }()
+
+	var m tea.Model = newTestModel("")
+	m.(model).results.SetResults([]APIListing{
+		{Title: "RTX 3060", URL: "https://example.com/1"},
+		{Title: "RTX 3070", URL: "https://example.com/2"},
+	})
+
+	m, _ = m.Update(keyMsg("j"))
+	m, cmd := m.Update(keyMsg("c"))
+	if clip.copied != "https://example.com/2" {
+		t.Errorf("Expected the selected URL to be copied, got '%s'", clip.copied)
+	}
+	if !strings.Contains(m.View(), "Copied!") {
+		t.Error("Expected a 'Copied!' notice")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a command to clear the notice")
+	}
+
+	m, _ = m.Update(noticeExpiredMsg{notice: m.(model).results.notice})
+	if m.(model).results.notice != "" {
+		t.Errorf("Expected the notice to expire, got '%s'", m.(model).results.notice)
+	}
+}
+
+func TestResultsCopyJSON(t *testing.T) {
+	clip := &recordingClipboard{}
+	systemClipboard = clip
+	defer func() { systemClipboard = osClipboard{} }()
+
+	listing := APIListing{
+		ID:        7,
+		Source:    "govdeals",
+		URL:       "https://example.com/7",
+		Title:     "RTX 3060 \"Founders\" <Edition>",
+		Price:     249.99,
+		Currency:  "EUR",
+		Condition: "used",
+		Timestamp: 1709994600.5,
+		Metadata:  map[string]interface{}{"seller": "test", "bids": 3.0},
+	}
+	var m tea.Model = newTestModel("")
+	m.(model).results.SetResults([]APIListing{listing})
+
+	m, cmd := m.Update(keyMsg("J"))
+	if cmd == nil {
+		t.Error("Expected a command to clear the notice")
+	}
+
+	want := `{
+  "id": 7,
+  "source": "govdeals",
+  "url": "https://example.com/7",
+  "title": "RTX 3060 \"Founders\" <Edition>",
+  "price": 249.99,
+  "currency": "EUR",
+  "condition": "used",
+  "ts": 1709994600.5,
+  "meta_json": {
+    "bids": 3,
+    "seller": "test"
+  }
+}`
+	if clip.copied != want {
+		t.Errorf("Expected listing JSON\n%s\ngot\n%s", want, clip.copied)
+	}
+
+	var decoded APIListing
+	if err := json.Unmarshal([]byte(clip.copied), &decoded); err != nil {
+		t.Fatalf("Failed to decode copied JSON: %v", err)
+	}
+	if decoded.Title != listing.Title || decoded.Timestamp != listing.Timestamp || decoded.Metadata["seller"] != "test" {
+		t.Errorf("Expected the copied JSON to round-trip, got %+v", decoded)
+	}
+}
+
+func TestResultsCopyJSONFallsBackToOverlay(t *testing.T) {
+	systemClipboard = &recordingClipboard{err: errors.New("no clipboard utilities available")}
+	defer func() { systemClipboard = osClipboard{} }()
+
+	var m tea.Model = newTestModel("")
+	m.(model).results.SetResults([]APIListing{{ID: 1, Title: "RTX 3060", URL: "https://example.com/1"}})
+
+	m, _ = m.Update(keyMsg("J"))
+	if !m.(model).results.showingDetail {
+		t.Fatal("Expected the JSON to open in the detail overlay")
+	}
+	view := m.View()
+	if !strings.Contains(view, `"url": "https://example.com/1"`) || !strings.Contains(view, "Clipboard unavailable") {
+		t.Errorf("Expected the overlay to show the listing JSON:\n%s", view)
+	}
+}
+
+func TestResultsCopyFallsBackWithoutClipboard(t *testing.T) {
+	systemClipboard = &recordingClipboard{err: errors.New("no clipboard utilities available")}
+	defer func() { systemClipboard = osClipboard{} }()
+
+	var m tea.Model = newTestModel("")
+	m.(model).results.SetResults([]APIListing{{Title: "RTX 3060", URL: "https://example.com/1"}})
+
+	m, cmd := m.Update(keyMsg("c"))
+	if cmd != nil {
+		t.Error("Expected the fallback notice to stay up")
+	}
+	if notice := m.(model).results.notice; !strings.Contains(notice, "https://example.com/1") {
+		t.Errorf("Expected the URL in the notice, got '%s'", notice)
+	}
+}
+
+func TestResultsPageSizeFollowsHeight(t *testing.T) {
+	p := NewResultsPane(nil)
+	var listings []APIListing
+	for i := 0; i < 100; i++ {
+		listings = append(listings, APIListing{ID: i, Title: fmt.Sprintf("Listing %03d", i)})
+	}
+	p.SetResults(listings)
+
+	rows := func(view string) int {
+		return strings.Count(view, "Listing ")
+	}
+
+	short := rows(p.View(120, 20))
+	if short != p.pageSize {
+		t.Errorf("Expected %d rows drawn for a page of %d", short, p.pageSize)
+	}
+	if !strings.Contains(p.View(120, 20), fmt.Sprintf("Showing 1-%d of 100", short)) {
+		t.Errorf("Expected pagination text for %d rows", short)
+	}
+
+	tall := rows(p.View(120, 50))
+	if tall != short+30 || p.pageSize != tall {
+		t.Errorf("Expected 30 more rows on a 30-line taller pane, got %d then %d (page size %d)", short, tall, p.pageSize)
+	}
+	if view := p.View(120, 50); !strings.Contains(view, fmt.Sprintf("Showing 1-%d of 100", tall)) {
+		t.Errorf("Expected pagination text for %d rows, got:\n%s", tall, view)
+	}
+
+	// Paging moves by what was rendered
+	p.Update(keyMsg("pgdown"))
+	if p.offset != tall {
+		t.Errorf("Expected a page down to move the window by %d, got offset %d", tall, p.offset)
+	}
+
+	// A tiny pane still shows the selected row
+	if got := rows(p.View(120, 1)); got != 1 || p.pageSize != 1 {
+		t.Errorf("Expected a single row in a tiny pane, got %d (page size %d)", got, p.pageSize)
+	}
+	if !strings.Contains(p.View(120, 1), fmt.Sprintf("Listing %03d", p.selectedIdx)) {
+		t.Errorf("Expected the selected row %d to stay visible", p.selectedIdx)
+	}
+}
+
+func TestResultsPositionSurvivesTabsAndRefreshes(t *testing.T) {
+	listings := func(ids ...int) []APIListing {
+		l := make([]APIListing, len(ids))
+		for i, id := range ids {
+			l[i] = APIListing{ID: id, Title: fmt.Sprintf("Item %d", id), URL: fmt.Sprintf("https://example.com/%d", id), Price: float64(id)}
+		}
+		return l
+	}
+	ids := make([]int, 30)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+
+	m := newTestModel("")
+	tm, _ := m.Update(SearchResultMsg{Query: "item", Results: listings(ids...)})
+	m = tm.(model)
+	for i := 0; i < 15; i++ {
+		m.results.Update(keyMsg("down"))
+	}
+	selectedTitle := func() string { return m.results.results[m.results.selectedIdx].Title }
+
+	for _, k := range []string{"tab", "shift+tab"} {
+		tm, _ = m.Update(keyMsg(k))
+		m = tm.(model)
+	}
+	if m.currentPane != 1 || m.results.selectedIdx != 15 || m.results.offset != 6 {
+		t.Fatalf("Expected row 15 at offset 6 after tabbing back, got row %d at offset %d", m.results.selectedIdx, m.results.offset)
+	}
+
+	// A refresh with new listings on top follows the selected listing
+	tm, _ = m.Update(SearchResultMsg{Refresh: true, Results: listings(append([]int{31, 32}, ids...)...)})
+	m = tm.(model)
+	if got := selectedTitle(); got != "Item 16" || m.results.offset != 8 {
+		t.Errorf("Expected Item 16 to stay selected and in view, got '%s' at offset %d", got, m.results.offset)
+	}
+
+	// When the selected listing is gone the selection keeps its row
+	tm, _ = m.Update(SearchResultMsg{Refresh: true, Results: listings(ids[:15]...)})
+	m = tm.(model)
+	if m.results.selectedIdx != 14 || m.results.offset != 5 {
+		t.Errorf("Expected the last row to be selected, got row %d at offset %d", m.results.selectedIdx, m.results.offset)
+	}
+
+	// Only a new search starts from the top
+	tm, _ = m.Update(SearchResultMsg{Query: "item", Results: listings(ids...)})
+	m = tm.(model)
+	if m.results.selectedIdx != 0 || m.results.offset != 0 {
+		t.Errorf("Expected a new search to start at the top, got row %d at offset %d", m.results.selectedIdx, m.results.offset)
+	}
+}
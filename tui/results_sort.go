@@ -0,0 +1,120 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// sortKey identifies the results column the table is ordered by
+type sortKey int
+
+const (
+	sortNone sortKey = iota
+	sortByPrice
+	sortByTitle
+	sortByAge
+	sortBySource
+	sortByMargin
+)
+
+// toggleSort sorts by key, flipping the direction if it is already active.
+// Margin starts best-first; every other column starts ascending.
+func (p *ResultsPane) toggleSort(key sortKey) {
+	if p.sortKey == key {
+		p.sortDesc = !p.sortDesc
+	} else {
+		p.sortKey = key
+		p.sortDesc = key == sortByMargin
+	}
+	p.rebuild()
+	p.selectedIdx = 0
+	p.offset = 0
+}
+
+// sortOpportunities stably orders opportunities by the active sort key. Rows
+// with no value for the key (unknown age, no matching comp) always sort last.
+func (p *ResultsPane) sortOpportunities(opportunities []Opportunity) {
+	if p.sortKey == sortNone {
+		return
+	}
+
+	sort.SliceStable(opportunities, func(i, j int) bool {
+		a, b := opportunities[i], opportunities[j]
+		if missingA, missingB := p.missingSortValue(a), p.missingSortValue(b); missingA || missingB {
+			return !missingA && missingB
+		}
+		if p.sortDesc {
+			return p.sortLess(b, a)
+		}
+		return p.sortLess(a, b)
+	})
+}
+
+// sortLess reports whether a sorts before b in ascending order
+func (p *ResultsPane) sortLess(a, b Opportunity) bool {
+	switch p.sortKey {
+	case sortByPrice:
+		return a.Listing.Price < b.Listing.Price
+	case sortByTitle:
+		return strings.ToLower(a.Listing.Title) < strings.ToLower(b.Listing.Title)
+	case sortByAge:
+		// Youngest first: a newer timestamp means a smaller age
+		at, _ := a.Listing.Time()
+		bt, _ := b.Listing.Time()
+		return at.After(bt)
+	case sortBySource:
+		return a.Listing.Source < b.Listing.Source
+	case sortByMargin:
+		return a.Margin < b.Margin
+	}
+	return false
+}
+
+// missingSortValue reports whether o has no value for the active sort key
+func (p *ResultsPane) missingSortValue(o Opportunity) bool {
+	switch p.sortKey {
+	case sortByAge:
+		_, ok := o.Listing.Time()
+		return !ok
+	case sortByMargin:
+		return !o.HasMatch()
+	}
+	return false
+}
+
+// columnLabel decorates a column header with the sort direction when the
+// table is ordered by that column
+func (p *ResultsPane) columnLabel(label string, key sortKey) string {
+	if p.sortKey != key {
+		return label
+	}
+	if p.sortDesc {
+		return label + " ▼"
+	}
+	return label + " ▲"
+}
+
+// padLeft right-aligns s within width terminal cells
+func padLeft(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return strings.Repeat(" ", width-w) + s
+	}
+	return s
+}
+
+// truncate shortens s to at most width terminal cells, cutting on rune
+// boundaries and marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "…")
+}
+
+// padRight left-aligns s within width terminal cells
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+func sortTestPane() *ResultsPane {
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults([]APIListing{
+		{Title: "banana", Source: "govdeals", Price: 30, Timestamp: 2000},
+		{Title: "Apple", Source: "shopgoodwill", Price: 10, Timestamp: 0},
+		{Title: "cherry", Source: "govdeals", Price: 20, Timestamp: 3000},
+		{Title: "date", Source: "manual", Price: 20, Timestamp: 1000},
+	})
+	return pane
+}
+
+func resultTitles(pane *ResultsPane) string {
+	titles := make([]string, len(pane.results))
+	for i, r := range pane.results {
+		titles[i] = r.Title
+	}
+	return strings.Join(titles, ",")
+}
+
+func TestResultsSortKeys(t *testing.T) {
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		// Equal prices keep their original relative order
+		{[]string{"p"}, "Apple,cherry,date,banana"},
+		{[]string{"p", "p"}, "banana,cherry,date,Apple"},
+		{[]string{"t"}, "Apple,banana,cherry,date"},
+		{[]string{"t", "t"}, "date,cherry,banana,Apple"},
+		// Unknown ages sort last in both directions
+		{[]string{"a"}, "cherry,banana,date,Apple"},
+		{[]string{"a", "a"}, "date,banana,cherry,Apple"},
+		{[]string{"s"}, "banana,cherry,date,Apple"},
+		{[]string{"s", "s"}, "Apple,date,banana,cherry"},
+	}
+
+	for _, tt := range tests {
+		pane := sortTestPane()
+		for _, key := range tt.keys {
+			pane.Update(keyMsg(key))
+		}
+		if got := resultTitles(pane); got != tt.want {
+			t.Errorf("Keys %v: expected %s, got %s", tt.keys, tt.want, got)
+		}
+	}
+}
+
+func TestResultsSortResetsSelection(t *testing.T) {
+	pane := sortTestPane()
+	pane.selectedIdx = 3
+	pane.offset = 2
+
+	pane.Update(keyMsg("p"))
+	if pane.selectedIdx != 0 || pane.offset != 0 {
+		t.Errorf("Expected selection reset, got selectedIdx=%d offset=%d", pane.selectedIdx, pane.offset)
+	}
+}
+
+func TestResultsSortIndicator(t *testing.T) {
+	pane := sortTestPane()
+
+	pane.Update(keyMsg("p"))
+	if view := pane.View(150, 40); !strings.Contains(view, "Price ▲") {
+		t.Error("Expected ascending indicator on Price column")
+	}
+
+	pane.Update(keyMsg("p"))
+	if view := pane.View(150, 40); !strings.Contains(view, "Price ▼") {
+		t.Error("Expected descending indicator on Price column")
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	tests := []struct {
+		title string
+		cut   bool
+	}{
+		{"RTX 3060", false},
+		{"Café crème espresso machine, barely used, with milk frother", true},
+		{"任天堂スイッチ 有機ELモデル ホワイト 本体 新品 未開封 送料無料 即日発送", true},
+		{"🎮🎮 Gaming bundle 🎧 headset 🖱️ mouse ⌨️ keyboard 🖥️ monitor", true},
+	}
+
+	for _, tt := range tests {
+		got := truncate(tt.title, 40)
+		if !utf8.ValidString(got) {
+			t.Errorf("truncate(%q): expected valid UTF-8, got %q", tt.title, got)
+		}
+		if w := runewidth.StringWidth(got); w > 40 {
+			t.Errorf("truncate(%q): expected at most 40 cells, got %d", tt.title, w)
+		}
+		if tt.cut != strings.HasSuffix(got, "…") {
+			t.Errorf("truncate(%q): expected cut %v, got %q", tt.title, tt.cut, got)
+		}
+		if !tt.cut && got != tt.title {
+			t.Errorf("truncate(%q): expected title unchanged, got %q", tt.title, got)
+		}
+	}
+}
+
+func TestResultsViewAlignsWideTitles(t *testing.T) {
+	pane := NewResultsPane(NewAPIClient(""))
+	pane.SetResults([]APIListing{
+		{Title: "RTX 3060", Source: "govdeals", Price: 10},
+		{Title: "任天堂スイッチ 有機ELモデル ホワイト 本体 新品 未開封 送料無料", Source: "govdeals", Price: 20},
+	})
+
+	view := pane.View(120, 40)
+	if !utf8.ValidString(view) {
+		t.Fatal("Expected the view to be valid UTF-8")
+	}
+
+	// Both rows put their price column at the same display offset
+	var cols []int
+	for _, line := range strings.Split(view, "\n") {
+		if i := strings.Index(line, "$"); i >= 0 && strings.Contains(line, "govdeals") {
+			cols = append(cols, lipgloss.Width(line[:i]))
+		}
+	}
+	if len(cols) != 2 || cols[0] != cols[1] {
+		t.Errorf("Expected both prices in the same column, got %v", cols)
+	}
+}
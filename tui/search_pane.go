@@ -2,8 +2,12 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,11 +17,114 @@ type SearchPane struct {
 	queryInput     textinput.Model
 	providerSelect int
 	thresholdInput textinput.Model
+	minPriceInput  textinput.Model
+	maxPriceInput  textinput.Model
 	focusIndex     int
+	conditionIdx   int             // highlighted condition while the selector has focus
+	conditions     map[string]bool // selected searchConditions; none means any
 	providers      []string
 	searching      bool
+	spinner        spinner.Model
 	lastQuery      string
+	threshold      float64
 	lastError      string
+	suggestions    []SearchHistory // frequent queries offered when the query is empty
+	suggestionIdx  int             // highlighted suggestion, -1 when the input has focus
+	generation     int             // bumped on every query edit, so only the latest debounce fires
+	db             *Database
+}
+
+// defaultProviders are offered until the API's provider list has loaded, or
+// if it can't be
+var defaultProviders = []string{"shopgoodwill", "govdeals", "governmentsurplus", allProvider, manualProvider}
+
+// suggestionLimit is the number of past queries offered for autocomplete
+const suggestionLimit = 5
+
+// searchDebounce is how long the query must sit unchanged before it is
+// searched as you type
+const searchDebounce = 300 * time.Millisecond
+
+// minLiveQuery is the shortest query searched as you type; shorter ones wait
+// for Enter
+const minLiveQuery = 3
+
+// searchDebounceMsg is delivered once the query has sat unchanged for
+// searchDebounce. gen identifies the edit that scheduled it, so debounces
+// overtaken by further typing are dropped.
+type searchDebounceMsg struct {
+	gen int
+}
+
+// defaultThreshold is the discount threshold used when the field is empty
+const defaultThreshold = 20.0
+
+// parseThreshold parses the discount threshold field as a percentage
+func parseThreshold(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultThreshold, nil
+	}
+
+	threshold, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("threshold must be a number, got %q", raw)
+	}
+	if threshold < 0 || threshold > 100 {
+		return 0, fmt.Errorf("threshold must be between 0 and 100, got %g", threshold)
+	}
+
+	return threshold, nil
+}
+
+// parsePriceRange parses the optional min and max price fields into a
+// search filter; an empty field leaves that end of the range open
+func parsePriceRange(minRaw, maxRaw string) (SearchFilter, error) {
+	var f SearchFilter
+	var err error
+
+	if f.MinPrice, err = parsePriceBound(minRaw); err != nil {
+		return SearchFilter{}, fmt.Errorf("min price: %w", err)
+	}
+	if f.MaxPrice, err = parsePriceBound(maxRaw); err != nil {
+		return SearchFilter{}, fmt.Errorf("max price: %w", err)
+	}
+	if f.MinPrice > 0 && f.MaxPrice > 0 && f.MinPrice > f.MaxPrice {
+		return SearchFilter{}, fmt.Errorf("min price $%.2f is above max price $%.2f", f.MinPrice, f.MaxPrice)
+	}
+
+	return f, nil
+}
+
+// searchConditions are the listing conditions a search can be narrowed to
+var searchConditions = []string{"new", "used", "refurbished", "for-parts"}
+
+// conditionKeywords are what each of searchConditions looks for in a
+// listing's free-text condition, such as "Used - Good" or "For parts or not
+// working"
+var conditionKeywords = map[string]string{
+	"new":         "new",
+	"used":        "used",
+	"refurbished": "refurb",
+	"for-parts":   "parts",
+}
+
+// conditionMatches reports whether a listing's condition text is condition,
+// one of searchConditions
+func conditionMatches(text, condition string) bool {
+	keyword, ok := conditionKeywords[condition]
+	if !ok {
+		keyword = condition
+	}
+	return strings.Contains(strings.ToLower(text), keyword)
+}
+
+// formatPriceBound renders a price bound for its field, empty when open
+func formatPriceBound(price float64) string {
+	if price <= 0 {
+		return ""
+	}
+	return strconv.FormatFloat(price, 'f', -1, 64)
 }
 
 func NewSearchPane() *SearchPane {
@@ -29,76 +136,402 @@ func NewSearchPane() *SearchPane {
 	thresholdInput := textinput.New()
 	thresholdInput.Placeholder = "20.0"
 	thresholdInput.Width = 10
+	thresholdInput.Validate = validateDecimal
+
+	minPriceInput := textinput.New()
+	minPriceInput.Placeholder = "any"
+	minPriceInput.Width = 10
+	minPriceInput.Validate = validatePrice
+
+	maxPriceInput := textinput.New()
+	maxPriceInput.Placeholder = "any"
+	maxPriceInput.Width = 10
+	maxPriceInput.Validate = validatePrice
 
 	return &SearchPane{
 		queryInput:     queryInput,
 		thresholdInput: thresholdInput,
-		providers:      []string{"shopgoodwill", "govdeals", "governmentsurplus", "manual"},
+		minPriceInput:  minPriceInput,
+		maxPriceInput:  maxPriceInput,
+		providers:      defaultProviders,
 		providerSelect: 0,
 		focusIndex:     0,
+		suggestionIdx:  -1,
+		spinner:        newSpinner(),
+	}
+}
+
+// loadSuggestions refreshes the past queries offered for autocomplete, most
+// frequently searched first
+func (p *SearchPane) loadSuggestions() {
+	if p.db == nil {
+		return
+	}
+	if suggestions, err := p.db.GetFrequentQueries(suggestionLimit); err == nil {
+		p.suggestions = suggestions
+	}
+}
+
+// saveSession remembers the selected provider and threshold for the next run
+func (p *SearchPane) saveSession() {
+	if p.db == nil {
+		return
+	}
+	_ = p.db.SaveSession(map[string]interface{}{
+		"provider":  p.providers[p.providerSelect],
+		"threshold": strings.TrimSpace(p.thresholdInput.Value()),
+	})
+}
+
+// loadSession restores the provider and threshold of the last run, if any
+func (p *SearchPane) loadSession() {
+	if p.db == nil {
+		return
+	}
+	session, err := p.db.LoadConfig(sessionConfigName)
+	if err != nil {
+		return
+	}
+
+	if provider, ok := session["provider"].(string); ok {
+		for i, name := range p.providers {
+			if name == provider {
+				p.providerSelect = i
+			}
+		}
+	}
+	if threshold, ok := session["threshold"].(string); ok {
+		if _, err := parseThreshold(threshold); err == nil {
+			p.thresholdInput.SetValue(threshold)
+		}
 	}
 }
 
+// SetProviders offers the providers the API reported, plus searching them
+// all and the local manual provider, keeping the selected provider if it is
+// still offered
+func (p *SearchPane) SetProviders(sources []string) {
+	selected := p.providers[p.providerSelect]
+
+	providers := make([]string, 0, len(sources)+1)
+	seen := make(map[string]bool)
+	add := func(source string) {
+		source = strings.TrimSpace(source)
+		if source != "" && !seen[source] {
+			seen[source] = true
+			providers = append(providers, source)
+		}
+	}
+	for _, source := range sources {
+		add(source)
+	}
+	add(allProvider)
+	add(manualProvider)
+
+	p.providers = providers
+	p.providerSelect = 0
+	for i, name := range providers {
+		if name == selected {
+			p.providerSelect = i
+		}
+	}
+}
+
+// selectProvider selects the named provider, offering it if it isn't
+// already. It stays selected when the API's provider list arrives only if
+// the API offers it.
+func (p *SearchPane) selectProvider(name string) {
+	for i, provider := range p.providers {
+		if provider == name {
+			p.providerSelect = i
+			return
+		}
+	}
+	p.providers = append([]string{name}, p.providers...)
+	p.providerSelect = 0
+}
+
+// remoteProviders returns the providers searched through the API, leaving
+// out "all" itself and the local manual provider
+func (p *SearchPane) remoteProviders() []string {
+	var sources []string
+	for _, provider := range p.providers {
+		if provider != allProvider && provider != manualProvider {
+			sources = append(sources, provider)
+		}
+	}
+	return sources
+}
+
+// showingSuggestions reports whether the autocomplete dropdown is visible
+func (p *SearchPane) showingSuggestions() bool {
+	return p.focusIndex == 0 && p.queryInput.Value() == "" && len(p.suggestions) > 0
+}
+
 func (p *SearchPane) Update(msg tea.Msg) (SearchPane, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		typing := p.typing()
+
+		if p.showingSuggestions() {
+			switch {
+			case navMatches(msg, typing, keys.Search.Up):
+				if p.suggestionIdx >= 0 {
+					p.suggestionIdx--
+					return *p, nil
+				}
+			case navMatches(msg, typing, keys.Search.Down):
+				if p.suggestionIdx < len(p.suggestions)-1 {
+					p.suggestionIdx++
+					return *p, nil
+				}
+				p.suggestionIdx = -1
+			case key.Matches(msg, keys.Search.Submit):
+				if p.suggestionIdx >= 0 {
+					p.queryInput.SetValue(p.suggestions[p.suggestionIdx].Query)
+					p.queryInput.CursorEnd()
+					p.suggestionIdx = -1
+					return *p, nil
+				}
+			}
+		}
+
+		switch {
+		case key.Matches(msg, keys.Search.Submit):
 			if p.focusIndex == 0 && p.queryInput.Value() != "" {
-				p.lastQuery = p.queryInput.Value()
-				p.searching = true
-				// TODO: Trigger search
-				return *p, nil
+				// Enter searches right away, so a pending debounce is dropped
+				p.generation++
+				return *p, p.startSearch(false)
 			}
 			return *p, nil
 
-		case "up":
+		case key.Matches(msg, keys.Search.Save):
+			return *p, p.saveSearch()
+
+		case key.Matches(msg, keys.Search.Toggle) && p.focusIndex == 5:
+			p.toggleCondition(searchConditions[p.conditionIdx])
+			return *p, nil
+
+		case navMatches(msg, typing, keys.Search.Up):
 			if p.focusIndex > 0 {
 				p.focusIndex--
 				p.updateFocus()
 			}
 			return *p, nil
 
-		case "down":
-			if p.focusIndex < 2 {
+		case navMatches(msg, typing, keys.Search.Down):
+			if p.focusIndex < 5 {
 				p.focusIndex++
 				p.updateFocus()
 			}
 			return *p, nil
 
-		case "left":
+		case navMatches(msg, typing, keys.Search.Left):
 			if p.focusIndex == 1 && p.providerSelect > 0 {
 				p.providerSelect--
+				p.saveSession()
+			} else if p.focusIndex == 5 && p.conditionIdx > 0 {
+				p.conditionIdx--
 			}
 			return *p, nil
 
-		case "right":
+		case navMatches(msg, typing, keys.Search.Right):
 			if p.focusIndex == 1 && p.providerSelect < len(p.providers)-1 {
 				p.providerSelect++
+				p.saveSession()
+			} else if p.focusIndex == 5 && p.conditionIdx < len(searchConditions)-1 {
+				p.conditionIdx++
 			}
 			return *p, nil
 		}
+
+	case searchDebounceMsg:
+		query := strings.TrimSpace(p.queryInput.Value())
+		if msg.gen != p.generation || query == p.lastQuery {
+			return *p, nil
+		}
+		return *p, p.startSearch(true)
 	}
 
 	if p.focusIndex == 0 {
+		before := p.queryInput.Value()
 		p.queryInput, cmd = p.queryInput.Update(msg)
+		if p.queryInput.Value() != before {
+			return *p, tea.Batch(cmd, p.debounce())
+		}
 	} else if p.focusIndex == 2 {
-		p.thresholdInput, cmd = p.thresholdInput.Update(msg)
+		p.thresholdInput, cmd = updateNumericInput(p.thresholdInput, msg)
+	} else if p.focusIndex == 3 {
+		p.minPriceInput, cmd = updateNumericInput(p.minPriceInput, msg)
+	} else if p.focusIndex == 4 {
+		p.maxPriceInput, cmd = updateNumericInput(p.maxPriceInput, msg)
 	}
 
 	return *p, cmd
 }
 
+// startSearch validates the form and issues a search for the query. live
+// marks a search made as you type, whose results aren't saved.
+func (p *SearchPane) startSearch(live bool) tea.Cmd {
+	threshold, err := parseThreshold(p.thresholdInput.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	filter, err := p.searchFilter()
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	p.lastError = ""
+	p.threshold = threshold
+	p.lastQuery = strings.TrimSpace(p.queryInput.Value())
+	p.searching = true
+	p.saveSession()
+
+	search := SearchMsg{
+		Query:     p.lastQuery,
+		Provider:  p.providers[p.providerSelect],
+		Threshold: p.threshold,
+		Filter:    filter,
+		Live:      live,
+	}
+	if search.Provider == allProvider {
+		search.Sources = p.remoteProviders()
+	}
+	return tea.Batch(func() tea.Msg { return search }, p.spinner.Tick)
+}
+
+// savedSearchName is the configuration name a search is saved under
+func savedSearchName(query, provider string) string {
+	return fmt.Sprintf("search: %s [%s]", query, provider)
+}
+
+// saveSearch stores the query, provider, threshold and filter as a named
+// configuration; loading it from the Config pane runs the search again
+func (p *SearchPane) saveSearch() tea.Cmd {
+	query := strings.TrimSpace(p.queryInput.Value())
+	if query == "" {
+		p.lastError = "enter a query to save"
+		return nil
+	}
+	threshold, err := parseThreshold(p.thresholdInput.Value())
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	filter, err := p.searchFilter()
+	if err != nil {
+		p.lastError = err.Error()
+		return nil
+	}
+	if p.db == nil {
+		p.lastError = "no database available"
+		return nil
+	}
+	p.lastError = ""
+
+	db := p.db
+	provider := p.providers[p.providerSelect]
+	name := savedSearchName(query, provider)
+	config := map[string]interface{}{
+		"search_query": query,
+		"provider":     provider,
+		"threshold":    threshold,
+	}
+	if filter.MinPrice > 0 {
+		config["min_price"] = filter.MinPrice
+	}
+	if filter.MaxPrice > 0 {
+		config["max_price"] = filter.MaxPrice
+	}
+	if len(filter.Conditions) > 0 {
+		config["conditions"] = filter.Conditions
+	}
+	return func() tea.Msg {
+		return SearchSavedMsg{Name: name, Error: db.SaveConfig(name, config)}
+	}
+}
+
+// RunSavedSearch fills in the form from a saved search and searches it
+func (p *SearchPane) RunSavedSearch(msg RunSavedSearchMsg) tea.Cmd {
+	p.queryInput.SetValue(msg.Query)
+	p.queryInput.CursorEnd()
+	if msg.Provider != "" {
+		p.selectProvider(msg.Provider)
+	}
+	p.thresholdInput.SetValue(strconv.FormatFloat(msg.Threshold, 'f', -1, 64))
+	p.minPriceInput.SetValue(formatPriceBound(msg.Filter.MinPrice))
+	p.maxPriceInput.SetValue(formatPriceBound(msg.Filter.MaxPrice))
+	p.conditions = nil
+	for _, condition := range msg.Filter.Conditions {
+		p.toggleCondition(condition)
+	}
+	p.focusIndex = 0
+	p.updateFocus()
+	p.generation++
+	return p.startSearch(false)
+}
+
+// searchFilter builds the search filter from the price fields and the
+// selected conditions
+func (p *SearchPane) searchFilter() (SearchFilter, error) {
+	filter, err := parsePriceRange(p.minPriceInput.Value(), p.maxPriceInput.Value())
+	if err != nil {
+		return SearchFilter{}, err
+	}
+	for _, condition := range searchConditions {
+		if p.conditions[condition] {
+			filter.Conditions = append(filter.Conditions, condition)
+		}
+	}
+	return filter, nil
+}
+
+// toggleCondition selects or deselects one of searchConditions
+func (p *SearchPane) toggleCondition(condition string) {
+	if p.conditions == nil {
+		p.conditions = make(map[string]bool)
+	}
+	p.conditions[condition] = !p.conditions[condition]
+}
+
+// typing reports whether a text field has focus: the query, threshold and
+// price fields take letters and digits as text
+func (p *SearchPane) typing() bool {
+	return p.focusIndex != 1 && p.focusIndex != 5
+}
+
+// debounce schedules a search of the query once typing pauses. Every edit
+// bumps the generation, so only the debounce of the last keystroke fires.
+func (p *SearchPane) debounce() tea.Cmd {
+	p.generation++
+	if len([]rune(strings.TrimSpace(p.queryInput.Value()))) < minLiveQuery {
+		return nil
+	}
+	gen := p.generation
+	return tea.Tick(searchDebounce, func(time.Time) tea.Msg {
+		return searchDebounceMsg{gen: gen}
+	})
+}
+
 func (p *SearchPane) updateFocus() {
 	p.queryInput.Blur()
 	p.thresholdInput.Blur()
+	p.minPriceInput.Blur()
+	p.maxPriceInput.Blur()
 
-	if p.focusIndex == 0 {
+	switch p.focusIndex {
+	case 0:
 		p.queryInput.Focus()
-	} else if p.focusIndex == 2 {
+	case 2:
 		p.thresholdInput.Focus()
+	case 3:
+		p.minPriceInput.Focus()
+	case 4:
+		p.maxPriceInput.Focus()
 	}
 }
 
@@ -107,19 +540,19 @@ func (p *SearchPane) View(width, height int) string {
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
+		Foreground(theme.Primary).
 		MarginBottom(1)
 
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Text).
 		Bold(true)
 
 	infoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Muted).
 		Italic(true)
 
 	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF0000")).
+		Foreground(theme.Error).
 		Bold(true)
 
 	// Title
@@ -130,20 +563,39 @@ func (p *SearchPane) View(width, height int) string {
 	b.WriteString(labelStyle.Render("Search Query:"))
 	b.WriteString("\n")
 	b.WriteString(p.queryInput.View())
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if p.showingSuggestions() {
+		suggestionStyle := lipgloss.NewStyle().
+			Foreground(theme.Subtle).
+			PaddingLeft(2)
+		selectedSuggestionStyle := suggestionStyle.Copy().
+			Foreground(theme.Text).
+			Background(theme.SelectedBg)
+
+		for i, suggestion := range p.suggestions {
+			line := fmt.Sprintf("↺ %s (%d×)", suggestion.Query, suggestion.Count)
+			if i == p.suggestionIdx {
+				b.WriteString(selectedSuggestionStyle.Render(line))
+			} else {
+				b.WriteString(suggestionStyle.Render(line))
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
 
 	// Provider selection
 	b.WriteString(labelStyle.Render("Provider:"))
 	b.WriteString("\n")
-	
+
 	providerStyle := lipgloss.NewStyle().
 		Padding(0, 1).
 		Margin(0, 1, 0, 0)
 
 	selectedProviderStyle := providerStyle.Copy().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
-		Background(lipgloss.Color("#7D56F4"))
+		Foreground(theme.Text).
+		Background(theme.SelectedBg)
 
 	for i, provider := range p.providers {
 		if i == p.providerSelect && p.focusIndex == 1 {
@@ -162,19 +614,47 @@ func (p *SearchPane) View(width, height int) string {
 	b.WriteString(p.thresholdInput.View())
 	b.WriteString("\n\n")
 
+	// Price range inputs
+	b.WriteString(labelStyle.Render("Price Range ($):"))
+	b.WriteString("\n")
+	b.WriteString(p.minPriceInput.View() + "  to  " + p.maxPriceInput.View())
+	b.WriteString("\n\n")
+
+	// Condition selection
+	b.WriteString(labelStyle.Render("Condition:"))
+	b.WriteString("\n")
+	for i, condition := range searchConditions {
+		label := "  " + condition
+		if p.conditions[condition] {
+			label = "✓ " + condition
+		}
+		if i == p.conditionIdx && p.focusIndex == 5 {
+			b.WriteString(selectedProviderStyle.Render(label))
+		} else {
+			b.WriteString(providerStyle.Render(label))
+		}
+	}
+	b.WriteString("\n")
+	if filter, _ := p.searchFilter(); len(filter.Conditions) == 0 {
+		b.WriteString(infoStyle.Render("Any condition • ←/→ and space to choose"))
+	} else {
+		b.WriteString(infoStyle.Render("←/→ and space to choose"))
+	}
+	b.WriteString("\n\n")
+
 	// Instructions
-	b.WriteString(infoStyle.Render("↑/↓: Navigate fields • Enter: Search • Tab: Switch pane"))
+	b.WriteString(infoStyle.Render("↑/↓: Navigate fields • Enter: Search • Ctrl+S: Save search • Tab: Switch pane"))
 	b.WriteString("\n\n")
 
 	// Status
 	if p.searching {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
+			Foreground(theme.Success).
 			Bold(true)
-		b.WriteString(statusStyle.Render("🔄 Searching..."))
+		b.WriteString(statusStyle.Render(p.spinner.View() + " Searching..."))
 	} else if p.lastQuery != "" {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00"))
+			Foreground(theme.Success)
 		b.WriteString(statusStyle.Render(fmt.Sprintf("✓ Last search: %s", p.lastQuery)))
 	}
 
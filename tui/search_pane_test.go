@@ -0,0 +1,635 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"", defaultThreshold, false},
+		{"  ", defaultThreshold, false},
+		{"12.5", 12.5, false},
+		{"0", 0, false},
+		{"100", 100, false},
+		{"abc", 0, true},
+		{"150", 0, true},
+		{"-5", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseThreshold(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseThreshold(%q): expected error, got %v", tt.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseThreshold(%q): unexpected error: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseThreshold(%q): expected %v, got %v", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestInvalidThresholdBlocksSearch(t *testing.T) {
+	pane := NewSearchPane()
+	pane.queryInput.SetValue("rtx 3060")
+	pane.thresholdInput.SetValue("abc")
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if pane.searching {
+		t.Error("Expected search to be blocked")
+	}
+	if pane.lastError == "" {
+		t.Error("Expected a threshold error")
+	}
+
+	pane.thresholdInput.SetValue("12.5")
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !pane.searching {
+		t.Error("Expected search to start")
+	}
+	if pane.threshold != 12.5 {
+		t.Errorf("Expected threshold 12.5, got %v", pane.threshold)
+	}
+	if pane.lastError != "" {
+		t.Errorf("Expected error to be cleared, got '%s'", pane.lastError)
+	}
+}
+
+func TestNumericInputsRejectInvalidKeys(t *testing.T) {
+	pane := NewSearchPane()
+	type field struct {
+		focus int
+		input *textinput.Model
+	}
+	fields := []field{{2, &pane.thresholdInput}, {3, &pane.minPriceInput}, {4, &pane.maxPriceInput}}
+
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{[]string{"1", "2", ".", "5"}, "12.5"},
+		{[]string{".", "5"}, ".5"},
+		{[]string{"1", "a", "2"}, "12"},
+		{[]string{"1", ".", "2", ".", "3"}, "1.23"},
+		{[]string{"-", "5", "%"}, "5"},
+		// A paste is taken or rejected whole
+		{[]string{"1", "2 0"}, "1"},
+	}
+	for _, f := range fields {
+		pane.focusIndex = f.focus
+		f.input.Focus()
+		for _, tt := range tests {
+			f.input.SetValue("")
+			for _, k := range tt.keys {
+				pane.Update(keyMsg(k))
+			}
+			if got := f.input.Value(); got != tt.want {
+				t.Errorf("Field %d: typing %q, expected %q, got %q", f.focus, tt.keys, tt.want, got)
+			}
+			if f.input.Err != nil {
+				t.Errorf("Field %d: expected no error after typing %q, got %v", f.focus, tt.keys, f.input.Err)
+			}
+		}
+		f.input.Blur()
+	}
+
+	// Prices may start with a dollar sign, the threshold may not
+	pane.focusIndex = 3
+	pane.minPriceInput.Focus()
+	pane.minPriceInput.SetValue("")
+	for _, k := range []string{"$", "4", "0", "$"} {
+		pane.Update(keyMsg(k))
+	}
+	if got := pane.minPriceInput.Value(); got != "$40" {
+		t.Errorf("Expected a leading dollar sign in a price, got %q", got)
+	}
+	pane.focusIndex = 2
+	pane.thresholdInput.Focus()
+	pane.thresholdInput.SetValue("")
+	pane.Update(keyMsg("$"))
+	if got := pane.thresholdInput.Value(); got != "" {
+		t.Errorf("Expected the threshold to reject a dollar sign, got %q", got)
+	}
+
+	// Deleting and moving the cursor keeps the edit valid
+	pane.thresholdInput.SetValue("12.5")
+	pane.thresholdInput.SetCursor(2)
+	pane.Update(keyMsg("."))
+	pane.Update(keyMsg("3"))
+	if got := pane.thresholdInput.Value(); got != "123.5" {
+		t.Errorf("Expected a second decimal point to be rejected mid-value, got %q", got)
+	}
+}
+
+func TestParsePriceRange(t *testing.T) {
+	tests := []struct {
+		min, max string
+		want     SearchFilter
+		wantErr  bool
+	}{
+		{"", "", SearchFilter{}, false},
+		{"50", "", SearchFilter{MinPrice: 50}, false},
+		{"", "$199.99", SearchFilter{MaxPrice: 199.99}, false},
+		{"50", "200", SearchFilter{MinPrice: 50, MaxPrice: 200}, false},
+		{"75", "75", SearchFilter{MinPrice: 75, MaxPrice: 75}, false},
+		{"200", "50", SearchFilter{}, true},
+		{"abc", "", SearchFilter{}, true},
+		{"", "-5", SearchFilter{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePriceRange(tt.min, tt.max)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parsePriceRange(%q, %q): expected error, got %+v", tt.min, tt.max, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parsePriceRange(%q, %q): unexpected error: %v", tt.min, tt.max, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePriceRange(%q, %q): expected %+v, got %+v", tt.min, tt.max, tt.want, got)
+		}
+	}
+}
+
+func TestPriceRangeSearch(t *testing.T) {
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 0
+	m.search.queryInput.SetValue("rtx 3060")
+	m.search.minPriceInput.SetValue("300")
+	m.search.maxPriceInput.SetValue("100")
+
+	if _, cmd := m.search.Update(keyMsg("enter")); cmd != nil || m.search.lastError == "" {
+		t.Fatal("Expected a min price above the max price to block the search")
+	}
+
+	m.search.maxPriceInput.SetValue("500")
+	_, cmd := m.search.Update(keyMsg("enter"))
+	search, ok := runCmd(cmd).(SearchMsg)
+	if !ok {
+		t.Fatalf("Expected a search, error: %s", m.search.lastError)
+	}
+	if !reflect.DeepEqual(search.Filter, SearchFilter{MinPrice: 300, MaxPrice: 500}) {
+		t.Errorf("Expected the price range to be searched, got %+v", search.Filter)
+	}
+
+	// Listings outside the range are hidden even if the API returns them
+	tm, _ := m.Update(search)
+	m = tm.(model)
+	tm, _ = m.Update(SearchResultMsg{
+		ID:    m.searchID,
+		Query: "rtx 3060",
+		Results: []APIListing{
+			{Title: "A", Price: 250},
+			{Title: "B", Price: 300},
+			{Title: "C", Price: 450},
+			{Title: "D", Price: 650},
+		},
+	})
+	m = tm.(model)
+	if got := resultTitles(m.results); got != "B,C" {
+		t.Errorf("Expected only listings priced $300-$500, got '%s'", got)
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	tests := []struct {
+		text      string
+		condition string
+		want      bool
+	}{
+		{"New", "new", true},
+		{"Used - Good", "used", true},
+		{"Used - Good", "new", false},
+		{"Manufacturer Refurbished", "refurbished", true},
+		{"Refurb", "refurbished", true},
+		{"For parts or not working", "for-parts", true},
+		{"Parts only", "for-parts", true},
+		{"", "used", false},
+	}
+
+	for _, tt := range tests {
+		if got := conditionMatches(tt.text, tt.condition); got != tt.want {
+			t.Errorf("conditionMatches(%q, %q): expected %v, got %v", tt.text, tt.condition, tt.want, got)
+		}
+	}
+}
+
+func TestSearchConditions(t *testing.T) {
+	m := newTestModel("http://localhost:8080")
+	m.currentPane = 0
+	m.search.queryInput.SetValue("thinkpad")
+
+	_, cmd := m.search.Update(keyMsg("enter"))
+	search := runCmd(cmd).(SearchMsg)
+	if len(search.Filter.Conditions) != 0 {
+		t.Errorf("Expected any condition by default, got %q", search.Filter.Conditions)
+	}
+
+	for range 5 {
+		m.search.Update(keyMsg("down"))
+	}
+	// Pick used and for-parts; space on refurbished twice leaves it off
+	for _, k := range []string{"right", " ", "right", " ", " ", "right", " "} {
+		m.search.Update(keyMsg(k))
+	}
+	if m.search.typing() {
+		t.Error("Expected the condition selector not to take text")
+	}
+
+	search = runCmd(m.search.startSearch(false)).(SearchMsg)
+	if got := strings.Join(search.Filter.Conditions, ","); got != "used,for-parts" {
+		t.Fatalf("Expected conditions 'used,for-parts', got '%s'", got)
+	}
+
+	// Listings in other conditions are hidden, even if the API returns them
+	tm, _ := m.Update(search)
+	m = tm.(model)
+	tm, _ = m.Update(SearchResultMsg{
+		ID:    m.searchID,
+		Query: "thinkpad",
+		Results: []APIListing{
+			{Title: "A", Price: 100, Condition: "Used - Good"},
+			{Title: "B", Price: 90, Condition: "New"},
+			{Title: "C", Price: 80, Condition: "For parts or not working"},
+			{Title: "D", Price: 70, Condition: "Refurbished"},
+			{Title: "E", Price: 60},
+		},
+	})
+	m = tm.(model)
+	if got := resultTitles(m.results); got != "A,C" {
+		t.Errorf("Expected only used and for-parts listings, got '%s'", got)
+	}
+}
+
+func TestSearchSuggestions(t *testing.T) {
+	pane := NewSearchPane()
+	pane.suggestions = []SearchHistory{
+		{Query: "rtx 3060", Count: 3},
+		{Query: "thinkpad", Count: 1},
+	}
+
+	if !pane.showingSuggestions() {
+		t.Fatal("Expected suggestions for an empty query")
+	}
+
+	pane.Update(tea.KeyMsg{Type: tea.KeyDown})
+	pane.Update(tea.KeyMsg{Type: tea.KeyDown})
+	pane.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if got := pane.queryInput.Value(); got != "thinkpad" {
+		t.Errorf("Expected query 'thinkpad', got '%s'", got)
+	}
+	if pane.searching {
+		t.Error("Expected picking a suggestion not to search yet")
+	}
+	if pane.showingSuggestions() {
+		t.Error("Expected suggestions to hide once the query is filled")
+	}
+}
+
+func TestSearchSessionRoundTrips(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewSearchPane()
+	pane.db = db
+	pane.focusIndex = 1
+	pane.Update(keyMsg("right"))
+	pane.Update(keyMsg("right"))
+	pane.thresholdInput.SetValue("35")
+	pane.focusIndex = 0
+	pane.queryInput.SetValue("rtx 3060")
+	pane.Update(keyMsg("enter"))
+
+	restored := NewSearchPane()
+	restored.db = db
+	restored.loadSession()
+	if restored.providerSelect != 2 {
+		t.Errorf("Expected provider 2 (%s), got %d", pane.providers[2], restored.providerSelect)
+	}
+	if got := restored.thresholdInput.Value(); got != "35" {
+		t.Errorf("Expected threshold '35', got '%s'", got)
+	}
+
+	configs, err := db.GetAllConfigs()
+	if err != nil {
+		t.Fatalf("Failed to get configs: %v", err)
+	}
+	if len(configs) != 0 {
+		t.Errorf("Expected the session row to stay out of the config list, got %d configs", len(configs))
+	}
+}
+
+func TestSavedSearchRoundTrips(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	m := newTestModel("http://localhost:8080")
+	m.db = db
+	m.search.db = db
+	m.config.db = db
+	m.currentPane = 0
+	m.search.queryInput.SetValue("rtx 3060")
+	m.search.selectProvider("govdeals")
+	m.search.thresholdInput.SetValue("35")
+
+	tm, cmd := m.Update(keyMsg("ctrl+s"))
+	m = tm.(model)
+	if cmd == nil {
+		t.Fatalf("Expected saving to issue a command, error: %s", m.search.lastError)
+	}
+	saved, ok := cmd().(SearchSavedMsg)
+	if !ok || saved.Error != nil {
+		t.Fatalf("Expected the search to save, got %+v", saved)
+	}
+	tm, _ = m.Update(saved)
+	m = tm.(model)
+
+	name := savedSearchName("rtx 3060", "govdeals")
+	if len(m.config.configs) != 1 || m.config.configs[0].Name != name {
+		t.Fatalf("Expected the Config pane to list '%s', got %+v", name, m.config.configs)
+	}
+
+	// Start from a different form so loading has something to restore
+	m.search.queryInput.SetValue("")
+	m.search.selectProvider("shopgoodwill")
+	m.search.thresholdInput.SetValue("")
+	m.currentPane = 3
+	m.config.focusIndex = listFocus
+
+	tm, cmd = m.Update(keyMsg("l"))
+	m = tm.(model)
+	if cmd == nil {
+		t.Fatalf("Expected loading a saved search to issue a command, error: %s", m.config.lastError)
+	}
+	msg := runCmd(cmd)
+	run, ok := msg.(RunSavedSearchMsg)
+	if !ok {
+		t.Fatalf("Expected RunSavedSearchMsg, got %T", msg)
+	}
+	tm, cmd = m.Update(run)
+	m = tm.(model)
+
+	if m.currentPane != 0 {
+		t.Errorf("Expected the search pane, got pane %d", m.currentPane)
+	}
+	if got := m.search.queryInput.Value(); got != "rtx 3060" {
+		t.Errorf("Expected query 'rtx 3060', got '%s'", got)
+	}
+	if got := m.search.providers[m.search.providerSelect]; got != "govdeals" {
+		t.Errorf("Expected provider 'govdeals', got '%s'", got)
+	}
+	if got := m.search.thresholdInput.Value(); got != "35" {
+		t.Errorf("Expected threshold '35', got '%s'", got)
+	}
+
+	search, ok := runCmd(cmd).(SearchMsg)
+	if !ok {
+		t.Fatal("Expected loading the saved search to run it")
+	}
+	if search.Query != "rtx 3060" || search.Provider != "govdeals" || search.Threshold != 35 {
+		t.Errorf("Expected the saved search to run, got %+v", search)
+	}
+	if m.config.apiURL.Value() != "" {
+		t.Errorf("Expected the API settings to be left alone, got URL '%s'", m.config.apiURL.Value())
+	}
+}
+
+func TestSaveSearchNeedsQuery(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewSearchPane()
+	pane.db = db
+	if _, cmd := pane.Update(keyMsg("ctrl+s")); cmd != nil {
+		t.Error("Expected an empty query not to be saved")
+	}
+	if pane.lastError == "" {
+		t.Error("Expected an error for an empty query")
+	}
+}
+
+// collectMsgs runs cmds concurrently, unwrapping batches, and returns every
+// message they produce
+func collectMsgs(cmds []tea.Cmd) []tea.Msg {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		msgs []tea.Msg
+	)
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		defer wg.Done()
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			wg.Add(len(batch))
+			for _, c := range batch {
+				go run(c)
+			}
+			return
+		}
+		mu.Lock()
+		msgs = append(msgs, msg)
+		mu.Unlock()
+	}
+
+	wg.Add(len(cmds))
+	for _, cmd := range cmds {
+		go run(cmd)
+	}
+	wg.Wait()
+	return msgs
+}
+
+// typeAndSettle types text into the pane one key at a time, then delivers
+// the debounces it scheduled and returns the searches they issued
+func typeAndSettle(pane *SearchPane, text string) []SearchMsg {
+	var cmds []tea.Cmd
+	for _, r := range text {
+		_, cmd := pane.Update(keyMsg(string(r)))
+		cmds = append(cmds, cmd)
+	}
+
+	var fired []tea.Cmd
+	for _, msg := range collectMsgs(cmds) {
+		if debounce, ok := msg.(searchDebounceMsg); ok {
+			_, cmd := pane.Update(debounce)
+			fired = append(fired, cmd)
+		}
+	}
+
+	var searches []SearchMsg
+	for _, msg := range collectMsgs(fired) {
+		if search, ok := msg.(SearchMsg); ok {
+			searches = append(searches, search)
+		}
+	}
+	return searches
+}
+
+func TestRapidTypingSearchesOnce(t *testing.T) {
+	pane := NewSearchPane()
+
+	searches := typeAndSettle(pane, "rtx 3060")
+	if len(searches) != 1 {
+		t.Fatalf("Expected 1 search, got %d", len(searches))
+	}
+	if searches[0].Query != "rtx 3060" {
+		t.Errorf("Expected query 'rtx 3060', got '%s'", searches[0].Query)
+	}
+	if !pane.searching {
+		t.Error("Expected the pane to be searching")
+	}
+
+	// Queries too short to be worth searching wait for Enter
+	if searches := typeAndSettle(NewSearchPane(), "rt"); len(searches) != 0 {
+		t.Errorf("Expected no search for a short query, got %d", len(searches))
+	}
+}
+
+func TestSubmitDropsPendingDebounce(t *testing.T) {
+	pane := NewSearchPane()
+	pane.queryInput.SetValue("rtx 306")
+	pane.queryInput.CursorEnd()
+
+	_, cmd := pane.Update(keyMsg("0"))
+	pending := pane.generation
+	if cmd == nil {
+		t.Fatal("Expected a debounce to be scheduled")
+	}
+
+	_, cmd = pane.Update(keyMsg("enter"))
+	if _, ok := runCmd(cmd).(SearchMsg); !ok {
+		t.Fatal("Expected Enter to search right away")
+	}
+	if _, cmd = pane.Update(searchDebounceMsg{gen: pending}); cmd != nil {
+		t.Error("Expected the overtaken debounce to be dropped")
+	}
+}
+
+// searchAndSettle hands search to the model, then delivers its result and
+// runs whatever the model did with it
+func searchAndSettle(tm tea.Model, search SearchMsg) tea.Model {
+	tm, cmd := tm.Update(search)
+	tm, cmd = tm.Update(runCmd(cmd).(SearchResultMsg))
+	if cmd != nil {
+		runCmd(cmd)
+	}
+	return tm
+}
+
+func TestLiveSearchIsNotSaved(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/listings/search" {
+			w.Write([]byte(`{"items": [{"url": "https://example.com/1", "title": "RTX 3060", "price": 250, "source": "ebay"}], "total": 1}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	m := newTestModel(server.URL)
+	m.attachDatabase(db)
+
+	searches := typeAndSettle(m.search, "rtx 3060")
+	if len(searches) != 1 || !searches[0].Live {
+		t.Fatalf("Expected one live search, got %+v", searches)
+	}
+	tm := searchAndSettle(m, searches[0])
+	if len(tm.(model).results.results) != 1 {
+		t.Fatalf("Expected the live search to show its result, got %d", len(tm.(model).results.results))
+	}
+	if history, _ := db.GetSearchHistory(10); len(history) != 0 {
+		t.Errorf("Expected no search history from typing, got %+v", history)
+	}
+	if prices, _ := db.GetPriceHistory("", 10); len(prices) != 0 {
+		t.Errorf("Expected no price history from typing, got %+v", prices)
+	}
+
+	// Enter submits the same query for keeps
+	_, cmd := m.search.Update(keyMsg("enter"))
+	search := runCmd(cmd).(SearchMsg)
+	if search.Live {
+		t.Fatal("Expected Enter to submit rather than preview")
+	}
+	searchAndSettle(tm, search)
+	if history, _ := db.GetSearchHistory(10); len(history) != 1 {
+		t.Errorf("Expected the submitted search in the history, got %+v", history)
+	}
+	if prices, _ := db.GetPriceHistory("", 10); len(prices) != 1 {
+		t.Errorf("Expected the submitted search's price recorded, got %+v", prices)
+	}
+}
+
+func TestProvidersComeFromSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/sources" {
+			t.Errorf("Expected path '/api/sources', got '%s'", r.URL.Path)
+		}
+		w.Write([]byte(`["ebay", "govdeals", "liquidation", "govdeals"]`))
+	}))
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.search.providerSelect = 1 // govdeals
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(loadSources(m.apiClient)())
+	search := tm.(model).search
+	if got := strings.Join(search.providers, ","); got != "ebay,govdeals,liquidation,all,manual" {
+		t.Errorf("Expected the API's providers plus all and manual, got '%s'", got)
+	}
+	if got := search.providers[search.providerSelect]; got != "govdeals" {
+		t.Errorf("Expected govdeals to stay selected, got '%s'", got)
+	}
+}
+
+func TestProvidersFallBackToDefaults(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	var tm tea.Model = m
+	tm, _ = tm.Update(loadSources(m.apiClient)())
+	if got := strings.Join(tm.(model).search.providers, ","); got != strings.Join(defaultProviders, ",") {
+		t.Errorf("Expected the default providers, got '%s'", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// sparkBlocks are the sparkline levels, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a row of block characters scaled between the
+// series minimum and maximum. Only the last width values are drawn when the
+// series is longer than width. A flat series renders at mid height.
+func sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	top := len(sparkBlocks) - 1
+	var b strings.Builder
+	for _, v := range values {
+		level := top / 2
+		if hi > lo {
+			level = int((v-lo)/(hi-lo)*float64(top) + 0.5)
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+
+	return b.String()
+}
+
+// trackedItems returns the titles in history ordered by how many price
+// points each has, most tracked first
+func trackedItems(history []PriceHistory) []string {
+	counts := make(map[string]int)
+	for _, h := range history {
+		counts[h.ItemTitle]++
+	}
+
+	titles := make([]string, 0, len(counts))
+	for title := range counts {
+		titles = append(titles, title)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		if counts[titles[i]] != counts[titles[j]] {
+			return counts[titles[i]] > counts[titles[j]]
+		}
+		return titles[i] < titles[j]
+	})
+
+	return titles
+}
+
+// priceSeries returns the prices recorded for title, oldest first. history
+// is expected newest first, as GetPriceHistory returns it.
+func priceSeries(history []PriceHistory, title string) []float64 {
+	var series []float64
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].ItemTitle == title {
+			series = append(series, history[i].Price)
+		}
+	}
+	return series
+}
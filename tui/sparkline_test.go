@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  int
+		want   string
+	}{
+		{"empty", nil, 10, ""},
+		{"zero width", []float64{1, 2, 3}, 0, ""},
+		{"single point", []float64{42}, 10, "▄"},
+		{"all equal", []float64{5, 5, 5, 5}, 10, "▄▄▄▄"},
+		{"rising", []float64{0, 1, 2, 3, 4, 5, 6, 7}, 10, "▁▂▃▄▅▆▇█"},
+		{"negatives", []float64{-10, 0, -5}, 10, "▁█▅"},
+		{"truncated to the latest values", []float64{100, 0, 7, 0}, 3, "▁█▁"},
+	}
+
+	for _, tt := range tests {
+		if got := sparkline(tt.values, tt.width); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPriceSeries(t *testing.T) {
+	now := time.Now()
+	// Newest first, as GetPriceHistory returns it
+	history := []PriceHistory{
+		{ItemTitle: "GPU", Price: 250, Timestamp: now},
+		{ItemTitle: "CPU", Price: 90, Timestamp: now.Add(-time.Hour)},
+		{ItemTitle: "GPU", Price: 275, Timestamp: now.Add(-2 * time.Hour)},
+		{ItemTitle: "GPU", Price: 300, Timestamp: now.Add(-3 * time.Hour)},
+	}
+
+	items := trackedItems(history)
+	if len(items) != 2 || items[0] != "GPU" || items[1] != "CPU" {
+		t.Errorf("Expected [GPU CPU] by tracking count, got %v", items)
+	}
+
+	series := priceSeries(history, "GPU")
+	if len(series) != 3 || series[0] != 300 || series[2] != 250 {
+		t.Errorf("Expected GPU prices oldest first [300 275 250], got %v", series)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newSpinner returns the spinner panes animate while they load
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot))
+}
+
+// tickSpinner advances s on its tick while loading. Ticks arriving after
+// loading has cleared are dropped, which stops the animation until the
+// next load starts it again with s.Tick.
+func tickSpinner(s *spinner.Model, loading bool, msg spinner.TickMsg) tea.Cmd {
+	if !loading {
+		return nil
+	}
+	var cmd tea.Cmd
+	*s, cmd = s.Update(msg)
+	return cmd
+}
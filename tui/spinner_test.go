@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSpinnerTicksOnlyWhileLoading(t *testing.T) {
+	m := newTestModel("")
+	m.results.loading = true
+	first := m.results.spinner.View()
+
+	var tm tea.Model = m
+	tm, cmd := tm.Update(m.results.spinner.Tick())
+	if cmd == nil {
+		t.Fatal("Expected a follow-up tick while loading")
+	}
+	results := tm.(model).results
+	if results.spinner.View() == first {
+		t.Errorf("Expected the tick to advance the spinner frame, still '%s'", first)
+	}
+
+	// The follow-up tick arrives after the load has finished
+	results.loading = false
+	frame := results.spinner.View()
+	tm, cmd = tm.Update(cmd())
+	if cmd != nil {
+		t.Error("Expected the spinner to stop once loading clears")
+	}
+	if got := tm.(model).results.spinner.View(); got != frame {
+		t.Errorf("Expected the frame to stay '%s' after loading, got '%s'", frame, got)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// startupError is a failure that leaves the TUI unusable, such as a database
+// that can't be opened. The model shows it in a modal in place of the panes
+// until a retry succeeds or the user quits.
+type startupError struct {
+	action string // what failed, as in "Couldn't open the database"
+	err    error
+	retry  tea.Cmd // tries again, reporting another StartupErrorMsg on failure
+}
+
+// openDatabase opens the database again for a retry from the error modal
+func openDatabase() tea.Msg {
+	db, err := NewDatabase()
+	if err != nil {
+		return StartupErrorMsg{Action: "open the database", Error: err, Retry: openDatabase}
+	}
+	return DatabaseOpenedMsg{DB: db}
+}
+
+// View renders the modal centred on the screen
+func (e *startupError) View(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Error).
+		Bold(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Width(min(60, max(20, width-10)))
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Error).
+		Padding(1, 2)
+
+	prompt := "q: Quit"
+	if e.retry != nil {
+		prompt = "r: Retry • " + prompt
+	}
+
+	box := boxStyle.Render(
+		titleStyle.Render(fmt.Sprintf("✗ Couldn't %s", e.action)) + "\n\n" +
+			errorStyle.Render(e.err.Error()) + "\n\n" +
+			infoStyle.Render(prompt),
+	)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
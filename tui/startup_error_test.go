@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStartupErrorModal(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	retries := 0
+	retry := func() tea.Msg {
+		retries++
+		return DatabaseOpenedMsg{DB: db}
+	}
+
+	var tm tea.Model = newTestModel("")
+	tm, _ = tm.Update(StartupErrorMsg{
+		Action: "open the database",
+		Error:  errors.New("unable to open database file"),
+		Retry:  retry,
+	})
+	if tm.(model).startupErr == nil {
+		t.Fatal("Expected the model to switch into the startup error state")
+	}
+	view := tm.View()
+	for _, want := range []string{"Couldn't open the database", "unable to open database file", "r: Retry"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected '%s' in the modal, got:\n%s", want, view)
+		}
+	}
+
+	// Keys meant for the panes are swallowed
+	tm, _ = tm.Update(keyMsg("tab"))
+	if got := tm.(model).currentPane; got != 1 {
+		t.Errorf("Expected to stay on pane 1, got %d", got)
+	}
+
+	tm, cmd := tm.Update(keyMsg("r"))
+	if tm.(model).startupErr != nil {
+		t.Error("Expected retry to close the modal")
+	}
+	if cmd == nil {
+		t.Fatal("Expected a retry command, got nil")
+	}
+	tm, _ = tm.Update(cmd())
+	if retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", retries)
+	}
+	if m := tm.(model); m.db != db || m.search.db != db || m.watchlist.db != db {
+		t.Error("Expected the reopened database to be attached to the model and panes")
+	}
+}
+
+func TestStartupErrorQuits(t *testing.T) {
+	var tm tea.Model = newTestModel("")
+	tm, _ = tm.Update(StartupErrorMsg{Action: "open the database", Error: errors.New("disk I/O error")})
+	if strings.Contains(tm.View(), "Retry") {
+		t.Error("Expected no retry prompt without a retry command")
+	}
+
+	_, cmd := tm.Update(keyMsg("q"))
+	if cmd == nil {
+		t.Fatal("Expected a quit command, got nil")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected q to quit")
+	}
+}
+
+func TestInitialConfigLoadFailureIsStartupError(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	db.Close()
+
+	msg, ok := loadInitialConfigs(NewConfigPane(), db)().(StartupErrorMsg)
+	if !ok {
+		t.Fatal("Expected a StartupErrorMsg for an unreadable database")
+	}
+	if msg.Action != "load saved configs" || msg.Error == nil || msg.Retry == nil {
+		t.Errorf("Expected a retryable config load error, got %+v", msg)
+	}
+}
+
+func TestInitialModelWithoutDatabase(t *testing.T) {
+	// main builds the model before it knows whether the database opened
+	m := initialModel(nil)
+	if m.db != nil || m.unreadAlerts != 0 {
+		t.Errorf("Expected a model without a database, got db %v and %d alerts", m.db, m.unreadAlerts)
+	}
+
+	var tm tea.Model = m
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	tm, _ = tm.Update(StartupErrorMsg{Action: "open the database", Error: errors.New("unable to open database file")})
+	if view := tm.View(); !strings.Contains(view, "Couldn't open the database") {
+		t.Errorf("Expected the startup error modal, got:\n%s", view)
+	}
+}
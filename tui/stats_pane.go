@@ -2,8 +2,14 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -11,29 +17,112 @@ import (
 type StatsPane struct {
 	dbStats     map[string]int
 	apiStats    *APIStatistics
+	health      *HealthStatus
 	priceHist   []PriceHistory
+	priceDrops  []PriceDrop
+	topMovers   []Mover
+	trend       PriceTrendMsg
+	source      string // provider last searched, narrowing the trend; empty for all
+	chartIdx    int    // index into trackedItems of the item charted
+	rangeIdx    int    // index into statsRanges of the price analysis window
+	bySource    map[string]SourceStat
+	bySourceAPI bool // true when bySource came from the API
 	loading     bool
+	spinner     spinner.Model
 	lastError   string
+	lastSuccess string
 	apiClient   *APIClient
 	db          *Database
 }
 
-func NewStatsPane() *StatsPane {
+// priceDropThreshold is the smallest percentage fall reported as a price drop
+const priceDropThreshold = 10.0
+
+// maxPriceDropsShown caps the price drop section of the pane
+const maxPriceDropsShown = 5
+
+// maxTopMovers caps the top movers section of the pane
+const maxTopMovers = 5
+
+// sparklineWidth is the most price points charted in the trend sparkline
+const sparklineWidth = 40
+
+// statsRange is a window of price history the price analysis covers
+type statsRange struct {
+	label  string
+	window time.Duration // zero for all history
+}
+
+// statsRanges are the windows cycled with t, all history last
+var statsRanges = []statsRange{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+	{"all", 0},
+}
+
+func NewStatsPane(apiClient *APIClient) *StatsPane {
 	return &StatsPane{
 		dbStats:   make(map[string]int),
-		apiClient: NewAPIClient(""),
+		rangeIdx:  len(statsRanges) - 1,
+		spinner:   newSpinner(),
+		apiClient: apiClient,
 	}
 }
 
+// window returns the selected window, zero for all history
+func (p *StatsPane) window() time.Duration {
+	return statsRanges[p.rangeIdx].window
+}
+
 func (p *StatsPane) Update(msg tea.Msg) (StatsPane, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "r":
-			// Refresh statistics
-			p.loading = true
-			// TODO: Implement refresh
+		switch {
+		case key.Matches(msg, keys.Stats.PrevItem):
+			// Chart the previous tracked item
+			if n := len(trackedItems(p.priceHist)); n > 0 {
+				p.chartIdx = (p.chartIdx + n - 1) % n
+			}
+			return *p, p.loadTrend()
+
+		case key.Matches(msg, keys.Stats.NextItem):
+			// Chart the next tracked item
+			if n := len(trackedItems(p.priceHist)); n > 0 {
+				p.chartIdx = (p.chartIdx + 1) % n
+			}
+			return *p, p.loadTrend()
+
+		case key.Matches(msg, keys.Stats.Export):
+			// Export the charted item's price history
+			if title, ok := p.chartedItem(); ok {
+				p.exportPriceHistory(title)
+			}
+			return *p, nil
+
+		case key.Matches(msg, keys.Stats.ExportAll):
+			p.exportPriceHistory("")
 			return *p, nil
+
+		case key.Matches(msg, keys.Stats.Range):
+			// Narrow the price analysis to the next window and reload it
+			p.rangeIdx = (p.rangeIdx + 1) % len(statsRanges)
+			p.chartIdx = 0
+			p.loading = true
+			db, client, window := p.db, p.apiClient, p.window()
+			return *p, tea.Batch(func() tea.Msg {
+				return collectStats(db, client, false, window)
+			}, p.spinner.Tick)
+
+		case key.Matches(msg, keys.Stats.Refresh):
+			// Refresh statistics, bypassing the API statistics cache
+			p.loading = true
+			p.lastError = ""
+			p.lastSuccess = ""
+			db, client, window := p.db, p.apiClient, p.window()
+			return *p, tea.Batch(func() tea.Msg {
+				return collectStats(db, client, true, window)
+			}, p.spinner.Tick)
 		}
 	}
 
@@ -45,24 +134,24 @@ func (p *StatsPane) View(width, height int) string {
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#7D56F4")).
+		Foreground(theme.Primary).
 		MarginBottom(1)
 
 	sectionStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Text).
 		MarginTop(1).
 		MarginBottom(1)
 
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00D7FF"))
+		Foreground(theme.Accent)
 
 	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FAFAFA")).
+		Foreground(theme.Text).
 		Bold(true)
 
 	infoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#626262")).
+		Foreground(theme.Muted).
 		Italic(true)
 
 	// Title
@@ -71,15 +160,15 @@ func (p *StatsPane) View(width, height int) string {
 
 	if p.loading {
 		statusStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#00FF00")).
+			Foreground(theme.Success).
 			Bold(true)
-		b.WriteString(statusStyle.Render("🔄 Loading statistics..."))
+		b.WriteString(statusStyle.Render(p.spinner.View() + " Loading statistics..."))
 		b.WriteString("\n")
 	} else {
 		// Database statistics
 		b.WriteString(sectionStyle.Render("💾 Local Database"))
 		b.WriteString("\n")
-		
+
 		if len(p.dbStats) > 0 {
 			b.WriteString(fmt.Sprintf("%s %s\n",
 				labelStyle.Render("Total Searches:"),
@@ -106,7 +195,7 @@ func (p *StatsPane) View(width, height int) string {
 		b.WriteString("\n")
 		b.WriteString(sectionStyle.Render("🌐 API Statistics"))
 		b.WriteString("\n")
-		
+
 		if p.apiStats != nil {
 			b.WriteString(fmt.Sprintf("%s %s\n",
 				labelStyle.Render("Total Listings:"),
@@ -125,41 +214,196 @@ func (p *StatsPane) View(width, height int) string {
 			b.WriteString("\n")
 		}
 
+		// Backend health
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("🩺 Backend Health"))
+		b.WriteString("\n")
+		b.WriteString(p.healthView(labelStyle, valueStyle, infoStyle))
+
+		// Per-source breakdown
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("🏪 By Source"))
+		b.WriteString("\n")
+
+		if len(p.bySource) > 0 {
+			headerStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(theme.Text).
+				Background(theme.HeaderBg)
+
+			sources := make([]string, 0, len(p.bySource))
+			for source := range p.bySource {
+				sources = append(sources, source)
+			}
+			sort.Strings(sources)
+
+			b.WriteString(headerStyle.Render(fmt.Sprintf("%-15s %8s %10s %10s %10s", "Source", "Count", "Avg", "Min", "Max")))
+			b.WriteString("\n")
+			for _, source := range sources {
+				s := p.bySource[source]
+				b.WriteString(fmt.Sprintf("%-15s %8d %10s %10s %10s\n",
+					source,
+					s.Count,
+					fmt.Sprintf("$%.2f", s.AvgPrice),
+					fmt.Sprintf("$%.2f", s.MinPrice),
+					fmt.Sprintf("$%.2f", s.MaxPrice),
+				))
+			}
+			origin := "from cached listings"
+			if p.bySourceAPI {
+				origin = "from the API"
+			}
+			b.WriteString(infoStyle.Render(origin))
+			b.WriteString("\n")
+		} else {
+			b.WriteString(infoStyle.Render("No listings yet"))
+			b.WriteString("\n")
+		}
+
 		// Price analysis
 		b.WriteString("\n")
 		b.WriteString(sectionStyle.Render("💰 Price Analysis"))
+		b.WriteString(infoStyle.Render(fmt.Sprintf(" (%s)", statsRanges[p.rangeIdx].label)))
 		b.WriteString("\n")
-		
+
 		if len(p.priceHist) > 0 {
-			// Calculate average price from history
-			var total float64
-			for _, ph := range p.priceHist {
-				total += ph.Price
+			prices := make([]float64, len(p.priceHist))
+			for i, ph := range p.priceHist {
+				prices[i] = ph.Price
 			}
-			avg := total / float64(len(p.priceHist))
-			
+			summary := priceStats(prices)
+
 			b.WriteString(fmt.Sprintf("%s %s\n",
 				labelStyle.Render("Tracked Items:"),
 				valueStyle.Render(fmt.Sprintf("%d", len(p.priceHist))),
 			))
 			b.WriteString(fmt.Sprintf("%s %s\n",
 				labelStyle.Render("Avg Tracked Price:"),
-				valueStyle.Render(fmt.Sprintf("$%.2f", avg)),
+				valueStyle.Render(fmt.Sprintf("$%.2f", summary.Mean)),
+			))
+			b.WriteString(fmt.Sprintf("%s %s\n",
+				labelStyle.Render("Median Price:"),
+				valueStyle.Render(fmt.Sprintf("$%.2f", summary.Median)),
+			))
+			b.WriteString(fmt.Sprintf("%s %s\n",
+				labelStyle.Render("Middle 50%:"),
+				valueStyle.Render(fmt.Sprintf("$%.2f - $%.2f", summary.P25, summary.P75)),
+			))
+			b.WriteString(fmt.Sprintf("%s %s\n",
+				labelStyle.Render("Std Deviation:"),
+				valueStyle.Render(fmt.Sprintf("$%.2f", summary.StdDev)),
+			))
+
+			// Trend of the charted item, most tracked first
+			items := trackedItems(p.priceHist)
+			title, _ := p.chartedItem()
+			series := priceSeries(p.priceHist, title)
+			b.WriteString(fmt.Sprintf("%s %s %s\n",
+				labelStyle.Render(fmt.Sprintf("Trend (%s, %d/%d):", title, p.chartIdx%len(items)+1, len(items))),
+				valueStyle.Render(sparkline(series, sparklineWidth)),
+				infoStyle.Render(fmt.Sprintf("$%.2f → $%.2f", series[0], series[len(series)-1])),
 			))
+			if trend := p.trend.Points; p.trend.Title == title && p.trend.Source == p.source && len(trend) > 0 {
+				averages := make([]float64, len(trend))
+				for i, tp := range trend {
+					averages[i] = tp.AvgPrice
+				}
+				details := []string{trendIntervalLabels[statsTrendInterval]}
+				if p.trend.Source != "" {
+					details = append(details, p.trend.Source)
+				}
+				if p.trend.Local {
+					details = append(details, "local")
+				} else {
+					details = append(details, "API")
+				}
+				b.WriteString(fmt.Sprintf("%s %s %s\n",
+					labelStyle.Render(fmt.Sprintf("Long-term (%s):", strings.Join(details, ", "))),
+					valueStyle.Render(sparkline(averages, sparklineWidth)),
+					infoStyle.Render(fmt.Sprintf("$%.2f → $%.2f", averages[0], averages[len(averages)-1])),
+				))
+			}
+		} else if statsRanges[p.rangeIdx].window > 0 {
+			b.WriteString(infoStyle.Render(fmt.Sprintf("No prices recorded in the last %s", statsRanges[p.rangeIdx].label)))
+			b.WriteString("\n")
 		} else {
 			b.WriteString(infoStyle.Render("No price history yet"))
 			b.WriteString("\n")
 		}
+
+		// Price drops
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("📉 Recent Price Drops"))
+		b.WriteString("\n")
+
+		if len(p.priceDrops) > 0 {
+			dropStyle := lipgloss.NewStyle().
+				Foreground(theme.Success).
+				Bold(true)
+
+			for i, drop := range p.priceDrops {
+				if i == maxPriceDropsShown {
+					b.WriteString(infoStyle.Render(fmt.Sprintf("... and %d more", len(p.priceDrops)-i)))
+					b.WriteString("\n")
+					break
+				}
+				b.WriteString(fmt.Sprintf("%s %s\n",
+					labelStyle.Render(drop.ItemTitle+":"),
+					dropStyle.Render(fmt.Sprintf("$%.2f → $%.2f (-%.1f%%)", drop.PreviousPrice, drop.CurrentPrice, drop.DropPct)),
+				))
+			}
+		} else {
+			b.WriteString(infoStyle.Render(fmt.Sprintf("No drops of %.0f%% or more", priceDropThreshold)))
+			b.WriteString("\n")
+		}
+
+		// Top movers over the selected window
+		b.WriteString("\n")
+		b.WriteString(sectionStyle.Render("🚀 Top Movers"))
+		b.WriteString(infoStyle.Render(fmt.Sprintf(" (%s)", statsRanges[p.rangeIdx].label)))
+		b.WriteString("\n")
+
+		if len(p.topMovers) > 0 {
+			// Falling prices are the opportunities, so they get the success colour
+			upStyle := lipgloss.NewStyle().
+				Foreground(theme.Error).
+				Bold(true)
+			downStyle := lipgloss.NewStyle().
+				Foreground(theme.Success).
+				Bold(true)
+
+			for _, mover := range p.topMovers {
+				arrow, style := "▲", upStyle
+				if mover.Delta < 0 {
+					arrow, style = "▼", downStyle
+				}
+				b.WriteString(fmt.Sprintf("%s %s\n",
+					labelStyle.Render(mover.ItemTitle+":"),
+					style.Render(fmt.Sprintf("%s $%.2f → $%.2f (%+.2f, %+.1f%%)", arrow, mover.OldPrice, mover.NewPrice, mover.Delta, mover.Pct)),
+				))
+			}
+		} else {
+			b.WriteString(infoStyle.Render("No price changes in this window"))
+			b.WriteString("\n")
+		}
 	}
 
 	// Instructions
 	b.WriteString("\n\n")
-	b.WriteString(infoStyle.Render("r: Refresh • Tab: Switch pane"))
+	b.WriteString(infoStyle.Render("←/→ or h/l: Chart another item • t: Time range • e/E: Export item/all price history • r: Refresh • Tab: Switch pane"))
+
+	if p.lastSuccess != "" {
+		successStyle := lipgloss.NewStyle().
+			Foreground(theme.Success).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(successStyle.Render("✓ " + p.lastSuccess))
+	}
 
 	// Error
 	if p.lastError != "" {
 		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000")).
+			Foreground(theme.Error).
 			Bold(true)
 		b.WriteString("\n\n")
 		b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Error: %s", p.lastError)))
@@ -168,27 +412,190 @@ func (p *StatsPane) View(width, height int) string {
 	return b.String()
 }
 
-func (p *StatsPane) LoadStats(db *Database) {
+// chartedItem returns the title of the item charted in the trend line
+func (p *StatsPane) chartedItem() (string, bool) {
+	items := trackedItems(p.priceHist)
+	if len(items) == 0 {
+		return "", false
+	}
+	return items[p.chartIdx%len(items)], true
+}
+
+// loadTrend fetches the long-term trend of the charted item from the
+// selected source
+func (p *StatsPane) loadTrend() tea.Cmd {
+	title, ok := p.chartedItem()
+	if !ok {
+		return nil
+	}
+	return loadPriceTrend(p.apiClient, p.db, title, p.source)
+}
+
+// ApplyTrend shows a loaded long-term trend, unless the chart has moved on
+// to another item or source since it was requested
+func (p *StatsPane) ApplyTrend(msg PriceTrendMsg) {
+	if title, ok := p.chartedItem(); !ok || title != msg.Title || msg.Source != p.source {
+		return
+	}
+	p.trend = msg
+}
+
+// exportPriceHistory writes the full price history of title, or of every
+// item when title is empty, to a JSON file in the home directory
+func (p *StatsPane) exportPriceHistory(title string) {
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	// GetPriceHistory matches titles by substring, so keep exact matches only
+	all, err := p.db.GetPriceHistory(title, -1)
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	var hist []PriceHistory
+	for _, h := range all {
+		if title == "" || h.ItemTitle == title {
+			hist = append(hist, h)
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+	path := filepath.Join(homeDir, priceHistoryFileName(title))
+
+	if err := exportPriceHistoryFile(path, hist); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.lastSuccess = fmt.Sprintf("Price history exported to %s", path)
+}
+
+// healthView renders the backend health section
+func (p *StatsPane) healthView(labelStyle, valueStyle, infoStyle lipgloss.Style) string {
+	switch {
+	case p.health == nil:
+		return infoStyle.Render("API not connected") + "\n"
+	case p.health.Basic:
+		return valueStyle.Render("Reachable") + " " + infoStyle.Render("(the backend has no health endpoint)") + "\n"
+	}
+
+	db := "disconnected"
+	if p.health.DBConnected {
+		db = "connected"
+	}
+
+	var b strings.Builder
+	line := func(label, value string) {
+		b.WriteString(fmt.Sprintf("%s %s\n", labelStyle.Render(label), valueStyle.Render(value)))
+	}
+	line("Status:", valueOr(p.health.Status, "unknown"))
+	line("Version:", valueOr(p.health.Version, "unknown"))
+	line("Uptime:", p.health.Uptime().Round(time.Second).String())
+	line("Database:", db)
+	return b.String()
+}
+
+// ApplyStats shows freshly collected statistics. API statistics are
+// optional, so a missing result keeps whatever was shown before.
+func (p *StatsPane) ApplyStats(msg StatsLoadedMsg) {
+	if msg.DBStats != nil {
+		p.dbStats = msg.DBStats
+	}
+	if msg.PriceHistory != nil {
+		p.priceHist = msg.PriceHistory
+	}
+	if msg.PriceDrops != nil {
+		p.priceDrops = msg.PriceDrops
+	}
+	if msg.TopMovers != nil {
+		p.topMovers = msg.TopMovers
+	}
+	if msg.APIStats != nil {
+		p.apiStats = msg.APIStats
+	}
+	if msg.Health != nil {
+		p.health = msg.Health
+	}
+	if msg.BySource != nil {
+		p.bySource, p.bySourceAPI = msg.BySource, msg.BySourceAPI
+	}
+	if msg.Error != nil {
+		p.lastError = msg.Error.Error()
+	}
+
+	p.loading = false
+}
+
+// collectStats gathers database and API statistics, with the price history
+// and top movers of the given window (all history for zero). force bypasses
+// the client's statistics cache.
+func collectStats(db *Database, client *APIClient, force bool, window time.Duration) StatsLoadedMsg {
+	var msg StatsLoadedMsg
+	var since time.Time
+	if window > 0 {
+		since = time.Now().Add(-window)
+	}
+
 	if db != nil {
 		stats, err := db.GetStats()
 		if err == nil {
-			p.dbStats = stats
+			msg.DBStats = stats
 		} else {
-			p.lastError = err.Error()
+			msg.Error = err
+		}
+
+		// Load recent price history; an empty window still replaces the
+		// history shown for the previous one
+		priceHist, err := db.GetPriceHistoryInRange("", since, 100)
+		if err == nil {
+			if priceHist == nil {
+				priceHist = []PriceHistory{}
+			}
+			msg.PriceHistory = priceHist
+		}
+
+		movers, err := db.GetTopMovers(window, maxTopMovers)
+		if err == nil {
+			if movers == nil {
+				movers = []Mover{}
+			}
+			msg.TopMovers = movers
 		}
 
-		// Load recent price history
-		priceHist, err := db.GetPriceHistory("", 100)
+		priceDrops, err := db.DetectPriceDrops(priceDropThreshold)
 		if err == nil {
-			p.priceHist = priceHist
+			msg.PriceDrops = priceDrops
 		}
+
+		bySource, err := db.GetStatsBySource()
+		if err == nil {
+			msg.BySource = bySource
+		}
+	}
+
+	// The API breakdown covers every listing on the server, so it replaces
+	// the local one when the server provides it
+	if bySource, err := client.GetStatisticsBySource(); err == nil {
+		msg.BySource, msg.BySourceAPI = bySource, true
 	}
 
 	// Load API stats
-	apiStats, err := p.apiClient.GetStatistics()
-	if err == nil {
-		p.apiStats = apiStats
+	if force {
+		msg.APIStats, _ = client.ForceRefreshStatistics()
+	} else {
+		msg.APIStats, _ = client.GetStatistics()
 	}
 
-	p.loading = false
+	msg.Health, _ = client.Health()
+
+	return msg
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStatsRefreshBypassesCache(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/statistics" {
+			http.NotFound(w, r)
+			return
+		}
+		n := atomic.AddInt32(&requests, 1)
+		json.NewEncoder(w).Encode(APIStatistics{TotalListings: int(n) * 100})
+	}))
+	defer server.Close()
+
+	m := newTestModel(server.URL)
+	m.currentPane = 2
+	m.stats.ApplyStats(collectStats(nil, m.apiClient, false, 0))
+	m.stats.ApplyStats(collectStats(nil, m.apiClient, false, 0))
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Fatalf("Expected 1 request while the cache is fresh, got %d", got)
+	}
+
+	updated, cmd := m.Update(keyMsg("r"))
+	if cmd == nil {
+		t.Fatal("Expected refresh command, got nil")
+	}
+	if !updated.(model).stats.loading {
+		t.Error("Expected stats pane to be loading")
+	}
+
+	updated, _ = updated.Update(runCmd(cmd))
+	stats := updated.(model).stats
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("Expected 'r' to bypass the cache, got %d requests", got)
+	}
+	if stats.loading {
+		t.Error("Expected loading to be cleared")
+	}
+	if stats.apiStats == nil || stats.apiStats.TotalListings != 200 {
+		t.Errorf("Expected refreshed API statistics, got %+v", stats.apiStats)
+	}
+}
+
+func TestStatsChartsTrackedItems(t *testing.T) {
+	pane := NewStatsPane(NewAPIClient(""))
+	pane.priceHist = []PriceHistory{
+		{ItemTitle: "GPU", Price: 250},
+		{ItemTitle: "CPU", Price: 90},
+		{ItemTitle: "GPU", Price: 300},
+	}
+
+	view := pane.View(120, 40)
+	if !strings.Contains(view, "Trend (GPU, 1/2):") || !strings.Contains(view, "█▁") {
+		t.Errorf("Expected the most tracked item charted first, got:\n%s", view)
+	}
+
+	pane.Update(keyMsg("right"))
+	if view := pane.View(120, 40); !strings.Contains(view, "Trend (CPU, 2/2):") {
+		t.Errorf("Expected 'right' to chart the next item, got:\n%s", view)
+	}
+
+	pane.Update(keyMsg("right"))
+	if pane.chartIdx != 0 {
+		t.Errorf("Expected the chart to wrap around, got index %d", pane.chartIdx)
+	}
+}
+
+func TestStatsBySourcePrefersAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/statistics/by_source" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]SourceStat{
+			"govdeals": {Count: 12, AvgPrice: 80, MinPrice: 10, MaxPrice: 150},
+		})
+	}))
+	defer server.Close()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	if err := db.CacheListing(Listing{Source: "ebay", URL: "https://example.com/1", Title: "Monitor", Price: 40}); err != nil {
+		t.Fatalf("Failed to cache listing: %v", err)
+	}
+
+	pane := NewStatsPane(NewAPIClient(server.URL))
+	pane.ApplyStats(collectStats(db, pane.apiClient, false, pane.window()))
+	if !pane.bySourceAPI || pane.bySource["govdeals"].Count != 12 {
+		t.Errorf("Expected the API breakdown, got %+v", pane.bySource)
+	}
+	if view := pane.View(120, 60); !strings.Contains(view, "govdeals") || !strings.Contains(view, "from the API") {
+		t.Errorf("Expected the API breakdown rendered, got:\n%s", view)
+	}
+
+	// Without the endpoint the local cache is summarised instead
+	server.Close()
+	pane = NewStatsPane(NewAPIClient(server.URL))
+	pane.ApplyStats(collectStats(db, pane.apiClient, false, pane.window()))
+	if pane.bySourceAPI || pane.bySource["ebay"].Count != 1 {
+		t.Errorf("Expected the local breakdown, got %+v", pane.bySource)
+	}
+}
+
+func TestStatsTimeRange(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now().UTC()
+	for _, r := range []struct {
+		price float64
+		age   time.Duration
+	}{{100, 2 * time.Hour}, {200, 3 * 24 * time.Hour}, {600, 60 * 24 * time.Hour}} {
+		if _, err := db.db.Exec(
+			"INSERT INTO price_history (item_title, price, source, timestamp, metadata) VALUES ('Monitor', ?, 'ebay', ?, '{}')",
+			r.price, now.Add(-r.age).Format("2006-01-02 15:04:05"),
+		); err != nil {
+			t.Fatalf("Failed to seed price history: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	pane := NewStatsPane(NewAPIClient(server.URL))
+	pane.db = db
+	pane.ApplyStats(collectStats(db, pane.apiClient, false, pane.window()))
+	if got := len(pane.priceHist); got != 3 {
+		t.Fatalf("Expected all 3 prices by default, got %d", got)
+	}
+
+	// Each press moves to the next window: 24h, 7d, 30d, then all again
+	for _, want := range []struct {
+		label string
+		count int
+		mean  string
+		mover string
+	}{
+		{"24h", 1, "$100.00", "No price changes in this window"},
+		{"7d", 2, "$150.00", "▼ $200.00 → $100.00 (-100.00, -50.0%)"},
+		{"30d", 2, "$150.00", "▼ $200.00 → $100.00 (-100.00, -50.0%)"},
+		{"all", 3, "$300.00", "▼ $600.00 → $100.00 (-500.00, -83.3%)"},
+	} {
+		_, cmd := pane.Update(keyMsg("t"))
+		pane.ApplyStats(runCmd(cmd).(StatsLoadedMsg))
+
+		if got := statsRanges[pane.rangeIdx].label; got != want.label {
+			t.Fatalf("Expected the %s window, got %s", want.label, got)
+		}
+		if got := len(pane.priceHist); got != want.count {
+			t.Errorf("%s: expected %d prices, got %d", want.label, want.count, got)
+		}
+		view := pane.View(120, 60)
+		if !strings.Contains(view, "("+want.label+")") || !strings.Contains(view, want.mean) {
+			t.Errorf("%s: expected the window and average %s shown, got:\n%s", want.label, want.mean, view)
+		}
+		if !strings.Contains(view, want.mover) {
+			t.Errorf("%s: expected top mover %q, got:\n%s", want.label, want.mover, view)
+		}
+	}
+}
+
+func TestInitialStatsLoadReportsMessage(t *testing.T) {
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	// The command only reports what it collected; the pane changes when
+	// the model applies the message
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	m := newTestModel(server.URL)
+	msg, ok := loadInitialStats(db, m.apiClient, m.stats.window())().(StatsLoadedMsg)
+	if !ok || msg.DBStats == nil {
+		t.Fatalf("Expected a StatsLoadedMsg with the database stats, got %+v", msg)
+	}
+	if m.stats.dbStats == nil || len(m.stats.dbStats) != 0 {
+		t.Errorf("Expected the pane untouched until the message is applied, got %v", m.stats.dbStats)
+	}
+}
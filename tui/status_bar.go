@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusPingInterval is how often the status bar re-checks the API
+const statusPingInterval = 30 * time.Second
+
+// statusPingMsg asks the model to ping the API for the status bar
+type statusPingMsg struct{}
+
+// scheduleStatusPing fires the next status bar ping after statusPingInterval
+func scheduleStatusPing() tea.Cmd {
+	return tea.Tick(statusPingInterval, func(time.Time) tea.Msg {
+		return statusPingMsg{}
+	})
+}
+
+// statusBar renders the bottom line: API reachability, the base URL, when
+// data was last refreshed and the auto-refresh setting
+func (m model) statusBar() string {
+	barStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Background(theme.HeaderBg).
+		Padding(0, 1)
+
+	dot, state := theme.Muted, "checking"
+	switch {
+	case !m.pinged:
+	case m.offline:
+		dot, state = theme.Error, "offline"
+	default:
+		dot, state = theme.Success, "online"
+	}
+	indicator := lipgloss.NewStyle().
+		Foreground(dot).
+		Background(theme.HeaderBg).
+		Render("●")
+
+	refreshed := "never"
+	if !m.lastRefresh.IsZero() {
+		refreshed = m.lastRefresh.Format("15:04:05")
+	}
+
+	autoRefresh := "auto-refresh off"
+	if m.autoRefresh {
+		autoRefresh = fmt.Sprintf("auto-refresh every %ds", int(m.config.refreshEvery.Seconds()))
+	}
+
+	status := fmt.Sprintf("%s • %s • last refresh %s • %s", state, m.apiClient.BaseURL(), refreshed, autoRefresh)
+	if m.unreadAlerts > 0 {
+		status += fmt.Sprintf(" • 🔔 %d unread alerts", m.unreadAlerts)
+	}
+
+	return indicator + barStyle.Render(status)
+}
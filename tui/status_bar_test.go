@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestStatusBarTracksConnectivity(t *testing.T) {
+	var m tea.Model = newTestModel("http://api.example.com:8080")
+	if view := m.View(); !strings.Contains(view, "checking • http://api.example.com:8080 • last refresh never") {
+		t.Errorf("Expected an unchecked status bar before the first ping, got:\n%s", view)
+	}
+
+	m, _ = m.Update(ConnectivityMsg{Online: true})
+	if view := m.View(); !strings.Contains(view, "online • http://api.example.com:8080") {
+		t.Errorf("Expected the status bar to show online, got:\n%s", view)
+	}
+
+	m, _ = m.Update(ConnectivityMsg{Online: false, Error: errors.New("connection refused")})
+	if view := m.View(); !strings.Contains(view, "offline • http://api.example.com:8080") {
+		t.Errorf("Expected the status bar to show offline, got:\n%s", view)
+	}
+
+	m, _ = m.Update(SearchResultMsg{Results: []APIListing{{Title: "RTX 3060"}}, Refresh: true})
+	if view := m.View(); strings.Contains(view, "last refresh never") {
+		t.Errorf("Expected the last refresh time after loading results, got:\n%s", view)
+	}
+}
+
+func TestStatusPingReschedules(t *testing.T) {
+	var m tea.Model = newTestModel("")
+	if _, cmd := m.Update(statusPingMsg{}); cmd == nil {
+		t.Error("Expected a status ping to ping the API and schedule the next one")
+	}
+}
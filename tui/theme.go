@@ -0,0 +1,74 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// Theme is the colour palette the panes render with
+type Theme struct {
+	Name       string
+	Primary    lipgloss.Color // titles and highlights
+	Accent     lipgloss.Color // field labels
+	Text       lipgloss.Color // emphasised text and table headers
+	Success    lipgloss.Color
+	Error      lipgloss.Color
+	Muted      lipgloss.Color // hints and secondary information
+	Subtle     lipgloss.Color // empty states and inactive tabs
+	SelectedBg lipgloss.Color // selected rows and the active tab
+	HeaderBg   lipgloss.Color // table headers and the status bar
+	TitleBg    lipgloss.Color // the title bar and inactive tabs
+}
+
+// themes are the built-in palettes; the first is the default
+var themes = []Theme{
+	{
+		Name:       "dark",
+		Primary:    "#7D56F4",
+		Accent:     "#00D7FF",
+		Text:       "#FAFAFA",
+		Success:    "#00FF00",
+		Error:      "#FF0000",
+		Muted:      "#626262",
+		Subtle:     "#888888",
+		SelectedBg: "#7D56F4",
+		HeaderBg:   "#3a3a3a",
+		TitleBg:    "#1a1a1a",
+	},
+	{
+		Name:       "high-contrast",
+		Primary:    "#FFFF00",
+		Accent:     "#00FFFF",
+		Text:       "#FFFFFF",
+		Success:    "#00FF00",
+		Error:      "#FF5555",
+		Muted:      "#D0D0D0",
+		Subtle:     "#C0C0C0",
+		SelectedBg: "#0000AF",
+		HeaderBg:   "#000000",
+		TitleBg:    "#000000",
+	},
+	{
+		Name:       "light",
+		Primary:    "#5A3FC0",
+		Accent:     "#005F87",
+		Text:       "#1A1A1A",
+		Success:    "#007A00",
+		Error:      "#C00000",
+		Muted:      "#6C6C6C",
+		Subtle:     "#808080",
+		SelectedBg: "#C8B8FF",
+		HeaderBg:   "#D0D0D0",
+		TitleBg:    "#EEEEEE",
+	},
+}
+
+// theme is the palette in use; the Config pane switches it
+var theme = themes[0]
+
+// themeIndex returns the position of the named theme in themes
+func themeIndex(name string) (int, bool) {
+	for i, t := range themes {
+		if t.Name == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestThemeChangesRenderedTitle(t *testing.T) {
+	// Tests run without a terminal, so force colour output
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+	defer func() { theme = themes[0] }()
+
+	m := newTestModel("")
+	title := func() string {
+		return strings.Split(m.View(), "\n")[0]
+	}
+
+	theme = themes[0]
+	dark := title()
+	idx, ok := themeIndex("light")
+	if !ok {
+		t.Fatal("Expected a built-in 'light' theme")
+	}
+	theme = themes[idx]
+	light := title()
+
+	if dark == light {
+		t.Errorf("Expected the title to render differently per theme, got %q for both", dark)
+	}
+}
+
+func TestConfigPaneSelectsAndPersistsTheme(t *testing.T) {
+	defer func() { theme = themes[0] }()
+
+	db, err := NewDatabaseAt(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	pane := NewConfigPane()
+	pane.db = db
+	pane.apiClient = NewAPIClient("")
+	pane.focusIndex = themeFocus
+	pane.Update(keyMsg("right"))
+	if theme.Name != themes[1].Name {
+		t.Fatalf("Expected '→' to switch to the %s theme, got %s", themes[1].Name, theme.Name)
+	}
+
+	pane.newConfigName.SetValue("contrast")
+	pane.Update(keyMsg("s"))
+	if pane.lastError != "" {
+		t.Fatalf("Failed to save config: %s", pane.lastError)
+	}
+
+	pane.selectTheme(0)
+	pane.loadConfig("contrast")
+	if theme.Name != themes[1].Name {
+		t.Errorf("Expected loading the config to restore the %s theme, got %s", themes[1].Name, theme.Name)
+	}
+}
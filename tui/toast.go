@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// toastTTL is how long a notification stays on screen
+const toastTTL = 4 * time.Second
+
+// maxToasts caps the stack; the oldest notification makes way for a new one
+const maxToasts = 3
+
+// toastWidth is the widest a notification is drawn
+const toastWidth = 40
+
+// severity picks a notification's colour
+type severity int
+
+const (
+	severityInfo severity = iota
+	severitySuccess
+	severityError
+)
+
+// toast is one transient notification
+type toast struct {
+	id       int
+	text     string
+	severity severity
+}
+
+// toastMsg asks the model to show a notification
+type toastMsg struct {
+	text     string
+	severity severity
+}
+
+// toastExpiredMsg dismisses the notification with the given id
+type toastExpiredMsg struct {
+	id int
+}
+
+// notify returns a command that shows text as a notification
+func notify(text string, sev severity) tea.Cmd {
+	return func() tea.Msg {
+		return toastMsg{text: text, severity: sev}
+	}
+}
+
+// pushToast shows a notification and schedules its dismissal
+func (m *model) pushToast(text string, sev severity) tea.Cmd {
+	if sev == severityError {
+		logger.Error("error shown", "text", text)
+		m.errorLog.push(errorEntry{at: time.Now(), source: paneNames[m.currentPane], text: text})
+	} else {
+		logger.Debug("notification shown", "text", text)
+	}
+
+	m.toastSeq++
+	id := m.toastSeq
+
+	toasts := append([]toast(nil), m.toasts...)
+	toasts = append(toasts, toast{id: id, text: text, severity: sev})
+	if len(toasts) > maxToasts {
+		toasts = toasts[len(toasts)-maxToasts:]
+	}
+	m.toasts = toasts
+
+	return tea.Tick(toastTTL, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+// toastPaneSuccess turns a success reported by the config or stats pane into
+// a notification, so it doesn't linger under the pane
+func (m *model) toastPaneSuccess() tea.Cmd {
+	for _, success := range []*string{&m.config.lastSuccess, &m.stats.lastSuccess} {
+		if *success != "" {
+			text := *success
+			*success = ""
+			return m.pushToast(text, severitySuccess)
+		}
+	}
+	return nil
+}
+
+// expireToast removes the notification with the given id, if still shown
+func (m *model) expireToast(id int) {
+	toasts := make([]toast, 0, len(m.toasts))
+	for _, t := range m.toasts {
+		if t.id != id {
+			toasts = append(toasts, t)
+		}
+	}
+	m.toasts = toasts
+}
+
+// toastsView renders the stacked notifications, newest at the bottom
+func (m model) toastsView(width int) string {
+	colors := map[severity]lipgloss.Color{
+		severityInfo:    theme.Accent,
+		severitySuccess: theme.Success,
+		severityError:   theme.Error,
+	}
+
+	boxes := make([]string, len(m.toasts))
+	for i, t := range m.toasts {
+		boxes[i] = lipgloss.NewStyle().
+			Foreground(colors[t.severity]).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(colors[t.severity]).
+			Padding(0, 1).
+			Width(width - 2).
+			Render(t.text)
+	}
+	return lipgloss.JoinVertical(lipgloss.Right, boxes...)
+}
+
+// overlayRight draws overlay over the right edge of base, starting at line
+// top, cutting off whatever of base lies underneath
+func overlayRight(base, overlay string, top, width int) string {
+	lines := strings.Split(base, "\n")
+	for i, o := range strings.Split(overlay, "\n") {
+		row := top + i
+		if row >= len(lines) {
+			break
+		}
+		left := width - ansi.StringWidth(o)
+		line := ansi.Truncate(lines[row], left, "")
+		if pad := left - ansi.StringWidth(line); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		}
+		lines[row] = line + o
+	}
+	return strings.Join(lines, "\n")
+}
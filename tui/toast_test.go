@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestToastExpiresAfterTTL(t *testing.T) {
+	m := newTestModel("")
+	m.width, m.height = 100, 30
+
+	var tm tea.Model = m
+	tm, expire := tm.Update(toastMsg{text: "Configuration 'prod' saved", severity: severitySuccess})
+	if expire == nil {
+		t.Fatal("Expected a command scheduling the toast's expiry")
+	}
+	tm, _ = tm.Update(toastMsg{text: "Export failed", severity: severityError})
+
+	if toasts := tm.(model).toasts; len(toasts) != 2 {
+		t.Fatalf("Expected 2 stacked toasts, got %d", len(toasts))
+	}
+	view := tm.View()
+	if !strings.Contains(view, "Configuration 'prod' saved") || !strings.Contains(view, "Export failed") {
+		t.Errorf("Expected both toasts in the view, got:\n%s", view)
+	}
+
+	// The first toast's TTL message removes only that toast
+	tm, _ = tm.Update(toastExpiredMsg{id: tm.(model).toasts[0].id})
+	toasts := tm.(model).toasts
+	if len(toasts) != 1 || toasts[0].text != "Export failed" {
+		t.Errorf("Expected only 'Export failed' left, got %+v", toasts)
+	}
+	if strings.Contains(tm.View(), "Configuration 'prod' saved") {
+		t.Error("Expected the expired toast to leave the view")
+	}
+}
+
+func TestToastStackIsCapped(t *testing.T) {
+	m := newTestModel("")
+	for i := 0; i < maxToasts+2; i++ {
+		m.pushToast(strings.Repeat("x", i+1), severityInfo)
+	}
+	if len(m.toasts) != maxToasts {
+		t.Fatalf("Expected %d toasts, got %d", maxToasts, len(m.toasts))
+	}
+	if m.toasts[0].text != "xxx" {
+		t.Errorf("Expected the oldest toasts to make way, first is '%s'", m.toasts[0].text)
+	}
+}
+
+func TestOverlayRight(t *testing.T) {
+	base := "aaaaaaaaaa\nbbbbbbbbbb\ncccccccccc"
+	got := overlayRight(base, "XX\nYY", 1, 10)
+	want := "aaaaaaaaaa\nbbbbbbbbXX\nccccccccYY"
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type WatchlistPane struct {
+	items       []WatchlistItem
+	selectedIdx int
+	offset      int
+	pageSize    int
+	lastError   string
+	lastSuccess string
+	db          *Database
+}
+
+func NewWatchlistPane() *WatchlistPane {
+	return &WatchlistPane{
+		items:    []WatchlistItem{},
+		pageSize: 10,
+	}
+}
+
+func (p *WatchlistPane) Update(msg tea.Msg) (WatchlistPane, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, keys.Watchlist.Up):
+			if p.selectedIdx > 0 {
+				p.selectedIdx--
+				if p.selectedIdx < p.offset {
+					p.offset = p.selectedIdx
+				}
+			}
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.Down):
+			if p.selectedIdx < len(p.items)-1 {
+				p.selectedIdx++
+				if p.selectedIdx >= p.offset+p.pageSize {
+					p.offset = p.selectedIdx - p.pageSize + 1
+				}
+			}
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.Top):
+			p.selectedIdx, p.offset = scrollTo(0, p.offset, len(p.items), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.Bottom):
+			p.selectedIdx, p.offset = scrollTo(len(p.items)-1, p.offset, len(p.items), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.HalfPageUp):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx-p.pageSize/2, p.offset, len(p.items), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.HalfPageDn):
+			p.selectedIdx, p.offset = scrollTo(p.selectedIdx+p.pageSize/2, p.offset, len(p.items), p.pageSize)
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.Remove):
+			// Stop tracking the selected item
+			p.removeSelected()
+			return *p, nil
+
+		case key.Matches(msg, keys.Watchlist.Refresh):
+			// Reload prices from the cache
+			p.lastSuccess = ""
+			p.Load()
+			return *p, nil
+		}
+	}
+
+	return *p, nil
+}
+
+// removeSelected deletes the highlighted item from the watchlist
+func (p *WatchlistPane) removeSelected() {
+	if len(p.items) == 0 || p.selectedIdx >= len(p.items) {
+		return
+	}
+
+	p.lastError = ""
+	p.lastSuccess = ""
+
+	if p.db == nil {
+		p.lastError = "no database available"
+		return
+	}
+
+	title := p.items[p.selectedIdx].Title
+	if err := p.db.RemoveFromWatchlist(title); err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.Load()
+	p.lastSuccess = fmt.Sprintf("Stopped watching '%s'", title)
+}
+
+// Load reads the watchlist and latest cached prices from the database
+func (p *WatchlistPane) Load() {
+	if p.db == nil {
+		return
+	}
+
+	items, err := p.db.GetWatchlist()
+	if err != nil {
+		p.lastError = err.Error()
+		return
+	}
+
+	p.items = items
+	if p.selectedIdx >= len(p.items) {
+		p.selectedIdx = len(p.items) - 1
+	}
+	if p.selectedIdx < 0 {
+		p.selectedIdx = 0
+	}
+	if p.offset > p.selectedIdx {
+		p.offset = p.selectedIdx
+	}
+}
+
+func (p *WatchlistPane) View(width, height int) string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Primary).
+		MarginBottom(1)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(theme.Text).
+		Background(theme.HeaderBg).
+		Padding(0, 1)
+
+	itemStyle := lipgloss.NewStyle().
+		Padding(0, 1)
+
+	selectedItemStyle := itemStyle.Copy().
+		Background(theme.SelectedBg).
+		Bold(true)
+
+	underTargetStyle := itemStyle.Copy().
+		Foreground(theme.Success)
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted).
+		Italic(true)
+
+	// Title
+	b.WriteString(titleStyle.Render(fmt.Sprintf("👀 Watchlist (%d items)", len(p.items))))
+	b.WriteString("\n\n")
+
+	if len(p.items) == 0 {
+		emptyStyle := lipgloss.NewStyle().
+			Foreground(theme.Subtle).
+			Italic(true)
+		b.WriteString(emptyStyle.Render("Nothing watched yet. Press 'w' on a result to track its title."))
+		b.WriteString("\n")
+	} else {
+		// Header
+		header := fmt.Sprintf("%-40s %10s %10s  %s", "Title", "Target", "Latest", "Status")
+		b.WriteString(headerStyle.Render(header))
+		b.WriteString("\n")
+
+		end := p.offset + p.pageSize
+		if end > len(p.items) {
+			end = len(p.items)
+		}
+
+		for i := p.offset; i < end; i++ {
+			item := p.items[i]
+			title := truncate(item.Title, 40)
+
+			latest, status := "—", "no cached price"
+			if item.HasPrice {
+				latest = fmt.Sprintf("$%.2f", item.LatestPrice)
+				status = "above target"
+			}
+			if item.UnderTarget() {
+				status = "★ under target"
+			}
+
+			line := fmt.Sprintf("%s %10s %10s  %s",
+				padRight(title, 40),
+				fmt.Sprintf("$%.2f", item.TargetPrice),
+				latest,
+				status,
+			)
+
+			if i == p.selectedIdx {
+				b.WriteString(selectedItemStyle.Render("▸ " + line))
+			} else if item.UnderTarget() {
+				b.WriteString(underTargetStyle.Render("  " + line))
+			} else {
+				b.WriteString(itemStyle.Render("  " + line))
+			}
+			b.WriteString("\n")
+		}
+
+		// Pagination info
+		b.WriteString("\n")
+		pageInfo := fmt.Sprintf("Showing %d-%d of %d", p.offset+1, end, len(p.items))
+		b.WriteString(infoStyle.Render(pageInfo))
+	}
+
+	// Instructions
+	b.WriteString("\n\n")
+	b.WriteString(infoStyle.Render("↑/↓ or j/k: Navigate • d: Remove • r: Refresh prices • Tab: Switch pane"))
+
+	// Status messages
+	if p.lastSuccess != "" {
+		successStyle := lipgloss.NewStyle().
+			Foreground(theme.Success).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(successStyle.Render("✓ " + p.lastSuccess))
+	}
+
+	if p.lastError != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(theme.Error).
+			Bold(true)
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render(fmt.Sprintf("✗ Error: %s", p.lastError)))
+	}
+
+	return b.String()
+}